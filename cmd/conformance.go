@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/conformance"
+	"github.com/kaitoy/zundoko-go-client/pkg/logging"
+)
+
+// conformanceCommand checks Zundoko Server against the OpenAPI spec, exiting with 1 if any test case failed.
+// The test posts Zundokos and Kiyoshies that remain on the server, so it refuses to run without -allow-writes
+// lest it pollute a shared history by mistake.
+func conformanceCommand(ctx context.Context, args []string) int {
+	var common commonFlags
+	fs := newFlagSet("conformance", &common)
+	output := fs.String("o", "-", "file to write the report to, or - for the standard output")
+	reportFormat := fs.String("report", "junit", "report format: junit (JUnit XML), text, or json")
+	timeout := fs.Duration("timeout", 10*time.Second, "timeout of each API call")
+	allowWrites := fs.Bool("allow-writes", false, "allow the test to post Zundokos and Kiyoshies, which remain on the server (required)")
+	fs.Parse(args)
+
+	if !*allowWrites {
+		logging.GetLogger().Error("Give -allow-writes to run the conformance test, which posts Zundokos and Kiyoshies that remain on the server.")
+		return 2
+	}
+
+	writers := map[string]func(r *conformance.Report, w io.Writer) error{
+		"junit": (*conformance.Report).WriteJUnit,
+		"text":  (*conformance.Report).WriteText,
+		"json":  (*conformance.Report).WriteJSON,
+	}
+	write, ok := writers[*reportFormat]
+	if !ok {
+		logging.GetLogger().Errorw("Unknown report format.", "report", *reportFormat)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	report, err := conformance.Run(ctx, conformance.Config{
+		Server:     common.server,
+		HTTPClient: &http.Client{Timeout: *timeout},
+	})
+	if err != nil {
+		logging.GetLogger().Errorw("Failed to run the conformance test.", "err", err)
+		return 1
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "-" {
+		f, err := os.Create(*output)
+		if err != nil {
+			logging.GetLogger().Errorw("Failed to create the output file.", "err", err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := write(report, w); err != nil {
+		logging.GetLogger().Errorw("Failed to write the report.", "err", err)
+		return 1
+	}
+	if failed := report.Failed(); failed > 0 {
+		logging.GetLogger().Errorw("The server doesn't conform to the spec.", "failed", failed, "tests", len(report.Results))
+		return 1
+	}
+	logging.GetLogger().Infow("The server conforms to the spec.", "tests", len(report.Results))
+	return 0
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/history"
+	"github.com/kaitoy/zundoko-go-client/pkg/logging"
+)
+
+// exportCommand writes the history on Zundoko Server to a file or the standard output.
+func exportCommand(ctx context.Context, args []string) int {
+	var common commonFlags
+	fs := newFlagSet("export", &common)
+	output := fs.String("o", "-", "file to write the history to, or - for the standard output")
+	formatName := fs.String("format", "", "history format: jsonl, csv, or parquet (default: by the extension of -o, or jsonl)")
+	fs.Parse(args)
+
+	format, err := historyFormat(*formatName, *output)
+	if err != nil {
+		logging.GetLogger().Errorw("Unknown history format.", "err", err)
+		return 2
+	}
+
+	shutdown, ok := initTracing(ctx, &common)
+	if !ok {
+		return 1
+	}
+	defer shutdown()
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	h, err := history.Fetch(ctx, newClient(&common))
+	if err != nil {
+		logging.GetLogger().Errorw("Failed to get the history.", "err", err)
+		return 1
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "-" {
+		f, err := os.Create(*output)
+		if err != nil {
+			logging.GetLogger().Errorw("Failed to create the output file.", "err", err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := history.Write(w, h, format); err != nil {
+		logging.GetLogger().Errorw("Failed to write the history.", "err", err)
+		return 1
+	}
+	logging.GetLogger().Infow(
+		"Exported the history.",
+		"zundokos", len(h.Zundokos),
+		"kiyoshies", len(h.Kiyoshies),
+		"format", format,
+	)
+	return 0
+}
+
+// historyFormat returns the history format of the name, or the one of the file at the path if the name is empty.
+// It defaults to JSON lines for the standard input or output, or a file without a known extension.
+func historyFormat(name, path string) (history.Format, error) {
+	if name != "" {
+		return history.ParseFormat(name)
+	}
+	if format, err := history.FormatOf(path); err == nil {
+		return format, nil
+	}
+	return history.FormatJSONLines, nil
+}
+
+// readHistory reads a history in the format from the file at the path, or the standard input if it's "-".
+func readHistory(path string, format history.Format) (*history.History, error) {
+	if path == "-" {
+		return history.Read(os.Stdin, format)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return history.Read(f, format)
+}
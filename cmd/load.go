@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/load"
+	"github.com/kaitoy/zundoko-go-client/pkg/logging"
+	"github.com/kaitoy/zundoko-go-client/pkg/runner"
+)
+
+// loadCommand runs a load test and prints its report.
+func loadCommand(ctx context.Context, args []string) int {
+	var common commonFlags
+	var config load.Config
+	fs := newFlagSet("load", &common)
+	fs.IntVar(&config.Players, "players", 10, "number of concurrent virtual players")
+	fs.DurationVar(&config.RampUp, "ramp-up", 0, "time to take to start all the players")
+	fs.DurationVar(&config.Duration, "duration", 0, "time limit of the load test (0 means no limit)")
+	fs.IntVar(&config.Iterations, "iterations", 0, "number of sessions each player runs (0 means no limit)")
+	fs.Float64Var(&config.RequestRate, "rate", 0, "target API requests per second of all the players (0 means no limit)")
+	fs.IntVar(&config.MaxInFlight, "max-in-flight", 0, "maximum concurrent API requests of all the players (0 means no limit)")
+	fs.DurationVar(&config.Interval, "interval", 0, "interval between Zundokos in a session")
+	localDetection := fs.Bool("local-detection", false, "detect the pattern locally instead of getting Zundokos before every post")
+	resyncPosts := fs.Int("resync-posts", 0, "posts after which local detection resyncs words (0 means never)")
+	fs.DurationVar(&config.FailureBackoff, "failure-backoff", load.DefaultFailureBackoff, "time a player pauses after a failed session, doubling for each consecutive failure up to 30s")
+	reportFormat := fs.String("report", "text", "report format: text or json")
+	fs.Parse(args)
+	if *localDetection {
+		config.LocalDetection = &runner.LocalDetection{ResyncPosts: *resyncPosts}
+	}
+	if *reportFormat != "text" && *reportFormat != "json" {
+		logging.GetLogger().Errorw("Unknown report format.", "report", *reportFormat)
+		return 2
+	}
+
+	shutdown, ok := initTracing(ctx, &common)
+	if !ok {
+		return 1
+	}
+	defer shutdown()
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	report, err := load.NewTester(newClient(&common), config).Run(ctx)
+	if err != nil {
+		logging.GetLogger().Errorw("Failed to run a load test.", "err", err)
+		return 1
+	}
+
+	if *reportFormat == "json" {
+		err = report.WriteJSON(os.Stdout)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		logging.GetLogger().Errorw("Failed to write a report.", "err", err)
+		return 1
+	}
+	return 0
+}
@@ -1,19 +1,140 @@
 // The zundoko-client command is a client of Zundoko Server.
+//
+// Usage:
+//
+//	zundoko-client [run] [flags]        Run a Zundoko Kiyoshi.
+//	zundoko-client load [flags]         Run a load test with many concurrent virtual players.
+//	zundoko-client tui [flags]          Watch and play Zundoko Kiyoshi in a full-screen terminal UI.
+//	zundoko-client export [flags]       Export the history on Zundoko Server to JSON lines, CSV, or Parquet.
+//	zundoko-client replay [flags]       Replay an exported history locally or to Zundoko Server.
+//	zundoko-client stats [flags]        Print statistics of the history on Zundoko Server or in an exported file.
+//	zundoko-client simulate [flags]     Estimate words to a Kiyoshi by a Monte-Carlo simulation.
+//	zundoko-client serve [flags]        Serve a REST API to control Zundoko Kiyoshi sessions.
+//	zundoko-client probe [flags]        Check a read/write round-trip to Zundoko Server for monitoring.
+//	zundoko-client conformance [flags]  Check Zundoko Server against the OpenAPI spec, posting test entities.
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
 	"github.com/kaitoy/zundoko-go-client/pkg/client"
 	"github.com/kaitoy/zundoko-go-client/pkg/logging"
-	"github.com/kaitoy/zundoko-go-client/pkg/runner"
+	"github.com/kaitoy/zundoko-go-client/pkg/tracing"
 	"go.uber.org/zap/zapcore"
 )
 
+// commands maps subcommand names to funcs that run them with args and return an exit code.
+var commands = map[string]func(ctx context.Context, args []string) int{
+	"run":         runCommand,
+	"load":        loadCommand,
+	"tui":         tuiCommand,
+	"export":      exportCommand,
+	"replay":      replayCommand,
+	"stats":       statsCommand,
+	"simulate":    simulateCommand,
+	"serve":       serveCommand,
+	"probe":       probeCommand,
+	"conformance": conformanceCommand,
+}
+
 func main() {
+	os.Exit(dispatch(os.Args[1:]))
+}
+
+// dispatch runs the subcommand specified by the first arg, or run subcommand if not specified.
+func dispatch(args []string) int {
 	logging.Init(zapcore.InfoLevel)
 	defer logging.GetLogger().Sync()
 
-	cl := client.NewClient("http://localhost:8080")
-	if err := runner.NewRunner(cl).Run(1000); err != nil {
-		logging.GetLogger().Errorw("An error occurred.", "err", err)
+	name := "run"
+	if len(args) > 0 {
+		if _, ok := commands[args[0]]; ok {
+			name, args = args[0], args[1:]
+		}
+	}
+
+	return commands[name](context.Background(), args)
+}
+
+// commonFlags holds flags shared by subcommands.
+type commonFlags struct {
+	server           string
+	retries          int
+	retryBackoff     time.Duration
+	breakerThreshold int
+	breakerCoolDown  time.Duration
+	strict           bool
+	maxBodySize      int64
+	format           client.Format
+	traceExporter    string
+	otlpEndpoint     string
+}
+
+// newFlagSet creates a FlagSet for the subcommand with the common flags registered.
+func newFlagSet(name string, common *commonFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: zundoko-client %s [flags]\n", name)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&common.server, "server", "http://localhost:8080", "URL of Zundoko Server")
+	fs.IntVar(&common.retries, "retries", 0, "maximum retries of a POST API call that failed transiently")
+	fs.DurationVar(&common.retryBackoff, "retry-backoff", 100*time.Millisecond, "wait before the first retry, doubling for subsequent ones")
+	fs.IntVar(&common.breakerThreshold, "breaker-threshold", 0, "consecutive API failures to open the circuit breaker (0 disables it)")
+	fs.DurationVar(&common.breakerCoolDown, "breaker-cool-down", 30*time.Second, "time the circuit breaker stays open")
+	fs.BoolVar(&common.strict, "strict", false, "validate responses against the schemas in the OpenAPI spec")
+	fs.Int64Var(&common.maxBodySize, "max-body-size", client.DefaultMaxBodySize, "max size in bytes of a response body to read (0 means no limit)")
+	common.format = client.FormatJSON
+	fs.Func("wire-format", "wire format of request and response bodies: json, msgpack, or protobuf (default json)", func(name string) (err error) {
+		common.format, err = client.ParseFormat(name)
+		return err
+	})
+	fs.StringVar(&common.traceExporter, "trace-exporter", "none", "span exporter to send traces to: none, stdout, or otlp")
+	fs.StringVar(&common.otlpEndpoint, "otlp-endpoint", "", "URL of OTLP/HTTP collector (default: $OTEL_EXPORTER_OTLP_ENDPOINT)")
+	return fs
+}
+
+// newClient creates a Client with the common flags.
+func newClient(common *commonFlags, opts ...client.Option) client.Client {
+	opts = append(opts, client.WithRetry(client.RetryConfig{
+		MaxRetries: common.retries,
+		Backoff:    common.retryBackoff,
+	}))
+	opts = append(opts, client.WithMaxBodySize(common.maxBodySize), client.WithFormat(common.format))
+	if common.strict {
+		opts = append(opts, client.WithStrictMode())
+	}
+	cl := client.NewClient(common.server, opts...)
+	if common.breakerThreshold <= 0 {
+		return cl
+	}
+	return client.NewCircuitBreaker(cl, client.CircuitBreakerConfig{
+		FailureThreshold: common.breakerThreshold,
+		CoolDown:         common.breakerCoolDown,
+	})
+}
+
+// initTracing initializes tracing with the common flags and returns a func to shut it down.
+func initTracing(ctx context.Context, common *commonFlags) (func(), bool) {
+	shutdown, err := tracing.Init(ctx, tracing.Exporter(common.traceExporter), common.otlpEndpoint)
+	if err != nil {
+		logging.GetLogger().Errorw("Failed to initialize tracing.", "err", err)
+		return nil, false
 	}
+	return func() { shutdown(ctx) }, true
+}
+
+// isFlagSet returns whether the flag of the name was given in the command line.
+func isFlagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
 }
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/logging"
+	"github.com/kaitoy/zundoko-go-client/pkg/probe"
+)
+
+// probeCommand checks a read/write round-trip to Zundoko Server, exiting with the code of the status
+// in the Nagios plugin convention: 0 for OK, 1 for WARNING, 2 for CRITICAL, and 3 for UNKNOWN.
+func probeCommand(ctx context.Context, args []string) int {
+	var common commonFlags
+	fs := newFlagSet("probe", &common)
+	// Exit with UNKNOWN instead of 2, which means CRITICAL for health checks.
+	fs.Init("probe", flag.ContinueOnError)
+	var config probe.Config
+	fs.StringVar(&config.Word, "word", "Zun", "word of the test Zundoko to post")
+	fs.BoolVar(&config.Kiyoshi, "kiyoshi", false, "also complete the pattern, post a test Kiyoshi, and check it's accepted")
+	fs.DurationVar(&config.Warning, "warning", 0, "elapsed time of the probe over which it's WARNING (0 disables it)")
+	fs.DurationVar(&config.Critical, "critical", 0, "elapsed time of the probe over which it's CRITICAL (0 disables it)")
+	timeout := fs.Duration("timeout", 10*time.Second, "time after which the probe is aborted as CRITICAL")
+	reportFormat := fs.String("report", "nagios", "report format: nagios or json")
+	if err := fs.Parse(args); err != nil {
+		return int(probe.StatusUnknown)
+	}
+
+	writers := map[string]func(r *probe.Report) error{
+		"nagios": func(r *probe.Report) error { return r.WriteNagios(os.Stdout) },
+		"json":   func(r *probe.Report) error { return r.WriteJSON(os.Stdout) },
+	}
+	write, ok := writers[*reportFormat]
+	if !ok {
+		logging.GetLogger().Errorw("Unknown report format.", "report", *reportFormat)
+		return int(probe.StatusUnknown)
+	}
+	if config.Word != "Zun" && config.Word != "Doko" {
+		logging.GetLogger().Errorw("Unknown word.", "word", config.Word)
+		return int(probe.StatusUnknown)
+	}
+
+	shutdown, ok := initTracing(ctx, &common)
+	if !ok {
+		return int(probe.StatusUnknown)
+	}
+	defer shutdown()
+
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	report := probe.Probe(ctx, newClient(&common), config)
+	if err := write(report); err != nil {
+		logging.GetLogger().Errorw("Failed to write the report.", "err", err)
+		return int(probe.StatusUnknown)
+	}
+	return int(report.Status)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/history"
+	"github.com/kaitoy/zundoko-go-client/pkg/logging"
+	"github.com/kaitoy/zundoko-go-client/pkg/runner"
+)
+
+// replayCommand plays an exported history back through the detection logic of runner,
+// or re-posts it to Zundoko Server.
+func replayCommand(ctx context.Context, args []string) int {
+	var common commonFlags
+	fs := newFlagSet("replay", &common)
+	input := fs.String("i", "-", "file to read the history from, or - for the standard input")
+	formatName := fs.String("format", "", "history format: jsonl, csv, or parquet (default: by the extension of -i, or jsonl)")
+	mode := fs.String("mode", "detect", "how to replay: detect (find Kiyoshies locally) or post (re-post to -server)")
+	output := fs.String("output", "text", "how to output words in detect mode: text, json, color, or silent")
+	resultFormat := fs.String("result", "none", "how to print the result of each session in detect mode: none, text, or json")
+	var config history.ReplayConfig
+	fs.Float64Var(&config.Speed, "speed", 1, "speed to re-post in post mode relative to the original timing (0 means no wait)")
+	fs.BoolVar(&config.KeepSaidAt, "keep-said-at", false, "re-post with the original saidAt in post mode")
+	fs.Parse(args)
+
+	format, err := historyFormat(*formatName, *input)
+	if err != nil {
+		logging.GetLogger().Errorw("Unknown history format.", "err", err)
+		return 2
+	}
+	newPresenter, ok := presenters[*output]
+	if !ok {
+		logging.GetLogger().Errorw("Unknown output mode.", "output", *output)
+		return 2
+	}
+	if *resultFormat != "none" && *resultFormat != "text" && *resultFormat != "json" {
+		logging.GetLogger().Errorw("Unknown result format.", "result", *resultFormat)
+		return 2
+	}
+
+	h, err := readHistory(*input, format)
+	if err != nil {
+		logging.GetLogger().Errorw("Failed to read the history.", "err", err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	switch *mode {
+	case "detect":
+		detection, err := history.Detect(ctx, h, runner.WithPresenter(newPresenter(os.Stdout)))
+		for _, result := range detection.Sessions {
+			if writeErr := writeResult(os.Stdout, result, *resultFormat); writeErr != nil {
+				logging.GetLogger().Errorw("Failed to write the result.", "err", writeErr)
+			}
+		}
+		if err != nil {
+			logging.GetLogger().Errorw("An error occurred.", "err", err)
+			return 1
+		}
+		logging.GetLogger().Infow(
+			"Replayed the history.",
+			"zundokos", len(h.Zundokos),
+			"detectedKiyoshies", len(detection.Sessions),
+			"recordedKiyoshies", len(h.Kiyoshies),
+			"remaining", detection.Remaining,
+		)
+	case "post":
+		shutdown, ok := initTracing(ctx, &common)
+		if !ok {
+			return 1
+		}
+		defer shutdown()
+
+		posted, err := history.Replay(ctx, newClient(&common), h, config)
+		logging.GetLogger().Infow("Re-posted the history.", "posted", posted, "server", common.server)
+		if err != nil {
+			logging.GetLogger().Errorw("An error occurred.", "err", err)
+			return 1
+		}
+	default:
+		logging.GetLogger().Errorw("Unknown replay mode.", "mode", *mode)
+		return 2
+	}
+	return 0
+}
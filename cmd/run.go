@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/client"
+	"github.com/kaitoy/zundoko-go-client/pkg/daemon"
+	"github.com/kaitoy/zundoko-go-client/pkg/logging"
+	"github.com/kaitoy/zundoko-go-client/pkg/runner"
+	"golang.org/x/term"
+)
+
+// presenters maps output modes to funcs creating Presenters for them which write to w.
+var presenters = map[string]func(w io.Writer) runner.Presenter{
+	"text":   runner.NewTextPresenter,
+	"json":   runner.NewJSONLinesPresenter,
+	"color":  runner.NewColorPresenter,
+	"silent": func(io.Writer) runner.Presenter { return runner.NewSilentPresenter() },
+}
+
+// claimRules maps names of claim rules to them.
+var claimRules = map[string]runner.ClaimRule{
+	"any":       runner.ClaimAny,
+	"completer": runner.ClaimCompleter,
+}
+
+// duplicateRules maps names of duplicate rules to them.
+var duplicateRules = map[string]runner.DuplicateRule{
+	"fail":  runner.DuplicateFail,
+	"yield": runner.DuplicateYield,
+}
+
+// runCommand runs a Zundoko Kiyoshi.
+func runCommand(ctx context.Context, args []string) int {
+	var common commonFlags
+	fs := newFlagSet("run", &common)
+	output := fs.String("output", "text", "how to output words: text, json, color, or silent")
+	resultFormat := fs.String("result", "none", "how to print the result at the end: none, text, or json")
+	input := fs.String("input", "random", "where to get words from: random, lines (a word per line of stdin), or keys (Z/D key presses)")
+	pace := fs.String("pace", "fixed", "how to pace words: fixed, jitter, tempo, or fast (default: fast for lines and keys inputs)")
+	interval := fs.Duration("interval", time.Second, "interval between words of fixed and jitter paces")
+	jitter := fs.Duration("jitter", 250*time.Millisecond, "maximum random shift of the interval of jitter pace")
+	bpm := fs.Float64("bpm", 120, "tempo in beats per minute of tempo pace")
+	localDetection := fs.Bool("local-detection", false, "detect the pattern locally instead of getting Zundokos before every post")
+	var local runner.LocalDetection
+	fs.IntVar(&local.ResyncPosts, "resync-posts", 0, "posts after which local detection resyncs words (0 means never)")
+	fs.DurationVar(&local.ResyncInterval, "resync-interval", 0, "time after which local detection resyncs words (0 means never)")
+	consensus := fs.Bool("consensus", false, "follow the consensus rules for a history shared with other clients")
+	claim := fs.String("claim", "any", "who may claim a Kiyoshi under the consensus rules: any or completer")
+	ownWordsOnly := fs.Bool("own-words-only", false, "count only own words under the consensus rules")
+	onDuplicate := fs.String("on-duplicate", "fail", "what to do on a duplicate Kiyoshi under the consensus rules: fail or yield")
+	daemonMode := fs.Bool("daemon", false, "run sessions repeatedly, writing their results as JSON lines (random input only)")
+	var schedule daemon.Config
+	fs.StringVar(&schedule.Schedule, "schedule", "", "cron expression or descriptor like @every 5m to start sessions of the daemon on (default: back to back)")
+	fs.IntVar(&schedule.Count, "count", 0, "number of sessions the daemon runs (0 means no limit)")
+	fs.DurationVar(&schedule.Cooldown, "cooldown", 0, "minimum time between sessions of the daemon")
+	fs.DurationVar(&schedule.Jitter, "start-jitter", 0, "maximum random delay of the start of sessions of the daemon")
+	fs.DurationVar(&schedule.Backoff, "backoff", time.Second, "minimum time after a failed session of the daemon, doubled for each consecutive failure")
+	fs.DurationVar(&schedule.MaxBackoff, "max-backoff", 10*time.Minute, "maximum backoff of the daemon")
+	results := fs.String("results", "", "file the daemon appends the results of sessions to (default: stdout, which makes -output silent)")
+	fs.Parse(args)
+	if *input != "random" && !isFlagSet(fs, "pace") {
+		*pace = "fast"
+	}
+
+	if *daemonMode && *input != "random" {
+		logging.GetLogger().Errorw("The daemon supports only random input.", "input", *input)
+		return 2
+	}
+	if *daemonMode && *results == "" {
+		if isFlagSet(fs, "output") && *output != "silent" {
+			logging.GetLogger().Errorw("The daemon writes results to stdout without -results, so words can't be output.", "output", *output)
+			return 2
+		}
+		*output = "silent"
+	}
+
+	newPresenter, ok := presenters[*output]
+	if !ok {
+		logging.GetLogger().Errorw("Unknown output mode.", "output", *output)
+		return 2
+	}
+
+	if *resultFormat != "none" && *resultFormat != "text" && *resultFormat != "json" {
+		logging.GetLogger().Errorw("Unknown result format.", "result", *resultFormat)
+		return 2
+	}
+
+	var pacer runner.Pacer
+	switch *pace {
+	case "fixed":
+		pacer = runner.NewFixedPacer(*interval)
+	case "jitter":
+		pacer = runner.NewJitterPacer(*interval, *jitter, nil)
+	case "tempo":
+		var err error
+		if pacer, err = runner.NewTempoPacer(*bpm); err != nil {
+			logging.GetLogger().Errorw("Invalid tempo.", "err", err)
+			return 2
+		}
+	case "fast":
+		pacer = runner.NewFastPacer()
+	default:
+		logging.GetLogger().Errorw("Unknown pace.", "pace", *pace)
+		return 2
+	}
+
+	var w io.Writer = os.Stdout
+	var source runner.WordSource
+	switch *input {
+	case "random":
+		source = runner.NewRandomWordSource(nil)
+	case "lines":
+		source = runner.NewLineWordSource(os.Stdin)
+	case "keys":
+		fd := int(os.Stdin.Fd())
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			logging.GetLogger().Errorw("Failed to put the terminal into raw mode.", "err", err)
+			return 1
+		}
+		defer term.Restore(fd, state)
+		source = runner.NewKeyWordSource(os.Stdin)
+		w = crlfWriter{os.Stdout}
+	default:
+		logging.GetLogger().Errorw("Unknown input mode.", "input", *input)
+		return 2
+	}
+
+	shutdown, ok := initTracing(ctx, &common)
+	if !ok {
+		return 1
+	}
+	defer shutdown()
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	cl := newClient(&common)
+	opts := []runner.Option{
+		runner.WithPresenter(newPresenter(w)),
+		runner.WithWordSource(source),
+		runner.WithPacer(pacer),
+		runner.WithObserver(runner.NewLoggingObserver()),
+	}
+	if *localDetection {
+		opts = append(opts, runner.WithLocalDetection(local))
+	}
+	if *consensus {
+		rules := runner.Consensus{OwnWordsOnly: *ownWordsOnly}
+		if rules.Claim, ok = claimRules[*claim]; !ok {
+			logging.GetLogger().Errorw("Unknown claim rule.", "claim", *claim)
+			return 2
+		}
+		if rules.OnDuplicate, ok = duplicateRules[*onDuplicate]; !ok {
+			logging.GetLogger().Errorw("Unknown duplicate rule.", "onDuplicate", *onDuplicate)
+			return 2
+		}
+		opts = append(opts, runner.WithConsensus(rules))
+	}
+	if *daemonMode {
+		return runDaemon(ctx, cl, opts, schedule, *results)
+	}
+	r := runner.NewRunner(cl, opts...)
+	result, err := r.Run(ctx)
+	if writeErr := writeResult(w, result, *resultFormat); writeErr != nil {
+		logging.GetLogger().Errorw("Failed to write the result.", "err", writeErr)
+	}
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			logging.GetLogger().Info("Quit Zundoko Kiyoshi before a Kiyoshi.")
+			return 0
+		}
+		logging.GetLogger().Errorw("An error occurred.", "err", err)
+		return 1
+	}
+	return 0
+}
+
+// runDaemon runs sessions of Runners with the options on the schedule, appending their results to the file at path,
+// or writing them to stdout if path is empty.
+func runDaemon(ctx context.Context, cl client.Client, opts []runner.Option, schedule daemon.Config, path string) int {
+	var w io.Writer = os.Stdout
+	if path != "" {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			logging.GetLogger().Errorw("Failed to open the results file.", "path", path, "err", err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+
+	d, err := daemon.NewDaemon(func() runner.Runner { return runner.NewRunner(cl, opts...) }, w, schedule)
+	if err != nil {
+		logging.GetLogger().Errorw("Invalid schedule.", "err", err)
+		return 2
+	}
+	if err := d.Run(ctx); err != nil {
+		logging.GetLogger().Errorw("An error occurred.", "err", err)
+		return 1
+	}
+	return 0
+}
+
+// writeResult writes the result in the format, or nothing if the format is "none".
+func writeResult(w io.Writer, result *runner.Result, format string) error {
+	switch format {
+	case "text":
+		return result.WriteText(w)
+	case "json":
+		return result.WriteJSON(w)
+	}
+	return nil
+}
+
+// crlfWriter writes to w with "\n" replaced with "\r\n", since a terminal in raw mode doesn't return the carriage on "\n".
+type crlfWriter struct {
+	w io.Writer
+}
+
+func (c crlfWriter) Write(p []byte) (int, error) {
+	if _, err := c.w.Write(bytes.ReplaceAll(p, []byte("\n"), []byte("\r\n"))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/agent"
+	"github.com/kaitoy/zundoko-go-client/pkg/client"
+	"github.com/kaitoy/zundoko-go-client/pkg/logging"
+	"github.com/kaitoy/zundoko-go-client/pkg/runner"
+)
+
+// serveCommand serves a REST API to start, stop, and list Zundoko Kiyoshi sessions.
+func serveCommand(ctx context.Context, args []string) int {
+	var common commonFlags
+	fs := newFlagSet("serve", &common)
+	listen := fs.String("listen", "127.0.0.1:8081", "address to listen on for the REST API")
+	fs.Parse(args)
+
+	shutdown, ok := initTracing(ctx, &common)
+	if !ok {
+		return 1
+	}
+	defer shutdown()
+
+	m := agent.NewManager(func(server string) client.Client {
+		flags := common
+		flags.server = server
+		return newClient(&flags)
+	}, common.server, runner.WithObserver(runner.NewLoggingObserver()))
+	server := &http.Server{Addr: *listen, Handler: agent.NewHandler(m)}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+		if err := m.Shutdown(shutdownCtx); err != nil {
+			logging.GetLogger().Warnw("Sessions didn't stop in time.", "err", err)
+		}
+	}()
+
+	logging.GetLogger().Infow("Serve the REST API.", "address", *listen)
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		logging.GetLogger().Errorw("Failed to serve the REST API.", "err", err)
+		return 1
+	}
+	<-stopped
+	return 0
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/logging"
+	"github.com/kaitoy/zundoko-go-client/pkg/sim"
+)
+
+// simulateCommand runs a Monte-Carlo simulation of Zundoko Kiyoshi offline and prints its report.
+func simulateCommand(ctx context.Context, args []string) int {
+	var config sim.Config
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: zundoko-client simulate [flags]")
+		fs.PrintDefaults()
+	}
+	fs.IntVar(&config.Runs, "runs", 1000000, "number of sessions to simulate")
+	fs.IntVar(&config.Workers, "workers", 0, "number of sessions to simulate in parallel (default: the number of CPUs)")
+	fs.Int64Var(&config.Seed, "seed", time.Now().UnixNano(), "seed of pseudo-random numbers (default: the current time)")
+	fs.IntVar(&config.MaxWords, "max-words", 1000000, "words after which a session is given up")
+	pattern := fs.String("pattern", "", "comma-separated words that make a Kiyoshi (default: Zun,Zun,Zun,Zun,Doko)")
+	probabilities := fs.String("probabilities", "", "comma-separated word=probability pairs (default: Zun=0.5,Doko=0.5)")
+	reportFormat := fs.String("report", "text", "report format: text or json")
+	fs.Parse(args)
+	if *reportFormat != "text" && *reportFormat != "json" {
+		logging.GetLogger().Errorw("Unknown report format.", "report", *reportFormat)
+		return 2
+	}
+
+	if *pattern != "" {
+		config.Pattern = strings.Split(*pattern, ",")
+	}
+	if *probabilities != "" {
+		config.Probabilities = make(map[string]float64)
+		for _, pair := range strings.Split(*probabilities, ",") {
+			kv := strings.SplitN(pair, "=", 2)
+			if len(kv) != 2 {
+				logging.GetLogger().Errorw("Invalid probability.", "probability", pair)
+				return 2
+			}
+			p, err := strconv.ParseFloat(kv[1], 64)
+			if err != nil {
+				logging.GetLogger().Errorw("Invalid probability.", "probability", pair, "err", err)
+				return 2
+			}
+			config.Probabilities[kv[0]] = p
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	report, err := sim.Simulate(ctx, config)
+	if report == nil {
+		logging.GetLogger().Errorw("Invalid simulation.", "err", err)
+		return 2
+	}
+	if err != nil {
+		logging.GetLogger().Warnw("The simulation was interrupted.", "err", err)
+	}
+
+	if *reportFormat == "json" {
+		err = report.WriteJSON(os.Stdout)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		logging.GetLogger().Errorw("Failed to write a report.", "err", err)
+		return 1
+	}
+	return 0
+}
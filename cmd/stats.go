@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/analysis"
+	"github.com/kaitoy/zundoko-go-client/pkg/history"
+	"github.com/kaitoy/zundoko-go-client/pkg/logging"
+)
+
+// statsCommand prints statistics of the history on Zundoko Server or in an exported file.
+func statsCommand(ctx context.Context, args []string) int {
+	var common commonFlags
+	fs := newFlagSet("stats", &common)
+	input := fs.String("i", "", "exported history file to analyse, or - for the standard input (default: the history on -server)")
+	formatName := fs.String("format", "", "history format of -i: jsonl, csv, or parquet (default: by the extension of -i, or jsonl)")
+	reportFormat := fs.String("report", "text", "report format: text, json, or markdown")
+	fs.Parse(args)
+
+	writers := map[string]func(r *analysis.Report) error{
+		"text":     func(r *analysis.Report) error { return r.WriteText(os.Stdout) },
+		"json":     func(r *analysis.Report) error { return r.WriteJSON(os.Stdout) },
+		"markdown": func(r *analysis.Report) error { return r.WriteMarkdown(os.Stdout) },
+	}
+	write, ok := writers[*reportFormat]
+	if !ok {
+		logging.GetLogger().Errorw("Unknown report format.", "report", *reportFormat)
+		return 2
+	}
+
+	var h *history.History
+	if *input != "" {
+		format, err := historyFormat(*formatName, *input)
+		if err != nil {
+			logging.GetLogger().Errorw("Unknown history format.", "err", err)
+			return 2
+		}
+		if h, err = readHistory(*input, format); err != nil {
+			logging.GetLogger().Errorw("Failed to read the history.", "err", err)
+			return 1
+		}
+	} else {
+		shutdown, ok := initTracing(ctx, &common)
+		if !ok {
+			return 1
+		}
+		defer shutdown()
+
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+		defer stop()
+
+		var err error
+		if h, err = history.Fetch(ctx, newClient(&common)); err != nil {
+			logging.GetLogger().Errorw("Failed to get the history.", "err", err)
+			return 1
+		}
+	}
+
+	if err := write(analysis.Analyze(h)); err != nil {
+		logging.GetLogger().Errorw("Failed to write a report.", "err", err)
+		return 1
+	}
+	return 0
+}
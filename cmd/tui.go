@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/logging"
+	"github.com/kaitoy/zundoko-go-client/pkg/tui"
+	"golang.org/x/term"
+)
+
+// ANSI escape sequences to switch to the alternate screen with the cursor hidden and back.
+const (
+	enterAltScreen = "\x1b[?1049h\x1b[?25l"
+	leaveAltScreen = "\x1b[?25h\x1b[?1049l"
+)
+
+// tuiCommand shows Zundokos live in a full-screen terminal UI.
+func tuiCommand(ctx context.Context, args []string) int {
+	var common commonFlags
+	var config tui.Config
+	fs := newFlagSet("tui", &common)
+	fs.BoolVar(&config.Manual, "manual", false, "post Zundokos by pressing Z or D keys")
+	fs.DurationVar(&config.PollInterval, "poll-interval", 500*time.Millisecond, "interval to get Zundokos")
+	fs.Parse(args)
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		logging.GetLogger().Error("The standard input is not a terminal.")
+		return 1
+	}
+
+	shutdown, ok := initTracing(ctx, &common)
+	if !ok {
+		return 1
+	}
+	defer shutdown()
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		logging.GetLogger().Errorw("Failed to put the terminal into raw mode.", "err", err)
+		return 1
+	}
+	os.Stdout.WriteString(enterAltScreen)
+	defer func() {
+		os.Stdout.WriteString(leaveAltScreen)
+		term.Restore(fd, state)
+	}()
+
+	if err := tui.NewTUI(newClient(&common), os.Stdin, os.Stdout, config).Run(ctx); err != nil {
+		logging.GetLogger().Errorw("An error occurred.", "err", err)
+		return 1
+	}
+	return 0
+}
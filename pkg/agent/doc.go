@@ -0,0 +1,3 @@
+// Package agent provides a manager of concurrent Zundoko Kiyoshi sessions and a REST API to control it,
+// which makes zundoko-client an agent driven remotely, e.g. by a test harness.
+package agent
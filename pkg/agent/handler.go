@@ -0,0 +1,111 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/logging"
+)
+
+// NewHandler creates an http.Handler of the REST API to control sessions of the Manager:
+//
+//	POST   /sessions             Start a session configured by a SessionConfig in the body. Responds 201 with the Session.
+//	GET    /sessions             List the Sessions.
+//	GET    /sessions/{id}        Get the Session.
+//	GET    /sessions/{id}/result Get the runner.Result of the session. Responds 409 while it's running.
+//	DELETE /sessions/{id}        Stop the session if it's running, remove it, and get the Session as it ended.
+//
+// Errors are responded as {"error": "message"}. Starting a session after the Manager is shut down responds 503.
+func NewHandler(m Manager) http.Handler {
+	h := &handler{m}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /sessions", h.start)
+	mux.HandleFunc("GET /sessions", h.list)
+	mux.HandleFunc("GET /sessions/{id}", h.get)
+	mux.HandleFunc("GET /sessions/{id}/result", h.result)
+	mux.HandleFunc("DELETE /sessions/{id}", h.remove)
+	return mux
+}
+
+type handler struct {
+	m Manager
+}
+
+func (h *handler) start(w http.ResponseWriter, req *http.Request) {
+	var config SessionConfig
+	decoder := json.NewDecoder(req.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	s, err := h.m.Start(config)
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	w.Header().Set("Location", "/sessions/"+s.Id)
+	writeJSON(w, http.StatusCreated, s)
+}
+
+func (h *handler) list(w http.ResponseWriter, req *http.Request) {
+	writeJSON(w, http.StatusOK, h.m.List())
+}
+
+func (h *handler) get(w http.ResponseWriter, req *http.Request) {
+	s, err := h.m.Get(req.PathValue("id"))
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, s)
+}
+
+func (h *handler) result(w http.ResponseWriter, req *http.Request) {
+	s, err := h.m.Get(req.PathValue("id"))
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	if s.State == SessionRunning {
+		writeError(w, http.StatusConflict, errors.New("the session is still running"))
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Result)
+}
+
+func (h *handler) remove(w http.ResponseWriter, req *http.Request) {
+	s, err := h.m.Remove(req.PathValue("id"))
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, s)
+}
+
+// statusOf returns the status code to respond for the error of the Manager.
+func statusOf(err error) int {
+	switch {
+	case errors.Is(err, ErrSessionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrInvalidSessionConfig):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrShutdown):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logging.GetLogger().Warnw("Failed to write a response.", "err", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
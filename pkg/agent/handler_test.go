@@ -0,0 +1,120 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/client"
+	"github.com/kaitoy/zundoko-go-client/pkg/history"
+	"github.com/kaitoy/zundoko-go-client/pkg/runner"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Handler", func() {
+	var (
+		m      Manager
+		server *httptest.Server
+	)
+
+	BeforeEach(func() {
+		m = NewManager(func(string) client.Client { return history.NewMemoryClient(nil) }, "http://default")
+		server = httptest.NewServer(NewHandler(m))
+	})
+
+	AfterEach(func() {
+		server.Close()
+		m.Shutdown(context.Background())
+	})
+
+	// call calls the API and decodes the response body into v.
+	call := func(method, path, body string, v interface{}) *http.Response {
+		req, _ := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).To(BeNil())
+		defer resp.Body.Close()
+		if v != nil {
+			Expect(json.NewDecoder(resp.Body).Decode(v)).To(Succeed())
+		}
+		return resp
+	}
+
+	It("starts a session and gets its status and result.", func() {
+		var s Session
+		resp := call("POST", "/sessions", `{"interval": "1ms", "pattern": ["Zun", "Doko"]}`, &s)
+
+		Expect(resp.StatusCode).To(Equal(201))
+		Expect(resp.Header.Get("Content-Type")).To(Equal("application/json"))
+		Expect(resp.Header.Get("Location")).To(Equal("/sessions/" + s.Id))
+		Expect(s.Config.Interval).To(Equal(Duration(time.Millisecond)))
+		Eventually(func() SessionState {
+			var got Session
+			call("GET", "/sessions/"+s.Id, "", &got)
+			return got.State
+		}, 5*time.Second).Should(Equal(SessionSucceeded))
+
+		var result runner.Result
+		resp = call("GET", "/sessions/"+s.Id+"/result", "", &result)
+		Expect(resp.StatusCode).To(Equal(200))
+		Expect(result.Kiyoshi).NotTo(BeNil())
+	})
+
+	It("lists, stops, and removes sessions.", func() {
+		var s Session
+		call("POST", "/sessions", `{"interval": "1h"}`, &s)
+
+		var sessions []Session
+		call("GET", "/sessions", "", &sessions)
+		Expect(sessions).To(HaveLen(1))
+
+		resp := call("GET", "/sessions/"+s.Id+"/result", "", nil)
+		Expect(resp.StatusCode).To(Equal(409))
+
+		var stopped Session
+		resp = call("DELETE", "/sessions/"+s.Id, "", &stopped)
+		Expect(resp.StatusCode).To(Equal(200))
+		Expect(stopped.State).To(Equal(SessionStopped))
+
+		resp = call("GET", "/sessions/"+s.Id, "", nil)
+		Expect(resp.StatusCode).To(Equal(404))
+		call("GET", "/sessions", "", &sessions)
+		Expect(sessions).To(BeEmpty())
+	})
+
+	It("responds 400 for an invalid configuration.", func() {
+		var body map[string]string
+		resp := call("POST", "/sessions", `{"interval": "soon"}`, &body)
+
+		Expect(resp.StatusCode).To(Equal(400))
+		Expect(body["error"]).NotTo(BeEmpty())
+
+		resp = call("POST", "/sessions", `{"players": 3}`, &body)
+		Expect(resp.StatusCode).To(Equal(400))
+
+		resp = call("POST", "/sessions", `{"pattern": ["Zun", "Don"]}`, &body)
+		Expect(resp.StatusCode).To(Equal(400))
+		Expect(body["error"]).To(ContainSubstring("Don"))
+	})
+
+	It("responds 503 after the manager is shut down.", func() {
+		Expect(m.Shutdown(context.Background())).To(Succeed())
+
+		var body map[string]string
+		resp := call("POST", "/sessions", `{}`, &body)
+
+		Expect(resp.StatusCode).To(Equal(503))
+		Expect(body["error"]).To(Equal(ErrShutdown.Error()))
+	})
+
+	It("responds 404 for an unknown session.", func() {
+		var body map[string]string
+		resp := call("GET", "/sessions/unknown", "", &body)
+
+		Expect(resp.StatusCode).To(Equal(404))
+		Expect(body["error"]).To(ContainSubstring("unknown"))
+	})
+})
@@ -0,0 +1,284 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/client"
+	"github.com/kaitoy/zundoko-go-client/pkg/model"
+	"github.com/kaitoy/zundoko-go-client/pkg/runner"
+	"github.com/kaitoy/zundoko-go-client/pkg/util"
+)
+
+var (
+	// ErrSessionNotFound is returned for an unknown session id.
+	ErrSessionNotFound = errors.New("session not found")
+
+	// ErrInvalidSessionConfig is returned for a SessionConfig that can't be started.
+	ErrInvalidSessionConfig = errors.New("invalid session config")
+
+	// ErrShutdown is returned when a session is started after the Manager is shut down.
+	ErrShutdown = errors.New("the manager is shut down")
+)
+
+// maxEndedSessions is the number of ended sessions a Manager keeps by default.
+const maxEndedSessions = 1000
+
+// Manager runs Zundoko Kiyoshi sessions concurrently. It's safe for concurrent use.
+type Manager interface {
+	// Start starts a session as configured in the background and returns its status.
+	Start(config SessionConfig) (Session, error)
+
+	// Stop stops the session of the id, waits for it to end, and returns its status.
+	// It does nothing to a session that has already ended.
+	Stop(id string) (Session, error)
+
+	// Remove stops the session of the id as Stop does and forgets it, returning its status as it ended.
+	Remove(id string) (Session, error)
+
+	// Get returns the status of the session of the id.
+	Get(id string) (Session, error)
+
+	// List returns the statuses of all the sessions in the order they started.
+	List() []Session
+
+	// Shutdown stops all the running sessions and waits for them to end, or until ctx is done.
+	// Sessions can't be started after it's called.
+	Shutdown(ctx context.Context) error
+}
+
+// ClientFactory creates a Client to call APIs of Zundoko Server at the URL.
+type ClientFactory func(server string) client.Client
+
+// NewManager creates a Manager whose sessions call Zundoko Server through Clients created by newClient.
+// defaultServer is the URL of Zundoko Server for sessions configured without one.
+// opts are applied to the Runner of every session before the options of its SessionConfig,
+// which take precedence.
+// The Manager keeps the last 1000 ended sessions, forgetting the ones that ended earlier.
+func NewManager(newClient ClientFactory, defaultServer string, opts ...runner.Option) Manager {
+	return &manager{
+		newClient:     newClient,
+		defaultServer: defaultServer,
+		opts:          opts,
+		maxEnded:      maxEndedSessions,
+		sessions:      make(map[string]*session),
+	}
+}
+
+type manager struct {
+	newClient     ClientFactory
+	defaultServer string
+	opts          []runner.Option
+
+	// maxEnded is the number of ended sessions to keep.
+	maxEnded int
+
+	mu       sync.Mutex
+	sessions map[string]*session
+	// ended holds ids of the ended sessions in the order they ended.
+	ended  []string
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// session is a running or ended session.
+type session struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu     sync.Mutex
+	status Session
+}
+
+func (s *session) get() Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+func (m *manager) Start(config SessionConfig) (Session, error) {
+	if err := config.validate(); err != nil {
+		return Session{}, fmt.Errorf("%w: %v", ErrInvalidSessionConfig, err)
+	}
+	if config.Server == "" {
+		config.Server = m.defaultServer
+	}
+	if config.Pattern == nil {
+		config.Pattern = runner.Pattern()
+	}
+	if config.Interval == 0 {
+		config.Interval = Duration(time.Second)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return Session{}, ErrShutdown
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &session{
+		cancel: cancel,
+		done:   make(chan struct{}),
+		status: Session{
+			Id:        util.NewUUID().String(),
+			Config:    config,
+			State:     SessionRunning,
+			StartedAt: time.Now(),
+		},
+	}
+	m.sessions[s.status.Id] = s
+
+	opts := append([]runner.Option{runner.WithPresenter(runner.NewSilentPresenter())}, m.opts...)
+	opts = append(
+		opts,
+		runner.WithPacer(runner.NewFixedPacer(time.Duration(config.Interval))),
+		runner.WithPattern(config.Pattern),
+	)
+	if config.LocalDetection {
+		opts = append(opts, runner.WithLocalDetection(runner.LocalDetection{}))
+	}
+	opts = append(opts, runner.WithObserver(runner.ObserverFuncs{
+		Word: func(*model.Zundoko, int) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.status.Words++
+		},
+	}))
+	r := runner.NewRunner(m.newClient(config.Server), opts...)
+
+	id := s.status.Id
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer close(s.done)
+		defer cancel()
+		result, err := r.Run(ctx)
+		s.finish(ctx, result, err)
+		m.retire(id)
+	}()
+	return s.get(), nil
+}
+
+// finish records the end of the session.
+func (s *session) finish(ctx context.Context, result *runner.Result, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	finishedAt := time.Now()
+	s.status.FinishedAt = &finishedAt
+	s.status.Result = result
+	switch {
+	case err == nil:
+		s.status.State = SessionSucceeded
+	case ctx.Err() != nil:
+		s.status.State = SessionStopped
+	default:
+		s.status.State = SessionFailed
+		s.status.Error = err.Error()
+	}
+}
+
+// retire records the session of the id ended, and forgets the oldest ended sessions over maxEnded.
+func (m *manager) retire(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.sessions[id]; !ok {
+		return
+	}
+	m.ended = append(m.ended, id)
+	for len(m.ended) > m.maxEnded {
+		delete(m.sessions, m.ended[0])
+		m.ended = m.ended[1:]
+	}
+}
+
+func (m *manager) Stop(id string) (Session, error) {
+	s, err := m.session(id)
+	if err != nil {
+		return Session{}, err
+	}
+	s.cancel()
+	<-s.done
+	return s.get(), nil
+}
+
+func (m *manager) Remove(id string) (Session, error) {
+	s, err := m.Stop(id)
+	if err != nil {
+		return Session{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, id)
+	for i, ended := range m.ended {
+		if ended == id {
+			m.ended = append(m.ended[:i], m.ended[i+1:]...)
+			break
+		}
+	}
+	return s, nil
+}
+
+func (m *manager) Get(id string) (Session, error) {
+	s, err := m.session(id)
+	if err != nil {
+		return Session{}, err
+	}
+	return s.get(), nil
+}
+
+func (m *manager) session(id string) (*session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.sessions[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, id)
+	}
+	return s, nil
+}
+
+func (m *manager) List() []Session {
+	m.mu.Lock()
+	sessions := make([]Session, 0, len(m.sessions))
+	for _, s := range m.sessions {
+		sessions = append(sessions, s.get())
+	}
+	m.mu.Unlock()
+
+	sort.Slice(sessions, func(i, j int) bool {
+		if !sessions[i].StartedAt.Equal(sessions[j].StartedAt) {
+			return sessions[i].StartedAt.Before(sessions[j].StartedAt)
+		}
+		return sessions[i].Id < sessions[j].Id
+	})
+	return sessions
+}
+
+func (m *manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	m.closed = true
+	for _, s := range m.sessions {
+		s.cancel()
+	}
+	m.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,199 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/client"
+	"github.com/kaitoy/zundoko-go-client/pkg/history"
+	"github.com/kaitoy/zundoko-go-client/pkg/runner"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Manager", func() {
+	var (
+		servers []string
+		testee  Manager
+	)
+
+	BeforeEach(func() {
+		servers = nil
+		testee = NewManager(func(server string) client.Client {
+			servers = append(servers, server)
+			return history.NewMemoryClient(nil)
+		}, "http://default")
+	})
+
+	AfterEach(func() {
+		Expect(testee.Shutdown(context.Background())).To(Succeed())
+	})
+
+	// state returns a func to get the state of the session for Eventually.
+	state := func(id string) func() SessionState {
+		return func() SessionState {
+			s, _ := testee.Get(id)
+			return s.State
+		}
+	}
+
+	Describe("Start()", func() {
+		It("runs a session until a Kiyoshi.", func() {
+			s, err := testee.Start(SessionConfig{Interval: Duration(time.Millisecond)})
+
+			Expect(err).To(BeNil())
+			Expect(s.State).To(Equal(SessionRunning))
+			Expect(s.Config.Server).To(Equal("http://default"))
+			Expect(s.Config.Pattern).To(Equal([]string{"Zun", "Zun", "Zun", "Zun", "Doko"}))
+			Eventually(state(s.Id), 5*time.Second).Should(Equal(SessionSucceeded))
+
+			s, _ = testee.Get(s.Id)
+			Expect(s.Result.Kiyoshi).NotTo(BeNil())
+			Expect(s.Words).To(Equal(len(s.Result.Zundokos)))
+			Expect(s.FinishedAt).NotTo(BeNil())
+			Expect(servers).To(Equal([]string{"http://default"}))
+		})
+
+		It("runs a session with the configured server and pattern.", func() {
+			s, _ := testee.Start(SessionConfig{
+				Server:   "http://other",
+				Pattern:  []string{"Doko"},
+				Interval: Duration(time.Millisecond),
+			})
+
+			Eventually(state(s.Id), 5*time.Second).Should(Equal(SessionSucceeded))
+			s, _ = testee.Get(s.Id)
+			Expect(s.Result.Zundokos[len(s.Result.Zundokos)-1].Word).To(Equal("Doko"))
+			Expect(servers).To(Equal([]string{"http://other"}))
+		})
+
+		It("returns an error for an invalid configuration.", func() {
+			_, err := testee.Start(SessionConfig{Pattern: []string{"Zun", "Don"}})
+
+			Expect(err).To(MatchError(ContainSubstring("Don")))
+			Expect(errors.Is(err, ErrInvalidSessionConfig)).To(BeTrue())
+			Expect(testee.List()).To(BeEmpty())
+		})
+
+		It("returns ErrShutdown after shutdown.", func() {
+			Expect(testee.Shutdown(context.Background())).To(Succeed())
+
+			_, err := testee.Start(SessionConfig{})
+
+			Expect(errors.Is(err, ErrShutdown)).To(BeTrue())
+		})
+
+		It("applies the options of the session over the ones of the manager.", func() {
+			m := NewManager(func(string) client.Client {
+				return history.NewMemoryClient(nil)
+			}, "http://default", runner.WithPattern([]string{"Zun"}), runner.WithPacer(runner.NewFixedPacer(time.Hour)))
+			defer m.Shutdown(context.Background())
+
+			s, _ := m.Start(SessionConfig{Pattern: []string{"Doko"}, Interval: Duration(time.Millisecond)})
+
+			Eventually(func() SessionState {
+				s, _ := m.Get(s.Id)
+				return s.State
+			}, 5*time.Second).Should(Equal(SessionSucceeded))
+			s, _ = m.Get(s.Id)
+			Expect(s.Result.Zundokos[len(s.Result.Zundokos)-1].Word).To(Equal("Doko"))
+		})
+
+		It("forgets the oldest ended sessions over the retention.", func() {
+			testee.(*manager).maxEnded = 2
+			var ids []string
+			for i := 0; i < 3; i++ {
+				s, _ := testee.Start(SessionConfig{Pattern: []string{"Zun"}, Interval: Duration(time.Millisecond)})
+				Eventually(state(s.Id), 5*time.Second).Should(Equal(SessionSucceeded))
+				ids = append(ids, s.Id)
+			}
+			running, _ := testee.Start(SessionConfig{Interval: Duration(time.Hour)})
+
+			_, err := testee.Get(ids[0])
+			Expect(errors.Is(err, ErrSessionNotFound)).To(BeTrue())
+			var listed []string
+			for _, s := range testee.List() {
+				listed = append(listed, s.Id)
+			}
+			Expect(listed).To(Equal([]string{ids[1], ids[2], running.Id}))
+		})
+	})
+
+	Describe("Stop()", func() {
+		It("stops a running session.", func() {
+			s, _ := testee.Start(SessionConfig{Interval: Duration(time.Hour)})
+			Eventually(func() int {
+				s, _ := testee.Get(s.Id)
+				return s.Words
+			}).Should(Equal(1))
+
+			s, err := testee.Stop(s.Id)
+
+			Expect(err).To(BeNil())
+			Expect(s.State).To(Equal(SessionStopped))
+			Expect(s.Words).To(Equal(1))
+			Expect(s.Result).NotTo(BeNil())
+		})
+
+		It("returns ErrSessionNotFound for an unknown session.", func() {
+			_, err := testee.Stop("unknown")
+
+			Expect(errors.Is(err, ErrSessionNotFound)).To(BeTrue())
+		})
+	})
+
+	Describe("Remove()", func() {
+		It("stops and forgets a running session.", func() {
+			s, _ := testee.Start(SessionConfig{Interval: Duration(time.Hour)})
+
+			s, err := testee.Remove(s.Id)
+
+			Expect(err).To(BeNil())
+			Expect(s.State).To(Equal(SessionStopped))
+			_, err = testee.Get(s.Id)
+			Expect(errors.Is(err, ErrSessionNotFound)).To(BeTrue())
+		})
+
+		It("forgets an ended session.", func() {
+			s, _ := testee.Start(SessionConfig{Pattern: []string{"Zun"}, Interval: Duration(time.Millisecond)})
+			Eventually(state(s.Id), 5*time.Second).Should(Equal(SessionSucceeded))
+
+			s, err := testee.Remove(s.Id)
+
+			Expect(err).To(BeNil())
+			Expect(s.State).To(Equal(SessionSucceeded))
+			Expect(testee.List()).To(BeEmpty())
+			Expect(testee.(*manager).ended).To(BeEmpty())
+		})
+
+		It("returns ErrSessionNotFound for an unknown session.", func() {
+			_, err := testee.Remove("unknown")
+
+			Expect(errors.Is(err, ErrSessionNotFound)).To(BeTrue())
+		})
+	})
+
+	Describe("List()", func() {
+		It("lists the sessions in the order they started.", func() {
+			first, _ := testee.Start(SessionConfig{Interval: Duration(time.Hour)})
+			second, _ := testee.Start(SessionConfig{Interval: Duration(time.Hour)})
+
+			sessions := testee.List()
+
+			Expect(sessions).To(HaveLen(2))
+			Expect(sessions[0].Id).To(Equal(first.Id))
+			Expect(sessions[1].Id).To(Equal(second.Id))
+		})
+	})
+
+	Describe("Shutdown()", func() {
+		It("stops all the running sessions.", func() {
+			s, _ := testee.Start(SessionConfig{Interval: Duration(time.Hour)})
+
+			Expect(testee.Shutdown(context.Background())).To(Succeed())
+
+			Expect(state(s.Id)()).To(Equal(SessionStopped))
+		})
+	})
+})
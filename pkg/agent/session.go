@@ -0,0 +1,110 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/runner"
+)
+
+// SessionConfig represents configuration of a session.
+type SessionConfig struct {
+	// Server is the URL of Zundoko Server. Defaults to the one given to the Manager.
+	Server string `json:"server,omitempty"`
+
+	// Pattern is the sequence of words that makes a Kiyoshi. Defaults to runner.Pattern().
+	Pattern []string `json:"pattern,omitempty"`
+
+	// Interval is the interval between words. Defaults to 1 second.
+	Interval Duration `json:"interval,omitempty"`
+
+	// LocalDetection makes the session detect the pattern locally, as runner.WithLocalDetection does.
+	LocalDetection bool `json:"localDetection,omitempty"`
+}
+
+// Duration is a time.Duration that is a string like "1.5s" in JSON. A number of nanoseconds is also accepted.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case float64:
+		*d = Duration(v)
+	case string:
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			return err
+		}
+		*d = Duration(parsed)
+	default:
+		return fmt.Errorf("invalid duration: %s", b)
+	}
+	return nil
+}
+
+// validate returns an error if the configuration is invalid.
+func (c SessionConfig) validate() error {
+	if c.Interval < 0 {
+		return errors.New("interval must not be negative")
+	}
+	for _, word := range c.Pattern {
+		if word != "Zun" && word != "Doko" {
+			return fmt.Errorf("unknown word in pattern: %s", word)
+		}
+	}
+	return nil
+}
+
+// SessionState represents a state of a session.
+type SessionState string
+
+// List of SessionState
+const (
+	// SessionRunning is the state of a session until it ends.
+	SessionRunning SessionState = "running"
+
+	// SessionSucceeded is the state of a session that made a Kiyoshi.
+	SessionSucceeded SessionState = "succeeded"
+
+	// SessionFailed is the state of a session that ended with an error.
+	SessionFailed SessionState = "failed"
+
+	// SessionStopped is the state of a session stopped by Manager.Stop or Manager.Shutdown.
+	SessionStopped SessionState = "stopped"
+)
+
+// Session represents a status of a session.
+type Session struct {
+	// Id identifies the session.
+	Id string `json:"id"`
+
+	// Config is the configuration of the session with the defaults applied.
+	Config SessionConfig `json:"config"`
+
+	// State is the current state.
+	State SessionState `json:"state"`
+
+	// StartedAt is the time the session started.
+	StartedAt time.Time `json:"startedAt"`
+
+	// FinishedAt is the time the session ended, or nil while it's running.
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+
+	// Words is the number of words said so far.
+	Words int `json:"words"`
+
+	// Error is the error the session failed with.
+	Error string `json:"error,omitempty"`
+
+	// Result is the result of the session, or nil while it's running.
+	Result *runner.Result `json:"result,omitempty"`
+}
@@ -0,0 +1,155 @@
+package analysis
+
+import (
+	"sort"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/history"
+	"github.com/kaitoy/zundoko-go-client/pkg/runner"
+	"github.com/kaitoy/zundoko-go-client/pkg/stats"
+)
+
+// Report represents statistics of a history.
+type Report struct {
+	// Words is the number of Zundokos.
+	Words int `json:"words"`
+
+	// Frequencies is the frequency of each word.
+	Frequencies map[string]Frequency `json:"frequencies"`
+
+	// LongestZunStreak is the largest number of "Zun"s said in a row.
+	LongestZunStreak int `json:"longestZunStreak"`
+
+	// KiyoshiPoints is the number of times the words completed the pattern, where a Kiyoshi could be made.
+	KiyoshiPoints int `json:"kiyoshiPoints"`
+
+	// MeanWordsToKiyoshi is the mean number of words said from the start or the previous Kiyoshi point
+	// to a Kiyoshi point.
+	MeanWordsToKiyoshi float64 `json:"meanWordsToKiyoshi"`
+
+	// Kiyoshies is the number of Kiyoshies made.
+	Kiyoshies int `json:"kiyoshies"`
+
+	// Intervals is the distribution of time between consecutive words.
+	Intervals stats.Distribution `json:"intervals"`
+
+	// Players is the breakdown of the Kiyoshies by who made them, in descending order of their Kiyoshies.
+	// It's empty if none of the Kiyoshies has MadeBy.
+	Players []Player `json:"players,omitempty"`
+}
+
+// Frequency represents how often a word was said.
+type Frequency struct {
+	// Count is the number of times the word was said.
+	Count int `json:"count"`
+
+	// Ratio is the ratio of the count to all the words.
+	Ratio float64 `json:"ratio"`
+}
+
+// Player represents statistics of Kiyoshies made by a player.
+type Player struct {
+	// MadeBy is the MadeBy of the Kiyoshies, which is empty for ones without it.
+	MadeBy string `json:"madeBy"`
+
+	// Kiyoshies is the number of the Kiyoshies.
+	Kiyoshies int `json:"kiyoshies"`
+
+	// MeanWords is the mean number of words said since the previous Kiyoshi of anyone before each of the Kiyoshies.
+	MeanWords float64 `json:"meanWords"`
+}
+
+// Analyze calculates the statistics of the history, whose Zundokos and Kiyoshies are sorted
+// in the order they were said, as ones read or fetched by the history package are.
+func Analyze(h *history.History) *Report {
+	report := &Report{
+		Words:       len(h.Zundokos),
+		Frequencies: make(map[string]Frequency),
+		Kiyoshies:   len(h.Kiyoshies),
+	}
+
+	pattern := runner.Pattern()
+	words := make([]string, 0, len(h.Zundokos))
+	streak, sinceKiyoshi, wordsToKiyoshi := 0, 0, 0
+	var intervals []time.Duration
+	for i, zd := range h.Zundokos {
+		freq := report.Frequencies[zd.Word]
+		freq.Count++
+		report.Frequencies[zd.Word] = freq
+
+		if zd.Word == "Zun" {
+			streak++
+			if streak > report.LongestZunStreak {
+				report.LongestZunStreak = streak
+			}
+		} else {
+			streak = 0
+		}
+
+		words = append(words, zd.Word)
+		sinceKiyoshi++
+		if runner.EndsWithPattern(words, pattern) {
+			report.KiyoshiPoints++
+			wordsToKiyoshi += sinceKiyoshi
+			sinceKiyoshi = 0
+		}
+
+		if i > 0 && !zd.SaidAt.IsZero() && !h.Zundokos[i-1].SaidAt.IsZero() {
+			intervals = append(intervals, zd.SaidAt.Sub(h.Zundokos[i-1].SaidAt))
+		}
+	}
+
+	for word, freq := range report.Frequencies {
+		freq.Ratio = float64(freq.Count) / float64(report.Words)
+		report.Frequencies[word] = freq
+	}
+	if report.KiyoshiPoints > 0 {
+		report.MeanWordsToKiyoshi = float64(wordsToKiyoshi) / float64(report.KiyoshiPoints)
+	}
+	report.Intervals = stats.NewDistribution(intervals)
+	report.Players = players(h)
+	return report
+}
+
+// players breaks down the Kiyoshies in the history by MadeBy.
+func players(h *history.History) []Player {
+	hasMadeBy := false
+	for _, k := range h.Kiyoshies {
+		if k.MadeBy != "" {
+			hasMadeBy = true
+		}
+	}
+	if !hasMadeBy {
+		return nil
+	}
+
+	byMadeBy := make(map[string]*Player)
+	words := make(map[string]int)
+	var players []Player
+	zi := 0
+	for _, k := range h.Kiyoshies {
+		since := 0
+		for ; zi < len(h.Zundokos) && !h.Zundokos[zi].SaidAt.After(k.SaidAt); zi++ {
+			since++
+		}
+		p, ok := byMadeBy[k.MadeBy]
+		if !ok {
+			p = &Player{MadeBy: k.MadeBy}
+			byMadeBy[k.MadeBy] = p
+		}
+		p.Kiyoshies++
+		words[k.MadeBy] += since
+	}
+
+	for madeBy, p := range byMadeBy {
+		p.MeanWords = float64(words[madeBy]) / float64(p.Kiyoshies)
+		players = append(players, *p)
+	}
+	sort.Slice(players, func(i, j int) bool {
+		if players[i].Kiyoshies != players[j].Kiyoshies {
+			return players[i].Kiyoshies > players[j].Kiyoshies
+		}
+		return players[i].MadeBy < players[j].MadeBy
+	})
+	return players
+}
@@ -0,0 +1,127 @@
+package analysis
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/history"
+	"github.com/kaitoy/zundoko-go-client/pkg/model"
+	"github.com/kaitoy/zundoko-go-client/pkg/stats"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Analyze()", func() {
+	var (
+		start time.Time
+		h     *history.History
+	)
+
+	// say appends Zundokos of the words said at the intervals after the last one.
+	say := func(interval time.Duration, words ...string) {
+		for _, word := range words {
+			saidAt := start
+			if n := len(h.Zundokos); n > 0 {
+				saidAt = h.Zundokos[n-1].SaidAt.Add(interval)
+			}
+			h.Zundokos = append(h.Zundokos, model.Zundoko{Id: word, SaidAt: saidAt, Word: word})
+		}
+	}
+
+	// kiyoshi appends a Kiyoshi made by the player right after the last Zundoko.
+	kiyoshi := func(madeBy string) {
+		h.Kiyoshies = append(h.Kiyoshies, model.Kiyoshi{
+			SaidAt: h.Zundokos[len(h.Zundokos)-1].SaidAt.Add(time.Millisecond),
+			MadeBy: madeBy,
+		})
+	}
+
+	BeforeEach(func() {
+		start = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		h = &history.History{}
+	})
+
+	It("reports statistics of the words.", func() {
+		say(time.Second, "Doko", "Zun", "Zun", "Zun", "Zun", "Zun", "Doko")
+		kiyoshi("alice@example.com")
+		say(3*time.Second, "Zun", "Zun", "Zun", "Zun", "Doko")
+		kiyoshi("bob@example.com")
+		say(time.Second, "Zun")
+
+		report := Analyze(h)
+
+		Expect(report.Words).To(Equal(13))
+		Expect(report.Frequencies["Zun"].Count).To(Equal(10))
+		Expect(report.Frequencies["Doko"].Ratio).To(BeNumerically("~", 3.0/13))
+		Expect(report.LongestZunStreak).To(Equal(5))
+		Expect(report.KiyoshiPoints).To(Equal(2))
+		Expect(report.MeanWordsToKiyoshi).To(Equal(6.0))
+		Expect(report.Kiyoshies).To(Equal(2))
+		Expect(report.Intervals.Count).To(Equal(12))
+		Expect(report.Intervals.Min).To(Equal(time.Second))
+		Expect(report.Intervals.Max).To(Equal(3 * time.Second))
+		Expect(report.Players).To(Equal([]Player{
+			{MadeBy: "alice@example.com", Kiyoshies: 1, MeanWords: 7},
+			{MadeBy: "bob@example.com", Kiyoshies: 1, MeanWords: 5},
+		}))
+	})
+
+	It("omits the breakdown by players if no Kiyoshi has MadeBy.", func() {
+		say(time.Second, "Zun", "Zun", "Zun", "Zun", "Doko")
+		kiyoshi("")
+
+		Expect(Analyze(h).Players).To(BeEmpty())
+	})
+
+	It("works for an empty history.", func() {
+		report := Analyze(h)
+
+		Expect(report.Words).To(BeZero())
+		Expect(report.MeanWordsToKiyoshi).To(BeZero())
+		Expect(report.Intervals).To(Equal(stats.Distribution{}))
+	})
+
+	Describe("Report", func() {
+		var (
+			report *Report
+		)
+
+		BeforeEach(func() {
+			say(time.Second, "Zun", "Zun", "Zun", "Zun", "Doko")
+			kiyoshi("alice|example")
+			report = Analyze(h)
+		})
+
+		It("is written in text.", func() {
+			var buf bytes.Buffer
+
+			Expect(report.WriteText(&buf)).To(Succeed())
+
+			Expect(buf.String()).To(ContainSubstring("Kiyoshi points:         1\n"))
+			Expect(buf.String()).To(ContainSubstring("Zun   4      80.00%\n"))
+			Expect(buf.String()).To(ContainSubstring("alice|example"))
+		})
+
+		It("is written in JSON.", func() {
+			var buf bytes.Buffer
+
+			Expect(report.WriteJSON(&buf)).To(Succeed())
+
+			var decoded Report
+			Expect(json.Unmarshal(buf.Bytes(), &decoded)).To(Succeed())
+			Expect(&decoded).To(Equal(report))
+		})
+
+		It("is written in Markdown tables.", func() {
+			var buf bytes.Buffer
+
+			Expect(report.WriteMarkdown(&buf)).To(Succeed())
+
+			Expect(buf.String()).To(ContainSubstring("| Kiyoshi points | 1 |\n"))
+			Expect(buf.String()).To(ContainSubstring("| Zun | 4 | 80.00% |\n"))
+			Expect(buf.String()).To(ContainSubstring("| Intervals | 4 | 1s | 1s | 1s | 1s | 1s | 1s | 1s |\n"))
+			Expect(buf.String()).To(ContainSubstring(`| alice\|example | 1 | 5.00 |`))
+		})
+	})
+})
@@ -0,0 +1,2 @@
+// Package analysis provides statistics over histories of Zundoko Kiyoshi games.
+package analysis
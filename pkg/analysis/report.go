@@ -0,0 +1,115 @@
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// WriteText writes the report in a human-readable text format.
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Words:\t%d\n", r.Words)
+	fmt.Fprintf(tw, "Longest Zun streak:\t%d\n", r.LongestZunStreak)
+	fmt.Fprintf(tw, "Kiyoshi points:\t%d\n", r.KiyoshiPoints)
+	fmt.Fprintf(tw, "Mean words to Kiyoshi:\t%.2f\n", r.MeanWordsToKiyoshi)
+	fmt.Fprintf(tw, "Kiyoshies:\t%d\n", r.Kiyoshies)
+	fmt.Fprintln(tw)
+
+	fmt.Fprintln(tw, "Word\tcount\tratio")
+	for _, word := range r.words() {
+		freq := r.Frequencies[word]
+		fmt.Fprintf(tw, "%s\t%d\t%.2f%%\n", word, freq.Count, freq.Ratio*100)
+	}
+	fmt.Fprintln(tw)
+
+	fmt.Fprintln(tw, "\tcount\tmin\tmean\tp50\tp90\tp95\tp99\tmax")
+	fmt.Fprintf(tw, "Intervals\t%d", r.Intervals.Count)
+	for _, d := range r.Intervals.Durations(time.Millisecond) {
+		fmt.Fprintf(tw, "\t%s", d)
+	}
+	fmt.Fprintln(tw)
+
+	if len(r.Players) > 0 {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "Player\tkiyoshies\tmean words")
+		for _, p := range r.Players {
+			fmt.Fprintf(tw, "%s\t%d\t%.2f\n", p.name(), p.Kiyoshies, p.MeanWords)
+		}
+	}
+
+	return tw.Flush()
+}
+
+// WriteJSON writes the report in JSON. Durations are in nanoseconds.
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// WriteMarkdown writes the report as Markdown tables.
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("| Metric | Value |\n|---|---:|\n")
+	fmt.Fprintf(&b, "| Words | %d |\n", r.Words)
+	fmt.Fprintf(&b, "| Longest Zun streak | %d |\n", r.LongestZunStreak)
+	fmt.Fprintf(&b, "| Kiyoshi points | %d |\n", r.KiyoshiPoints)
+	fmt.Fprintf(&b, "| Mean words to Kiyoshi | %.2f |\n", r.MeanWordsToKiyoshi)
+	fmt.Fprintf(&b, "| Kiyoshies | %d |\n", r.Kiyoshies)
+
+	b.WriteString("\n| Word | Count | Ratio |\n|---|---:|---:|\n")
+	for _, word := range r.words() {
+		freq := r.Frequencies[word]
+		fmt.Fprintf(&b, "| %s | %d | %.2f%% |\n", markdownEscape(word), freq.Count, freq.Ratio*100)
+	}
+
+	b.WriteString("\n| | Count | Min | Mean | P50 | P90 | P95 | P99 | Max |\n|---|---:|---:|---:|---:|---:|---:|---:|---:|\n")
+	fmt.Fprintf(&b, "| Intervals | %d |", r.Intervals.Count)
+	for _, d := range r.Intervals.Durations(time.Millisecond) {
+		fmt.Fprintf(&b, " %s |", d)
+	}
+	b.WriteString("\n")
+
+	if len(r.Players) > 0 {
+		b.WriteString("\n| Player | Kiyoshies | Mean words |\n|---|---:|---:|\n")
+		for _, p := range r.Players {
+			fmt.Fprintf(&b, "| %s | %d | %.2f |\n", markdownEscape(p.name()), p.Kiyoshies, p.MeanWords)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// words returns the words in the frequencies in descending order of their counts.
+func (r *Report) words() []string {
+	words := make([]string, 0, len(r.Frequencies))
+	for word := range r.Frequencies {
+		words = append(words, word)
+	}
+	sort.Slice(words, func(i, j int) bool {
+		ci, cj := r.Frequencies[words[i]].Count, r.Frequencies[words[j]].Count
+		if ci != cj {
+			return ci > cj
+		}
+		return words[i] < words[j]
+	})
+	return words
+}
+
+func (p Player) name() string {
+	if p.MadeBy == "" {
+		return "(unknown)"
+	}
+	return p.MadeBy
+}
+
+// markdownEscape escapes characters that break a cell of a Markdown table.
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
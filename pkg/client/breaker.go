@@ -0,0 +1,270 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/logging"
+	"github.com/kaitoy/zundoko-go-client/pkg/model"
+)
+
+// ErrCircuitOpen is returned by API calls made through a CircuitBreaker while it's open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState represents a state of a CircuitBreaker.
+type CircuitState int
+
+// List of CircuitState
+const (
+	// CircuitClosed is the state in which API calls are made.
+	CircuitClosed CircuitState = iota
+
+	// CircuitOpen is the state in which API calls fail fast with ErrCircuitOpen.
+	CircuitOpen
+
+	// CircuitHalfOpen is the state in which a trial API call is made to see if the server recovered.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", int(s))
+	}
+}
+
+// CircuitBreakerConfig represents configuration of a CircuitBreaker.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures that opens the circuit. Defaults to 5.
+	FailureThreshold int
+
+	// CoolDown is the time the circuit stays open before it gets half-open. Defaults to 30 seconds.
+	CoolDown time.Duration
+
+	// SuccessThreshold is the number of consecutive successful trial calls in half-open state
+	// that closes the circuit. Defaults to 1.
+	SuccessThreshold int
+}
+
+// CircuitBreakerMetrics represents metrics of a CircuitBreaker.
+type CircuitBreakerMetrics struct {
+	// State is the current state.
+	State CircuitState `json:"state"`
+
+	// Calls is the number of API calls made through the circuit.
+	Calls int `json:"calls"`
+
+	// Failures is the number of API calls that failed by transport errors or 5xx responses.
+	Failures int `json:"failures"`
+
+	// Rejections is the number of API calls rejected with ErrCircuitOpen.
+	Rejections int `json:"rejections"`
+
+	// Opens is the number of times the circuit opened.
+	Opens int `json:"opens"`
+
+	// LastStateChange is the time of the last state change.
+	LastStateChange time.Time `json:"lastStateChange"`
+}
+
+// CircuitBreaker is a Client that stops calling APIs for a while once they failed in a row.
+type CircuitBreaker interface {
+	Client
+
+	// State returns the current state.
+	State() CircuitState
+
+	// Metrics returns the metrics.
+	Metrics() CircuitBreakerMetrics
+}
+
+// NewCircuitBreaker creates a CircuitBreaker instance that wraps the given Client.
+func NewCircuitBreaker(cl Client, config CircuitBreakerConfig) CircuitBreaker {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 5
+	}
+	if config.CoolDown <= 0 {
+		config.CoolDown = 30 * time.Second
+	}
+	if config.SuccessThreshold <= 0 {
+		config.SuccessThreshold = 1
+	}
+	return &circuitBreaker{
+		cl:     cl,
+		config: config,
+		now:    time.Now,
+	}
+}
+
+type circuitBreaker struct {
+	cl     Client
+	config CircuitBreakerConfig
+	now    func() time.Time
+
+	mu        sync.Mutex
+	metrics   CircuitBreakerMetrics
+	failures  int
+	successes int
+	trying    bool
+}
+
+func (b *circuitBreaker) GetZundokos(ctx context.Context) (zundokos []model.Zundoko, err error) {
+	err = b.call(ctx, func() error {
+		zundokos, err = b.cl.GetZundokos(ctx)
+		return err
+	})
+	return zundokos, err
+}
+
+func (b *circuitBreaker) PostZundoko(ctx context.Context, zundoko *model.Zundoko) error {
+	return b.call(ctx, func() error {
+		return b.cl.PostZundoko(ctx, zundoko)
+	})
+}
+
+func (b *circuitBreaker) PostKiyoshi(ctx context.Context, kiyoshi *model.Kiyoshi) error {
+	return b.call(ctx, func() error {
+		return b.cl.PostKiyoshi(ctx, kiyoshi)
+	})
+}
+
+func (b *circuitBreaker) GetKiyoshies(ctx context.Context) (kiyoshies []model.Kiyoshi, err error) {
+	err = b.call(ctx, func() error {
+		kiyoshies, err = b.cl.GetKiyoshies(ctx)
+		return err
+	})
+	return kiyoshies, err
+}
+
+// ServerDate implements DateReporter if the wrapped Client does.
+func (b *circuitBreaker) ServerDate() (date, receivedAt time.Time, ok bool) {
+	if reporter, isReporter := b.cl.(DateReporter); isReporter {
+		return reporter.ServerDate()
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+func (b *circuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.coolDown()
+	return b.metrics.State
+}
+
+func (b *circuitBreaker) Metrics() CircuitBreakerMetrics {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.coolDown()
+	return b.metrics
+}
+
+// call makes the API call if the circuit allows it, and updates the state by the result.
+func (b *circuitBreaker) call(ctx context.Context, api func() error) error {
+	if err := b.allow(); err != nil {
+		return err
+	}
+
+	err := api()
+	b.done(ctx, err)
+	return err
+}
+
+// allow returns ErrCircuitOpen if the circuit doesn't allow an API call now.
+// In half-open state, only one trial call is allowed at a time.
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.coolDown()
+	switch b.metrics.State {
+	case CircuitOpen:
+		b.metrics.Rejections++
+		return ErrCircuitOpen
+	case CircuitHalfOpen:
+		if b.trying {
+			b.metrics.Rejections++
+			return ErrCircuitOpen
+		}
+		b.trying = true
+	}
+	b.metrics.Calls++
+	return nil
+}
+
+// done updates the state by the result of an API call.
+// A failure caused by cancellation of the caller's context is not counted.
+func (b *circuitBreaker) done(ctx context.Context, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trying = false
+	if err != nil && ctx.Err() != nil {
+		return
+	}
+
+	if isFailure(err) {
+		b.metrics.Failures++
+		b.successes = 0
+		b.failures++
+		if b.metrics.State == CircuitHalfOpen || b.failures >= b.config.FailureThreshold {
+			b.transit(CircuitOpen, err)
+		}
+		return
+	}
+
+	b.failures = 0
+	if b.metrics.State == CircuitHalfOpen {
+		b.successes++
+		if b.successes >= b.config.SuccessThreshold {
+			b.transit(CircuitClosed, nil)
+		}
+	}
+}
+
+// isFailure returns true if the error means the server is unhealthy, that is, the API call failed by a transport
+// error or a 5xx response. The other errors, such as ErrConflict, invalid responses, and 4xx responses, mean
+// the server is responding, so they don't count.
+func isFailure(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// coolDown makes the circuit half-open if it has been open for the cool-down time.
+func (b *circuitBreaker) coolDown() {
+	if b.metrics.State == CircuitOpen && b.now().Sub(b.metrics.LastStateChange) >= b.config.CoolDown {
+		b.transit(CircuitHalfOpen, nil)
+	}
+}
+
+func (b *circuitBreaker) transit(to CircuitState, cause error) {
+	from := b.metrics.State
+	b.metrics.State = to
+	b.metrics.LastStateChange = b.now()
+	b.failures = 0
+	b.successes = 0
+	if to == CircuitOpen {
+		b.metrics.Opens++
+	}
+
+	if cause != nil {
+		logging.GetLogger().Warnw("Circuit breaker state changed.", "from", from, "to", to, "cause", cause)
+	} else {
+		logging.GetLogger().Infow("Circuit breaker state changed.", "from", from, "to", to)
+	}
+}
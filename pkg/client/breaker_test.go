@@ -0,0 +1,234 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// fakeClient is a Client that returns err and counts calls.
+type fakeClient struct {
+	err   error
+	calls int
+}
+
+func (c *fakeClient) GetZundokos(ctx context.Context) ([]model.Zundoko, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return []model.Zundoko{{Word: "Zun"}}, nil
+}
+
+func (c *fakeClient) PostZundoko(ctx context.Context, zundoko *model.Zundoko) error {
+	c.calls++
+	return c.err
+}
+
+func (c *fakeClient) PostKiyoshi(ctx context.Context, kiyoshi *model.Kiyoshi) error {
+	c.calls++
+	return c.err
+}
+
+func (c *fakeClient) GetKiyoshies(ctx context.Context) ([]model.Kiyoshi, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return []model.Kiyoshi{{Id: "k1"}}, nil
+}
+
+var _ = Describe("CircuitBreaker", func() {
+	var (
+		wrapped *fakeClient
+		now     time.Time
+		testee  *circuitBreaker
+	)
+
+	BeforeEach(func() {
+		wrapped = &fakeClient{}
+		now = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		testee = NewCircuitBreaker(
+			wrapped,
+			CircuitBreakerConfig{FailureThreshold: 3, CoolDown: time.Minute},
+		).(*circuitBreaker)
+		testee.now = func() time.Time { return now }
+	})
+
+	openCircuit := func() {
+		wrapped.err = fmt.Errorf("some error: %w", &StatusError{StatusCode: 503, Status: "503 Service Unavailable"})
+		for i := 0; i < 3; i++ {
+			Expect(testee.PostZundoko(context.Background(), &model.Zundoko{})).To(Equal(wrapped.err))
+		}
+		Expect(testee.State()).To(Equal(CircuitOpen))
+	}
+
+	Describe("NewCircuitBreaker()", func() {
+		It("fills the config with defaults.", func() {
+			cb := NewCircuitBreaker(wrapped, CircuitBreakerConfig{}).(*circuitBreaker)
+
+			Expect(cb.config).To(Equal(CircuitBreakerConfig{
+				FailureThreshold: 5,
+				CoolDown:         30 * time.Second,
+				SuccessThreshold: 1,
+			}))
+			Expect(cb.State()).To(Equal(CircuitClosed))
+		})
+	})
+
+	Context("when closed", func() {
+		It("passes through the API calls and their results.", func() {
+			zundokos, err := testee.GetZundokos(context.Background())
+
+			Expect(err).To(BeNil())
+			Expect(zundokos).To(Equal([]model.Zundoko{{Word: "Zun"}}))
+			Expect(testee.PostKiyoshi(context.Background(), &model.Kiyoshi{})).To(Succeed())
+			Expect(wrapped.calls).To(Equal(2))
+		})
+
+		It("stays closed if failures don't reach the threshold in a row.", func() {
+			for i := 0; i < 3; i++ {
+				wrapped.err = fmt.Errorf("some error: %w", &StatusError{StatusCode: 503, Status: "503 Service Unavailable"})
+				testee.GetZundokos(context.Background())
+				testee.GetZundokos(context.Background())
+				wrapped.err = nil
+				testee.GetZundokos(context.Background())
+			}
+
+			Expect(testee.State()).To(Equal(CircuitClosed))
+		})
+
+		It("opens when transport errors reach the threshold in a row.", func() {
+			wrapped.err = fmt.Errorf("some error: %w", &url.Error{Op: "Post", URL: "http://localhost", Err: io.EOF})
+			for i := 0; i < 3; i++ {
+				testee.PostKiyoshi(context.Background(), &model.Kiyoshi{})
+			}
+
+			Expect(testee.State()).To(Equal(CircuitOpen))
+		})
+
+		It("doesn't count conflicts, 4xx responses, or invalid responses.", func() {
+			for _, err := range []error{
+				fmt.Errorf("some error: %w", ErrConflict),
+				fmt.Errorf("some error: %w", &StatusError{StatusCode: 400, Status: "400 Bad Request"}),
+				fmt.Errorf("some error: %w", &StatusError{StatusCode: 429, Status: "429 Too Many Requests"}),
+				fmt.Errorf("invalid response"),
+			} {
+				wrapped.err = err
+				for i := 0; i < 3; i++ {
+					Expect(testee.PostKiyoshi(context.Background(), &model.Kiyoshi{})).To(Equal(err))
+				}
+			}
+
+			Expect(testee.State()).To(Equal(CircuitClosed))
+			Expect(testee.Metrics().Failures).To(Equal(0))
+		})
+
+		It("doesn't count failures caused by cancellation of the caller's context.", func() {
+			wrapped.err = &url.Error{Op: "Get", URL: "http://localhost", Err: context.Canceled}
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			for i := 0; i < 3; i++ {
+				testee.GetZundokos(ctx)
+			}
+
+			Expect(testee.State()).To(Equal(CircuitClosed))
+		})
+
+		It("opens when failures reach the threshold in a row.", func() {
+			openCircuit()
+
+			metrics := testee.Metrics()
+			Expect(metrics.Failures).To(Equal(3))
+			Expect(metrics.Opens).To(Equal(1))
+			Expect(metrics.LastStateChange).To(Equal(now))
+		})
+	})
+
+	Context("when open", func() {
+		BeforeEach(openCircuit)
+
+		It("fails fast with ErrCircuitOpen.", func() {
+			wrapped.calls = 0
+
+			_, err := testee.GetZundokos(context.Background())
+
+			Expect(err).To(Equal(ErrCircuitOpen))
+			Expect(testee.PostZundoko(context.Background(), &model.Zundoko{})).To(Equal(ErrCircuitOpen))
+			Expect(testee.PostKiyoshi(context.Background(), &model.Kiyoshi{})).To(Equal(ErrCircuitOpen))
+			Expect(wrapped.calls).To(Equal(0))
+			Expect(testee.Metrics().Rejections).To(Equal(3))
+		})
+
+		It("gets half-open after the cool-down.", func() {
+			now = now.Add(59 * time.Second)
+			Expect(testee.State()).To(Equal(CircuitOpen))
+
+			now = now.Add(time.Second)
+			Expect(testee.State()).To(Equal(CircuitHalfOpen))
+		})
+	})
+
+	Context("when half-open", func() {
+		BeforeEach(func() {
+			openCircuit()
+			now = now.Add(time.Minute)
+		})
+
+		It("closes if the trial call succeeded.", func() {
+			wrapped.err = nil
+
+			Expect(testee.PostZundoko(context.Background(), &model.Zundoko{})).To(Succeed())
+
+			Expect(testee.State()).To(Equal(CircuitClosed))
+		})
+
+		It("opens again if the trial call failed.", func() {
+			Expect(testee.PostZundoko(context.Background(), &model.Zundoko{})).To(Equal(wrapped.err))
+
+			Expect(testee.State()).To(Equal(CircuitOpen))
+			Expect(testee.Metrics().Opens).To(Equal(2))
+		})
+
+		It("rejects calls while the trial call is in flight.", func() {
+			Expect(testee.allow()).To(Succeed())
+
+			Expect(testee.PostZundoko(context.Background(), &model.Zundoko{})).To(Equal(ErrCircuitOpen))
+		})
+	})
+
+	Describe("CircuitState", func() {
+		It("has a readable string representation.", func() {
+			Expect(CircuitClosed.String()).To(Equal("closed"))
+			Expect(CircuitOpen.String()).To(Equal("open"))
+			Expect(CircuitHalfOpen.String()).To(Equal("half-open"))
+		})
+	})
+
+	Describe("ServerDate()", func() {
+		It("reports the server date of the wrapped Client.", func() {
+			date := time.Date(2021, 1, 1, 1, 50, 0, 0, time.UTC)
+			wrapped := &client{}
+			wrapped.dates.record(&http.Response{Header: http.Header{"Date": []string{date.Format(http.TimeFormat)}}}, date)
+
+			serverDate, _, ok := NewCircuitBreaker(wrapped, CircuitBreakerConfig{}).(DateReporter).ServerDate()
+
+			Expect(ok).To(BeTrue())
+			Expect(serverDate).To(BeTemporally("==", date))
+		})
+
+		It("reports nothing if the wrapped Client isn't a DateReporter.", func() {
+			_, _, ok := NewCircuitBreaker(&fakeClient{}, CircuitBreakerConfig{}).(DateReporter).ServerDate()
+
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
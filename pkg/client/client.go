@@ -2,36 +2,126 @@ package client
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"time"
 
+	"github.com/kaitoy/zundoko-go-client/pkg/logging"
 	"github.com/kaitoy/zundoko-go-client/pkg/model"
+	"github.com/kaitoy/zundoko-go-client/pkg/openapi"
+	"github.com/kaitoy/zundoko-go-client/pkg/tracing"
 	"github.com/kaitoy/zundoko-go-client/pkg/util"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Client represents a Zundoko client.
+// A Client created by NewClient also implements DateReporter.
 type Client interface {
 	// GetZundokos calls GET Zundokos API and returns the results.
-	GetZundokos() ([]model.Zundoko, error)
+	GetZundokos(ctx context.Context) ([]model.Zundoko, error)
 
 	// PostZundoko calls POST Zundoko API and returns the result.
-	PostZundoko(zundoko *model.Zundoko) error
+	// The Id of the Zundoko is sent as the idempotency key, and a 409 response with the same Zundoko
+	// is treated as success.
+	PostZundoko(ctx context.Context, zundoko *model.Zundoko) error
 
 	// PostKiyoshi calls POST Kiyoshi API and returns the result.
-	PostKiyoshi(kiyoshi *model.Kiyoshi) error
+	// The Id of the Kiyoshi is sent as the idempotency key, and a 409 response with the same Kiyoshi
+	// is treated as success. A 409 response with another Kiyoshi, which means the posted one is a duplicate
+	// for the same pattern, fails with an error wrapping ErrConflict.
+	PostKiyoshi(ctx context.Context, kiyoshi *model.Kiyoshi) error
+
+	// GetKiyoshies calls GET Kiyoshies API and returns the results.
+	GetKiyoshies(ctx context.Context) ([]model.Kiyoshi, error)
+}
+
+// ErrConflict is wrapped in an error returned when a POST API responded 409 with another entity than the posted one.
+var ErrConflict = errors.New("conflict with an existing entity")
+
+// StatusError is wrapped in an error returned when an API responded an unexpected status.
+type StatusError struct {
+	// StatusCode is the status code of the response.
+	StatusCode int
+
+	// Status is the status line of the response, such as "503 Service Unavailable".
+	Status string
+}
+
+func (e *StatusError) Error() string {
+	return "status: " + e.Status
+}
+
+// RetryConfig represents configuration of retries of POST API calls.
+type RetryConfig struct {
+	// MaxRetries is the maximum number of retries of a call.
+	MaxRetries int
+
+	// Backoff is the wait before the first retry. It doubles for each subsequent retry.
+	Backoff time.Duration
+}
+
+// Option configures a Client.
+type Option func(c *client)
+
+// WithLimiter makes a Client wait for the Limiter before each API call.
+func WithLimiter(limiter Limiter) Option {
+	return func(c *client) {
+		c.limiter = limiter
+	}
+}
+
+// WithRetry makes a Client retry POST API calls that failed by transport errors such as timeouts
+// or by 502, 503, or 504 responses.
+// It's safe since the calls have idempotency keys and the server doesn't create a duplicate for them.
+func WithRetry(config RetryConfig) Option {
+	return func(c *client) {
+		c.retry = config
+	}
+}
+
+// WithStrictMode makes a Client validate response bodies against the schemas in the OpenAPI spec of Zundoko Server
+// before decoding them: required properties, types, enums, and formats such as uuid and date-time.
+// A call whose response violates them fails with an error wrapping *openapi.ValidationError, which lists every violation.
+func WithStrictMode() Option {
+	return func(c *client) {
+		c.strict = true
+	}
+}
+
+// DefaultMaxBodySize is the max size in bytes of a response body a Client reads unless WithMaxBodySize is given.
+const DefaultMaxBodySize = 64 << 20
+
+// WithMaxBodySize makes a Client read at most maxBodySize bytes of a response body. A call whose response body
+// exceeds it fails with an error wrapping *http.MaxBytesError. Zero or a negative value means no limit.
+func WithMaxBodySize(maxBodySize int64) Option {
+	return func(c *client) {
+		c.maxBodySize = maxBodySize
+	}
 }
 
 // NewClient creates a Client instance.
-func NewClient(urlBase string) Client {
-	return &client{
-		urlBase,
-		&http.Client{
+// Response bodies are decoded as streams, so big histories are not read all into memory unless in strict mode
+// or in Protobuf.
+func NewClient(urlBase string, opts ...Option) Client {
+	c := &client{
+		urlBase: urlBase,
+		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
-		model.NewZundokoDecoder(),
+		limiter:     NewLimiter(nil),
+		maxBodySize: DefaultMaxBodySize,
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	c.zundokoDecoder = model.NewZundokoDecoderWithMaxBodySize(c.maxBodySize)
+	c.kiyoshiDecoder = model.NewKiyoshiDecoderWithMaxBodySize(c.maxBodySize)
+	return c
 }
 
 // client implements Client interface.
@@ -39,65 +129,292 @@ type client struct {
 	urlBase        string
 	httpClient     util.HTTPClient
 	zundokoDecoder model.ZundokoDecoder
+	kiyoshiDecoder model.KiyoshiDecoder
+	limiter        Limiter
+	retry          RetryConfig
+	strict         bool
+	maxBodySize    int64
+	format         Format
+	dates          dateRecorder
 }
 
-func (c *client) GetZundokos() ([]model.Zundoko, error) {
+func (c *client) GetZundokos(ctx context.Context) (zundokos []model.Zundoko, err error) {
 	req, _ := http.NewRequest("GET", c.urlBase+"/zundokos", nil)
+	c.accept(req)
+	ctx, span := startSpan(ctx, req)
+	defer func() { tracing.EndSpan(span, err) }()
 
-	resp, err := c.httpClient.Do(req)
+	release, err := c.limiter.Acquire(ctx, OperationGetZundokos)
+	if err != nil {
+		return nil, fmt.Errorf("GET Zundoko API call was not allowed: %w", err)
+	}
+	defer release()
+
+	resp, err := c.do(ctx, span, req)
 	if err != nil {
 		return nil, fmt.Errorf("GET Zundoko API call failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		err = fmt.Errorf("GET Zundoko API returned an error. status: %s", resp.Status)
+		err = fmt.Errorf("GET Zundoko API returned an error. %w", &StatusError{StatusCode: resp.StatusCode, Status: resp.Status})
 		return nil, err
 	}
 
-	return c.zundokoDecoder.DecodeList(resp.Body)
+	body, err := c.validate(resp, "GET", "/zundokos")
+	if err != nil {
+		return nil, fmt.Errorf("GET Zundoko API returned an invalid response: %w", err)
+	}
+	decoder, err := c.zundokoDecoderFor(resp)
+	if err != nil {
+		return nil, fmt.Errorf("GET Zundoko API returned an invalid response: %w", err)
+	}
+	return decoder.DecodeList(body)
 }
 
-func (c *client) PostZundoko(zundoko *model.Zundoko) error {
-	zundokoJSON, _ := json.Marshal(zundoko)
+func (c *client) PostZundoko(ctx context.Context, zundoko *model.Zundoko) (err error) {
+	encoder, err := model.NewZundokoEncoderFor(c.contentType())
+	if err != nil {
+		return err
+	}
+	zundokoBody, err := encoder.Encode(zundoko)
+	if err != nil {
+		return err
+	}
 	req, _ := http.NewRequest(
 		"POST",
 		c.urlBase+"/zundokos",
-		bytes.NewBuffer([]byte(zundokoJSON)),
+		bytes.NewBuffer(zundokoBody),
 	)
-	req.Header.Add("Content-type", "application/json")
+	req.Header.Add("Content-type", c.contentType())
+	c.accept(req)
+	req.Header.Add("Idempotency-Key", zundoko.Id)
+	ctx, span := startSpan(ctx, req)
+	defer func() { tracing.EndSpan(span, err) }()
 
-	resp, err := c.httpClient.Do(req)
+	release, err := c.limiter.Acquire(ctx, OperationPostZundoko)
+	if err != nil {
+		return fmt.Errorf("POST Zundoko API call was not allowed: %w", err)
+	}
+	defer release()
+
+	resp, err := c.post(ctx, span, req)
 	if err != nil {
 		return fmt.Errorf("POST Zundoko API call failed: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == 409 {
+		body, err := c.validate(resp, "POST", "/zundokos")
+		if err != nil {
+			return fmt.Errorf("POST Zundoko API returned an invalid response: %w", err)
+		}
+		decoder, err := c.zundokoDecoderFor(resp)
+		if err != nil {
+			return fmt.Errorf("POST Zundoko API returned an invalid response: %w", err)
+		}
+		existing, err := decoder.Decode(body)
+		if err != nil {
+			return fmt.Errorf("POST Zundoko API returned an invalid response: %w", err)
+		}
+		if existing.Id == zundoko.Id && existing.Word == zundoko.Word {
+			return nil
+		}
+		return fmt.Errorf("POST Zundoko API returned a conflict with Zundoko %s: %w", existing.Id, ErrConflict)
+	}
 	if resp.StatusCode != 201 {
-		return fmt.Errorf("POST Zundoko API returned an error. status: %s", resp.Status)
+		return fmt.Errorf("POST Zundoko API returned an error. %w", &StatusError{StatusCode: resp.StatusCode, Status: resp.Status})
 	}
 
 	return nil
 }
 
-func (c *client) PostKiyoshi(kiyoshi *model.Kiyoshi) error {
-	kiyoshiJSON, _ := json.Marshal(kiyoshi)
+func (c *client) PostKiyoshi(ctx context.Context, kiyoshi *model.Kiyoshi) (err error) {
+	encoder, err := model.NewKiyoshiEncoderFor(c.contentType())
+	if err != nil {
+		return err
+	}
+	kiyoshiBody, err := encoder.Encode(kiyoshi)
+	if err != nil {
+		return err
+	}
 	req, _ := http.NewRequest(
 		"POST",
 		c.urlBase+"/kiyoshies",
-		bytes.NewBuffer([]byte(kiyoshiJSON)),
+		bytes.NewBuffer(kiyoshiBody),
 	)
-	req.Header.Add("Content-type", "application/json")
+	req.Header.Add("Content-type", c.contentType())
+	c.accept(req)
+	req.Header.Add("Idempotency-Key", kiyoshi.Id)
+	ctx, span := startSpan(ctx, req)
+	defer func() { tracing.EndSpan(span, err) }()
 
-	resp, err := c.httpClient.Do(req)
+	release, err := c.limiter.Acquire(ctx, OperationPostKiyoshi)
+	if err != nil {
+		return fmt.Errorf("POST Kiyoshi API call was not allowed: %w", err)
+	}
+	defer release()
+
+	resp, err := c.post(ctx, span, req)
 	if err != nil {
 		return fmt.Errorf("POST Kiyoshi API call failed: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == 409 {
+		body, err := c.validate(resp, "POST", "/kiyoshies")
+		if err != nil {
+			return fmt.Errorf("POST Kiyoshi API returned an invalid response: %w", err)
+		}
+		decoder, err := c.kiyoshiDecoderFor(resp)
+		if err != nil {
+			return fmt.Errorf("POST Kiyoshi API returned an invalid response: %w", err)
+		}
+		existing, err := decoder.Decode(body)
+		if err != nil {
+			return fmt.Errorf("POST Kiyoshi API returned an invalid response: %w", err)
+		}
+		if existing.Id == kiyoshi.Id {
+			return nil
+		}
+		return fmt.Errorf("POST Kiyoshi API returned a conflict with Kiyoshi %s: %w", existing.Id, ErrConflict)
+	}
 	if resp.StatusCode != 201 {
-		return fmt.Errorf("POST Kiyoshi API returned an error. status: %s", resp.Status)
+		return fmt.Errorf("POST Kiyoshi API returned an error. %w", &StatusError{StatusCode: resp.StatusCode, Status: resp.Status})
 	}
 
 	return nil
 }
+
+func (c *client) GetKiyoshies(ctx context.Context) (kiyoshies []model.Kiyoshi, err error) {
+	req, _ := http.NewRequest("GET", c.urlBase+"/kiyoshies", nil)
+	c.accept(req)
+	ctx, span := startSpan(ctx, req)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	release, err := c.limiter.Acquire(ctx, OperationGetKiyoshies)
+	if err != nil {
+		return nil, fmt.Errorf("GET Kiyoshi API call was not allowed: %w", err)
+	}
+	defer release()
+
+	resp, err := c.do(ctx, span, req)
+	if err != nil {
+		return nil, fmt.Errorf("GET Kiyoshi API call failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		err = fmt.Errorf("GET Kiyoshi API returned an error. %w", &StatusError{StatusCode: resp.StatusCode, Status: resp.Status})
+		return nil, err
+	}
+
+	body, err := c.validate(resp, "GET", "/kiyoshies")
+	if err != nil {
+		return nil, fmt.Errorf("GET Kiyoshi API returned an invalid response: %w", err)
+	}
+	decoder, err := c.kiyoshiDecoderFor(resp)
+	if err != nil {
+		return nil, fmt.Errorf("GET Kiyoshi API returned an invalid response: %w", err)
+	}
+	return decoder.DecodeList(body)
+}
+
+// do sends the request with the given context, propagating its trace context in the headers.
+func (c *client) do(ctx context.Context, span trace.Span, req *http.Request) (*http.Response, error) {
+	req = req.WithContext(ctx)
+	tracing.Inject(ctx, req.Header)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	c.dates.record(resp, time.Now())
+	return resp, nil
+}
+
+// validate returns the body of the response of the API at the method and path. In strict mode, it reads the body
+// in JSON and validates it against the schema of the response in the OpenAPI spec, returning an error wrapping
+// *openapi.ValidationError if it's invalid. Bodies in the other formats are not validated.
+func (c *client) validate(resp *http.Response, method, path string) (io.Reader, error) {
+	if !c.strict || !isJSON(resp) {
+		return resp.Body, nil
+	}
+
+	spec, err := openapi.ZundokoSpec()
+	if err != nil {
+		return nil, err
+	}
+	var r io.Reader = resp.Body
+	if c.maxBodySize > 0 {
+		r = http.MaxBytesReader(nil, resp.Body, c.maxBodySize)
+	}
+	body, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the response body: %w", err)
+	}
+	if r := spec.Operation(method, path).Response(resp.StatusCode); r != nil {
+		if content, ok := r.Content[string(FormatJSON)]; ok {
+			if err := content.Schema.ValidateJSON(body); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return bytes.NewReader(body), nil
+}
+
+// ServerDate implements DateReporter.
+func (c *client) ServerDate() (date, receivedAt time.Time, ok bool) {
+	return c.dates.get()
+}
+
+// post sends the POST request, retrying it as configured by WithRetry.
+func (c *client) post(ctx context.Context, span trace.Span, req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := c.do(ctx, span, req)
+		if attempt > c.retry.MaxRetries || !isRetryable(ctx, resp, err) {
+			return resp, err
+		}
+
+		if resp != nil {
+			err = fmt.Errorf("status: %s", resp.Status)
+			resp.Body.Close()
+		}
+		logging.GetLogger().Warnw("Retry a POST API call.", "url", req.URL, "attempt", attempt, "err", err)
+		span.AddEvent("retry", trace.WithAttributes(attribute.Int("attempt", attempt)))
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(c.retry.Backoff << (attempt - 1)):
+		}
+		req.Body, _ = req.GetBody()
+	}
+}
+
+// isRetryable returns true if the result of an API call is a transient failure worth retrying.
+func isRetryable(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil
+	}
+	switch resp.StatusCode {
+	case 502, 503, 504:
+		return true
+	default:
+		return false
+	}
+}
+
+// startSpan starts a client span for the request as a child of the span in the given context.
+func startSpan(ctx context.Context, req *http.Request) (context.Context, trace.Span) {
+	return tracing.GetTracer().Start(
+		ctx,
+		req.Method+" "+req.URL.Path,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("url.full", req.URL.String()),
+		),
+	)
+}
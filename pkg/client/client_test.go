@@ -1,8 +1,10 @@
 package client
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"strings"
 	"time"
@@ -11,9 +13,15 @@ import (
 	"github.com/kaitoy/zundoko-go-client/mock/pkg/mock_model"
 	"github.com/kaitoy/zundoko-go-client/mock/pkg/mock_util"
 	"github.com/kaitoy/zundoko-go-client/pkg/model"
+	"github.com/kaitoy/zundoko-go-client/pkg/openapi"
 	"github.com/kaitoy/zundoko-go-client/pkg/util"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 var _ = Describe("Client", func() {
@@ -21,6 +29,7 @@ var _ = Describe("Client", func() {
 		mockCtrl           *gomock.Controller
 		mockHTTPClient     *mock_util.MockHTTPClient
 		mockZundokoDecoder *mock_model.MockZundokoDecoder
+		mockKiyoshiDecoder *mock_model.MockKiyoshiDecoder
 		testee             Client
 	)
 
@@ -28,10 +37,13 @@ var _ = Describe("Client", func() {
 		mockCtrl = gomock.NewController(GinkgoT())
 		mockHTTPClient = mock_util.NewMockHTTPClient(mockCtrl)
 		mockZundokoDecoder = mock_model.NewMockZundokoDecoder(mockCtrl)
+		mockKiyoshiDecoder = mock_model.NewMockKiyoshiDecoder(mockCtrl)
 		testee = &client{
-			"http://test",
-			mockHTTPClient,
-			mockZundokoDecoder,
+			urlBase:        "http://test",
+			httpClient:     mockHTTPClient,
+			zundokoDecoder: mockZundokoDecoder,
+			kiyoshiDecoder: mockKiyoshiDecoder,
+			limiter:        NewLimiter(nil),
 		}
 	})
 
@@ -45,6 +57,53 @@ var _ = Describe("Client", func() {
 
 			Expect(newClient).NotTo(BeNil())
 		})
+
+		It("applies the given options.", func() {
+			limiter := NewLimiter(nil)
+
+			retry := RetryConfig{MaxRetries: 3, Backoff: time.Second}
+
+			newClient := NewClient("http://hoge.com:1234", WithLimiter(limiter), WithRetry(retry))
+
+			Expect(newClient.(*client).limiter).To(BeIdenticalTo(limiter))
+			Expect(newClient.(*client).retry).To(Equal(retry))
+			Expect(newClient.(*client).strict).To(BeFalse())
+			Expect(newClient.(*client).maxBodySize).To(Equal(int64(DefaultMaxBodySize)))
+			Expect(NewClient("http://hoge.com:1234", WithStrictMode()).(*client).strict).To(BeTrue())
+			Expect(NewClient("http://hoge.com:1234", WithMaxBodySize(1024)).(*client).maxBodySize).To(Equal(int64(1024)))
+		})
+	})
+
+	Describe("ServerDate()", func() {
+		It("reports nothing before any response.", func() {
+			_, _, ok := testee.(DateReporter).ServerDate()
+
+			Expect(ok).To(BeFalse())
+		})
+
+		It("reports the Date header of the last response.", func() {
+			responseBody := mock_util.NewMockReadCloser(mockCtrl)
+			date := time.Date(2021, 1, 1, 1, 50, 0, 0, time.UTC)
+			mockHTTPClient.EXPECT().Do(gomock.Any()).Return(
+				&http.Response{
+					StatusCode: 200,
+					Header:     http.Header{"Date": []string{date.Format(http.TimeFormat)}},
+					Body:       responseBody,
+				},
+				nil,
+			)
+			mockZundokoDecoder.EXPECT().DecodeList(gomock.Eq(responseBody)).Return(nil, nil)
+			responseBody.EXPECT().Close()
+			before := time.Now()
+
+			_, err := testee.GetZundokos(context.Background())
+
+			Expect(err).To(BeNil())
+			serverDate, receivedAt, ok := testee.(DateReporter).ServerDate()
+			Expect(ok).To(BeTrue())
+			Expect(serverDate).To(BeTemporally("==", date))
+			Expect(receivedAt).To(BeTemporally(">=", before))
+		})
 	})
 
 	Describe("GetZundokos()", func() {
@@ -61,7 +120,7 @@ var _ = Describe("Client", func() {
 				err := fmt.Errorf("some error")
 				mockHTTPClient.EXPECT().Do(util.HTTPRequestEq(req)).Return(nil, err)
 
-				zundokos, retErr := testee.GetZundokos()
+				zundokos, retErr := testee.GetZundokos(context.Background())
 
 				Expect(zundokos).To(BeNil())
 				Expect(errors.Unwrap(retErr)).To(Equal(err))
@@ -81,14 +140,33 @@ var _ = Describe("Client", func() {
 					)
 					responseBody.EXPECT().Close()
 
-					zundokos, retErr := testee.GetZundokos()
+					zundokos, retErr := testee.GetZundokos(context.Background())
 
 					Expect(zundokos).To(BeNil())
 					Expect(retErr.Error()).To(ContainSubstring("awful error"))
+					var statusErr *StatusError
+					Expect(errors.As(retErr, &statusErr)).To(BeTrue())
+					Expect(statusErr.StatusCode).To(Equal(code))
 				})
 			}
 		})
 
+		Context("when the limiter doesn't allow the call", func() {
+			It("returns the error in a wrap without calling the API.", func() {
+				limiter := NewLimiter(Limits{OperationGetZundokos: {MaxInFlight: 1}})
+				_, err := limiter.Acquire(context.Background(), OperationGetZundokos)
+				Expect(err).To(BeNil())
+				testee.(*client).limiter = limiter
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+				defer cancel()
+
+				zundokos, retErr := testee.GetZundokos(ctx)
+
+				Expect(zundokos).To(BeNil())
+				Expect(errors.Unwrap(retErr)).To(Equal(context.DeadlineExceeded))
+			})
+		})
+
 		Context("when GET Zundokos API returned 200 response and decoding the response body", func() {
 			var (
 				responseBody *mock_util.MockReadCloser
@@ -114,7 +192,7 @@ var _ = Describe("Client", func() {
 					responseBody.EXPECT().Close(),
 				)
 
-				zundokos, retErr := testee.GetZundokos()
+				zundokos, retErr := testee.GetZundokos(context.Background())
 
 				Expect(zundokos).To(BeNil())
 				Expect(retErr).To(Equal(err))
@@ -127,18 +205,157 @@ var _ = Describe("Client", func() {
 					Return(expectedZundokos, nil)
 				responseBody.EXPECT().Close().After(callDecodeList)
 
-				zundokos, retErr := testee.GetZundokos()
+				zundokos, retErr := testee.GetZundokos(context.Background())
 
 				Expect(zundokos).To(Equal(expectedZundokos))
 				Expect(retErr).To(BeNil())
 			})
 		})
+		Context("with the generated decoder limiting the body size", func() {
+			BeforeEach(func() {
+				testee.(*client).zundokoDecoder = model.NewZundokoDecoderWithMaxBodySize(100)
+			})
+
+			// respond makes GET Zundokos API respond 200 with the body.
+			respond := func(body string) {
+				mockHTTPClient.EXPECT().Do(util.HTTPRequestEq(req)).Return(
+					&http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(body))},
+					nil,
+				)
+			}
+
+			It("decodes a body within the limit.", func() {
+				respond(`[{"id": "zd1", "saidAt": "2020-12-31T12:30:15Z", "word": "Zun"}]`)
+
+				zundokos, retErr := testee.GetZundokos(context.Background())
+
+				Expect(retErr).To(BeNil())
+				Expect(zundokos).To(Equal([]model.Zundoko{
+					{Id: "zd1", SaidAt: time.Date(2020, 12, 31, 12, 30, 15, 0, time.UTC), Word: "Zun"},
+				}))
+			})
+
+			It("returns an error wrapping *http.MaxBytesError for a body over the limit.", func() {
+				respond(`[{"id": "zd1", "saidAt": "2020-12-31T12:30:15Z", "word": "Zun"}, {"id": "zd2", "saidAt": "2020-12-31T12:30:16Z", "word": "Doko"}]`)
+
+				zundokos, retErr := testee.GetZundokos(context.Background())
+
+				Expect(zundokos).To(BeNil())
+				var maxBytesErr *http.MaxBytesError
+				Expect(errors.As(retErr, &maxBytesErr)).To(BeTrue())
+				Expect(maxBytesErr.Limit).To(Equal(int64(100)))
+			})
+
+			It("returns an error for a body not of an array.", func() {
+				respond(`{"id": "zd1"}`)
+
+				_, retErr := testee.GetZundokos(context.Background())
+
+				Expect(retErr).To(MatchError(ContainSubstring("not an array")))
+			})
+		})
+
+		Context("in strict mode", func() {
+			BeforeEach(func() {
+				testee.(*client).strict = true
+			})
+
+			// respond makes GET Zundokos API respond 200 with the body.
+			respond := func(body string) {
+				mockHTTPClient.EXPECT().Do(util.HTTPRequestEq(req)).Return(
+					&http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(body))},
+					nil,
+				)
+			}
+
+			It("decodes a valid response body.", func() {
+				respond(`[{"id": "91259080-1984-4a87-a671-f6adb641ef52", "saidAt": "2020-12-31T12:30:15Z", "word": "Zun"}]`)
+				expectedZundokos := []model.Zundoko{{Id: "91259080-1984-4a87-a671-f6adb641ef52", Word: "Zun"}}
+				mockZundokoDecoder.EXPECT().DecodeList(gomock.Any()).Return(expectedZundokos, nil)
+
+				zundokos, retErr := testee.GetZundokos(context.Background())
+
+				Expect(zundokos).To(Equal(expectedZundokos))
+				Expect(retErr).To(BeNil())
+			})
+
+			It("returns an error listing every violation without decoding an invalid response body.", func() {
+				respond(`[{"id": "91259080-1984-4a87-a671-f6adb641ef52", "word": "Don"}]`)
+
+				zundokos, retErr := testee.GetZundokos(context.Background())
+
+				Expect(zundokos).To(BeNil())
+				var verr *openapi.ValidationError
+				Expect(errors.As(retErr, &verr)).To(BeTrue())
+				Expect(verr.Violations).To(Equal([]openapi.Violation{
+					{Path: "[0]", Message: `missing required property "saidAt"`},
+					{Path: "[0].word", Message: `"Don" is not one of ["Zun", "Doko"]`},
+				}))
+			})
+
+			It("returns an error wrapping *http.MaxBytesError for a body over the max body size.", func() {
+				testee.(*client).maxBodySize = 10
+				respond(`[{"id": "91259080-1984-4a87-a671-f6adb641ef52", "saidAt": "2020-12-31T12:30:15Z", "word": "Zun"}]`)
+
+				_, retErr := testee.GetZundokos(context.Background())
+
+				var maxBytesErr *http.MaxBytesError
+				Expect(errors.As(retErr, &maxBytesErr)).To(BeTrue())
+			})
+		})
+
+		Context("when a span is in the given context", func() {
+			var (
+				recorder *tracetest.SpanRecorder
+			)
+
+			BeforeEach(func() {
+				recorder = tracetest.NewSpanRecorder()
+				otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+				otel.SetTextMapPropagator(propagation.TraceContext{})
+			})
+
+			AfterEach(func() {
+				otel.SetTracerProvider(noop.NewTracerProvider())
+				otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+			})
+
+			It("calls the API in a child span and propagates the trace context in the headers.", func() {
+				ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+				responseBody := mock_util.NewMockReadCloser(mockCtrl)
+				var sentReq *http.Request
+				mockHTTPClient.EXPECT().Do(gomock.Any()).DoAndReturn(
+					func(r *http.Request) (*http.Response, error) {
+						sentReq = r
+						return &http.Response{StatusCode: 200, Body: responseBody}, nil
+					},
+				)
+				mockZundokoDecoder.EXPECT().DecodeList(gomock.Eq(responseBody)).Return([]model.Zundoko{}, nil)
+				responseBody.EXPECT().Close()
+
+				_, retErr := testee.GetZundokos(ctx)
+				parent.End()
+
+				Expect(retErr).To(BeNil())
+				spans := recorder.Ended()
+				Expect(spans).To(HaveLen(2))
+				Expect(spans[0].Name()).To(Equal("GET /zundokos"))
+				Expect(spans[0].Parent().SpanID()).To(Equal(parent.SpanContext().SpanID()))
+				Expect(sentReq.Header.Get("traceparent")).To(
+					ContainSubstring(spans[0].SpanContext().SpanID().String()),
+				)
+				Expect(sentReq.Header.Get("traceparent")).To(
+					ContainSubstring(parent.SpanContext().TraceID().String()),
+				)
+			})
+		})
 	})
 
 	Describe("PostZundoko()", func() {
 		var (
-			zundoko *model.Zundoko
-			req     *http.Request
+			zundoko    *model.Zundoko
+			req        *http.Request
+			newRequest func() *http.Request
 		)
 
 		BeforeEach(func() {
@@ -148,14 +365,19 @@ var _ = Describe("Client", func() {
 				Word:   "Zun",
 			}
 
-			req, _ = http.NewRequest(
-				"POST",
-				"http://test/zundokos",
-				strings.NewReader(
-					`{"id":"91259080-1984-4a87-a671-f6adb641ef52","saidAt":"2020-12-31T12:30:15Z","word":"Zun"}`,
-				),
-			)
-			req.Header.Add("Content-type", "application/json")
+			newRequest = func() *http.Request {
+				req, _ := http.NewRequest(
+					"POST",
+					"http://test/zundokos",
+					strings.NewReader(
+						`{"id":"91259080-1984-4a87-a671-f6adb641ef52","saidAt":"2020-12-31T12:30:15Z","word":"Zun"}`,
+					),
+				)
+				req.Header.Add("Content-type", "application/json")
+				req.Header.Add("Idempotency-Key", "91259080-1984-4a87-a671-f6adb641ef52")
+				return req
+			}
+			req = newRequest()
 		})
 
 		Context("when calling POST Zundoko API", func() {
@@ -163,7 +385,7 @@ var _ = Describe("Client", func() {
 				err := fmt.Errorf("some error")
 				mockHTTPClient.EXPECT().Do(util.HTTPRequestEq(req)).Return(nil, err)
 
-				retErr := testee.PostZundoko(zundoko)
+				retErr := testee.PostZundoko(context.Background(), zundoko)
 
 				Expect(errors.Unwrap(retErr)).To(Equal(err))
 			})
@@ -182,9 +404,12 @@ var _ = Describe("Client", func() {
 					)
 					responseBody.EXPECT().Close()
 
-					retErr := testee.PostZundoko(zundoko)
+					retErr := testee.PostZundoko(context.Background(), zundoko)
 
 					Expect(retErr.Error()).To(ContainSubstring("awful error"))
+					var statusErr *StatusError
+					Expect(errors.As(retErr, &statusErr)).To(BeTrue())
+					Expect(statusErr.StatusCode).To(Equal(code))
 				})
 			}
 		})
@@ -208,17 +433,132 @@ var _ = Describe("Client", func() {
 			It("returns nil.", func() {
 				responseBody.EXPECT().Close()
 
-				retErr := testee.PostZundoko(zundoko)
+				retErr := testee.PostZundoko(context.Background(), zundoko)
+
+				Expect(retErr).To(BeNil())
+			})
+		})
+
+		Context("when POST Zundoko API returned 409 response", func() {
+			var (
+				responseBody *mock_util.MockReadCloser
+			)
+
+			BeforeEach(func() {
+				responseBody = mock_util.NewMockReadCloser(mockCtrl)
+				mockHTTPClient.EXPECT().Do(util.HTTPRequestEq(req)).Return(
+					&http.Response{
+						StatusCode: 409,
+						Body:       responseBody,
+					},
+					nil,
+				)
+				responseBody.EXPECT().Close()
+			})
+
+			It("returns nil if the existing Zundoko is the posted one.", func() {
+				mockZundokoDecoder.EXPECT().Decode(gomock.Eq(responseBody)).Return(&model.Zundoko{Id: zundoko.Id, SaidAt: zundoko.SaidAt, Word: "Zun"}, nil)
+
+				retErr := testee.PostZundoko(context.Background(), zundoko)
 
 				Expect(retErr).To(BeNil())
 			})
+
+			It("returns an error wrapping ErrConflict if the existing Zundoko is another one.", func() {
+				mockZundokoDecoder.EXPECT().Decode(gomock.Eq(responseBody)).Return(&model.Zundoko{Id: "0e8f5ad2-7a8e-4b53-9f4e-1c0e8f6c5a10", Word: "Zun"}, nil)
+
+				retErr := testee.PostZundoko(context.Background(), zundoko)
+
+				Expect(errors.Is(retErr, ErrConflict)).To(BeTrue())
+			})
+
+			It("returns an error wrapping the error if decoding the existing Zundoko failed.", func() {
+				err := fmt.Errorf("some IO error")
+				mockZundokoDecoder.EXPECT().Decode(gomock.Eq(responseBody)).Return(nil, err)
+
+				retErr := testee.PostZundoko(context.Background(), zundoko)
+
+				Expect(errors.Is(retErr, err)).To(BeTrue())
+				Expect(retErr).To(MatchError("POST Zundoko API returned an invalid response: some IO error"))
+			})
+		})
+
+		Context("when POST Zundoko API returned 409 response in strict mode", func() {
+			It("returns an error if the existing Zundoko is invalid.", func() {
+				testee.(*client).strict = true
+				mockHTTPClient.EXPECT().Do(util.HTTPRequestEq(req)).Return(
+					&http.Response{StatusCode: 409, Body: ioutil.NopCloser(strings.NewReader(`{"id": "zd1", "saidAt": "", "word": "Zun"}`))},
+					nil,
+				)
+
+				retErr := testee.PostZundoko(context.Background(), zundoko)
+
+				var verr *openapi.ValidationError
+				Expect(errors.As(retErr, &verr)).To(BeTrue())
+				Expect(verr.Violations).To(HaveLen(2))
+			})
+		})
+
+		Context("when retries are configured", func() {
+			BeforeEach(func() {
+				testee.(*client).retry = RetryConfig{MaxRetries: 2, Backoff: time.Millisecond}
+			})
+
+			It("retries the call with the same idempotency key on transient failures.", func() {
+				responseBody1 := mock_util.NewMockReadCloser(mockCtrl)
+				responseBody2 := mock_util.NewMockReadCloser(mockCtrl)
+				gomock.InOrder(
+					mockHTTPClient.EXPECT().Do(util.HTTPRequestEq(req)).Return(nil, fmt.Errorf("timeout")),
+					mockHTTPClient.EXPECT().Do(util.HTTPRequestEq(newRequest())).Return(
+						&http.Response{StatusCode: 503, Status: "503 Service Unavailable", Body: responseBody1},
+						nil,
+					),
+					responseBody1.EXPECT().Close(),
+					mockHTTPClient.EXPECT().Do(util.HTTPRequestEq(newRequest())).Return(
+						&http.Response{StatusCode: 201, Body: responseBody2},
+						nil,
+					),
+					responseBody2.EXPECT().Close(),
+				)
+
+				retErr := testee.PostZundoko(context.Background(), zundoko)
+
+				Expect(retErr).To(BeNil())
+			})
+
+			It("gives up after the max retries.", func() {
+				err := fmt.Errorf("timeout")
+				gomock.InOrder(
+					mockHTTPClient.EXPECT().Do(util.HTTPRequestEq(req)).Return(nil, err),
+					mockHTTPClient.EXPECT().Do(util.HTTPRequestEq(newRequest())).Return(nil, err),
+					mockHTTPClient.EXPECT().Do(util.HTTPRequestEq(newRequest())).Return(nil, err),
+				)
+
+				retErr := testee.PostZundoko(context.Background(), zundoko)
+
+				Expect(errors.Unwrap(retErr)).To(Equal(err))
+			})
+
+			It("doesn't retry on non-transient error responses.", func() {
+				responseBody := mock_util.NewMockReadCloser(mockCtrl)
+				mockHTTPClient.EXPECT().Do(util.HTTPRequestEq(req)).Return(
+					&http.Response{StatusCode: 500, Status: "awful error", Body: responseBody},
+					nil,
+				)
+				responseBody.EXPECT().Close()
+
+				retErr := testee.PostZundoko(context.Background(), zundoko)
+
+				Expect(retErr.Error()).To(ContainSubstring("awful error"))
+			})
 		})
 	})
 
 	Describe("PostKiyoshi()", func() {
 		var (
-			kiyoshi *model.Kiyoshi
-			req     *http.Request
+			kiyoshi    *model.Kiyoshi
+			req        *http.Request
+			newRequest func() *http.Request
 		)
 
 		BeforeEach(func() {
@@ -228,14 +568,19 @@ var _ = Describe("Client", func() {
 				MadeBy: "",
 			}
 
-			req, _ = http.NewRequest(
-				"POST",
-				"http://test/kiyoshies",
-				strings.NewReader(
-					`{"id":"91259080-1984-4a87-a671-f6adb641ef52","saidAt":"2021-01-01T12:30:15Z"}`,
-				),
-			)
-			req.Header.Add("Content-type", "application/json")
+			newRequest = func() *http.Request {
+				req, _ := http.NewRequest(
+					"POST",
+					"http://test/kiyoshies",
+					strings.NewReader(
+						`{"id":"91259080-1984-4a87-a671-f6adb641ef52","saidAt":"2021-01-01T12:30:15Z"}`,
+					),
+				)
+				req.Header.Add("Content-type", "application/json")
+				req.Header.Add("Idempotency-Key", "91259080-1984-4a87-a671-f6adb641ef52")
+				return req
+			}
+			req = newRequest()
 		})
 
 		Context("when calling POST Kiyoshi API", func() {
@@ -243,7 +588,7 @@ var _ = Describe("Client", func() {
 				err := fmt.Errorf("some error")
 				mockHTTPClient.EXPECT().Do(util.HTTPRequestEq(req)).Return(nil, err)
 
-				retErr := testee.PostKiyoshi(kiyoshi)
+				retErr := testee.PostKiyoshi(context.Background(), kiyoshi)
 
 				Expect(errors.Unwrap(retErr)).To(Equal(err))
 			})
@@ -262,9 +607,12 @@ var _ = Describe("Client", func() {
 					)
 					responseBody.EXPECT().Close()
 
-					retErr := testee.PostKiyoshi(kiyoshi)
+					retErr := testee.PostKiyoshi(context.Background(), kiyoshi)
 
 					Expect(retErr.Error()).To(ContainSubstring("awful error"))
+					var statusErr *StatusError
+					Expect(errors.As(retErr, &statusErr)).To(BeTrue())
+					Expect(statusErr.StatusCode).To(Equal(code))
 				})
 			}
 		})
@@ -288,10 +636,362 @@ var _ = Describe("Client", func() {
 			It("returns nil.", func() {
 				responseBody.EXPECT().Close()
 
-				retErr := testee.PostKiyoshi(kiyoshi)
+				retErr := testee.PostKiyoshi(context.Background(), kiyoshi)
+
+				Expect(retErr).To(BeNil())
+			})
+		})
+
+		Context("when POST Kiyoshi API returned 409 response", func() {
+			var (
+				responseBody *mock_util.MockReadCloser
+			)
+
+			BeforeEach(func() {
+				responseBody = mock_util.NewMockReadCloser(mockCtrl)
+				mockHTTPClient.EXPECT().Do(util.HTTPRequestEq(req)).Return(
+					&http.Response{
+						StatusCode: 409,
+						Body:       responseBody,
+					},
+					nil,
+				)
+				responseBody.EXPECT().Close()
+			})
+
+			It("returns nil if the existing Kiyoshi is the posted one.", func() {
+				mockKiyoshiDecoder.EXPECT().Decode(gomock.Eq(responseBody)).Return(&model.Kiyoshi{Id: kiyoshi.Id, SaidAt: kiyoshi.SaidAt}, nil)
+
+				retErr := testee.PostKiyoshi(context.Background(), kiyoshi)
+
+				Expect(retErr).To(BeNil())
+			})
+
+			It("returns an error wrapping ErrConflict if the existing Kiyoshi is another one.", func() {
+				mockKiyoshiDecoder.EXPECT().Decode(gomock.Eq(responseBody)).Return(&model.Kiyoshi{Id: "0e8f5ad2-7a8e-4b53-9f4e-1c0e8f6c5a10"}, nil)
+
+				retErr := testee.PostKiyoshi(context.Background(), kiyoshi)
+
+				Expect(errors.Is(retErr, ErrConflict)).To(BeTrue())
+			})
+
+			It("returns an error wrapping the error if decoding the existing Kiyoshi failed.", func() {
+				err := fmt.Errorf("some IO error")
+				mockKiyoshiDecoder.EXPECT().Decode(gomock.Eq(responseBody)).Return(nil, err)
+
+				retErr := testee.PostKiyoshi(context.Background(), kiyoshi)
+
+				Expect(errors.Is(retErr, err)).To(BeTrue())
+				Expect(retErr).To(MatchError("POST Kiyoshi API returned an invalid response: some IO error"))
+			})
+		})
+
+		Context("when POST Kiyoshi API returned 409 response for a duplicate in strict mode", func() {
+			It("returns an error wrapping ErrConflict for another Kiyoshi valid against the spec.", func() {
+				testee.(*client).strict = true
+				testee.(*client).kiyoshiDecoder = model.NewKiyoshiDecoder()
+				mockHTTPClient.EXPECT().Do(util.HTTPRequestEq(req)).Return(
+					&http.Response{
+						StatusCode: 409,
+						Header:     http.Header{"Content-Type": []string{"application/json"}},
+						Body:       ioutil.NopCloser(strings.NewReader(`{"id": "0e8f5ad2-7a8e-4b53-9f4e-1c0e8f6c5a10", "saidAt": "2021-01-01T12:30:14Z"}`)),
+					},
+					nil,
+				)
+
+				retErr := testee.PostKiyoshi(context.Background(), kiyoshi)
+
+				Expect(errors.Is(retErr, ErrConflict)).To(BeTrue())
+				Expect(retErr).To(MatchError(ContainSubstring("0e8f5ad2-7a8e-4b53-9f4e-1c0e8f6c5a10")))
+			})
+		})
+
+		Context("when retries are configured", func() {
+			BeforeEach(func() {
+				testee.(*client).retry = RetryConfig{MaxRetries: 2, Backoff: time.Millisecond}
+			})
+
+			It("retries the call with the same idempotency key on transient failures.", func() {
+				responseBody1 := mock_util.NewMockReadCloser(mockCtrl)
+				responseBody2 := mock_util.NewMockReadCloser(mockCtrl)
+				gomock.InOrder(
+					mockHTTPClient.EXPECT().Do(util.HTTPRequestEq(req)).Return(nil, fmt.Errorf("timeout")),
+					mockHTTPClient.EXPECT().Do(util.HTTPRequestEq(newRequest())).Return(
+						&http.Response{StatusCode: 503, Status: "503 Service Unavailable", Body: responseBody1},
+						nil,
+					),
+					responseBody1.EXPECT().Close(),
+					mockHTTPClient.EXPECT().Do(util.HTTPRequestEq(newRequest())).Return(
+						&http.Response{StatusCode: 201, Body: responseBody2},
+						nil,
+					),
+					responseBody2.EXPECT().Close(),
+				)
+
+				retErr := testee.PostKiyoshi(context.Background(), kiyoshi)
 
 				Expect(retErr).To(BeNil())
 			})
+
+			It("gives up after the max retries.", func() {
+				err := fmt.Errorf("timeout")
+				gomock.InOrder(
+					mockHTTPClient.EXPECT().Do(util.HTTPRequestEq(req)).Return(nil, err),
+					mockHTTPClient.EXPECT().Do(util.HTTPRequestEq(newRequest())).Return(nil, err),
+					mockHTTPClient.EXPECT().Do(util.HTTPRequestEq(newRequest())).Return(nil, err),
+				)
+
+				retErr := testee.PostKiyoshi(context.Background(), kiyoshi)
+
+				Expect(errors.Unwrap(retErr)).To(Equal(err))
+			})
+
+			It("doesn't retry on non-transient error responses.", func() {
+				responseBody := mock_util.NewMockReadCloser(mockCtrl)
+				mockHTTPClient.EXPECT().Do(util.HTTPRequestEq(req)).Return(
+					&http.Response{StatusCode: 500, Status: "awful error", Body: responseBody},
+					nil,
+				)
+				responseBody.EXPECT().Close()
+
+				retErr := testee.PostKiyoshi(context.Background(), kiyoshi)
+
+				Expect(retErr.Error()).To(ContainSubstring("awful error"))
+			})
+		})
+	})
+
+	Describe("GetKiyoshies()", func() {
+		var (
+			req *http.Request
+		)
+
+		BeforeEach(func() {
+			req, _ = http.NewRequest("GET", "http://test/kiyoshies", nil)
+		})
+
+		It("returns an error if the response is not 200 ok.", func() {
+			responseBody := mock_util.NewMockReadCloser(mockCtrl)
+			mockHTTPClient.EXPECT().Do(util.HTTPRequestEq(req)).Return(
+				&http.Response{
+					StatusCode: 404,
+					Status:     "awful error",
+					Body:       responseBody,
+				},
+				nil,
+			)
+			responseBody.EXPECT().Close()
+
+			kiyoshies, retErr := testee.GetKiyoshies(context.Background())
+
+			Expect(kiyoshies).To(BeNil())
+			Expect(retErr.Error()).To(ContainSubstring("awful error"))
+		})
+
+		It("returns Kiyoshies if decoding succeeded.", func() {
+			responseBody := mock_util.NewMockReadCloser(mockCtrl)
+			mockHTTPClient.EXPECT().Do(util.HTTPRequestEq(req)).Return(
+				&http.Response{
+					StatusCode: 200,
+					Body:       responseBody,
+				},
+				nil,
+			)
+			expectedKiyoshies := []model.Kiyoshi{{Id: "k1", SaidAt: time.Now(), MadeBy: "kaitoy"}}
+			callDecodeList := mockKiyoshiDecoder.EXPECT().
+				DecodeList(gomock.Eq(responseBody)).
+				Return(expectedKiyoshies, nil)
+			responseBody.EXPECT().Close().After(callDecodeList)
+
+			kiyoshies, retErr := testee.GetKiyoshies(context.Background())
+
+			Expect(kiyoshies).To(Equal(expectedKiyoshies))
+			Expect(retErr).To(BeNil())
+		})
+
+		It("returns an error for an invalid response body in strict mode.", func() {
+			testee.(*client).strict = true
+			mockHTTPClient.EXPECT().Do(util.HTTPRequestEq(req)).Return(
+				&http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`[{"id": "k1", "saidAt": "2020-12-31T12:30:15Z", "madeBy": "kaitoy"}]`))},
+				nil,
+			)
+
+			kiyoshies, retErr := testee.GetKiyoshies(context.Background())
+
+			Expect(kiyoshies).To(BeNil())
+			var verr *openapi.ValidationError
+			Expect(errors.As(retErr, &verr)).To(BeTrue())
+			Expect(verr.Violations).To(HaveLen(2))
+			Expect(retErr.Error()).To(HavePrefix("GET Kiyoshi API returned an invalid response: 2 schema violations: [0].id: "))
+		})
+	})
+
+	Describe("formats", func() {
+		var (
+			zundokos  []model.Zundoko
+			kiyoshies []model.Kiyoshi
+		)
+
+		BeforeEach(func() {
+			zundokos = []model.Zundoko{
+				{
+					Id:       "91259080-1984-4a87-a671-f6adb641ef52",
+					Sequence: 1,
+					SaidAt:   time.Date(2020, 12, 31, 12, 30, 15, 123456789, time.UTC),
+					Word:     "Zun",
+				},
+				{
+					Id:     "c8b2f8b4-7f57-4f4a-9d2c-9a1b4b0e2f11",
+					SaidAt: time.Date(2020, 12, 31, 12, 30, 16, 0, time.UTC),
+					Word:   "Doko",
+				},
+			}
+			kiyoshies = []model.Kiyoshi{
+				{
+					Id:     "6c1f0c8e-5b0d-4a57-8a43-0d3b1e4f9a20",
+					SaidAt: time.Date(2020, 12, 31, 12, 30, 17, 987654321, time.UTC),
+					MadeBy: "kaitoy@example.com",
+				},
+			}
+		})
+
+		It("parses the names of the formats.", func() {
+			Expect(ParseFormat("json")).To(Equal(FormatJSON))
+			Expect(ParseFormat("msgpack")).To(Equal(FormatMessagePack))
+			Expect(ParseFormat("protobuf")).To(Equal(FormatProtobuf))
+			_, err := ParseFormat("xml")
+			Expect(err).To(MatchError("unknown format: xml"))
+		})
+
+		for _, format := range []Format{FormatJSON, FormatMessagePack, FormatProtobuf} {
+			format := format
+
+			Context("in "+string(format), func() {
+				var (
+					sentReqs []*http.Request
+					sentBody [][]byte
+				)
+
+				BeforeEach(func() {
+					sentReqs = nil
+					sentBody = nil
+					testee = &client{
+						urlBase:        "http://test",
+						httpClient:     mockHTTPClient,
+						zundokoDecoder: model.NewZundokoDecoder(),
+						kiyoshiDecoder: model.NewKiyoshiDecoder(),
+						limiter:        NewLimiter(nil),
+						format:         format,
+					}
+				})
+
+				// respond makes the API respond with the body encoded by encode in the format.
+				respond := func(status int, encode func() ([]byte, error)) {
+					mockHTTPClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+						sentReqs = append(sentReqs, req)
+						if req.Body != nil {
+							body, _ := ioutil.ReadAll(req.Body)
+							sentBody = append(sentBody, body)
+						}
+						body, err := encode()
+						Expect(err).To(BeNil())
+						return &http.Response{
+							StatusCode: status,
+							Header:     http.Header{"Content-Type": []string{string(format)}},
+							Body:       ioutil.NopCloser(strings.NewReader(string(body))),
+						}, nil
+					})
+				}
+
+				It("negotiates the format of GET Zundokos API and decodes identical Zundokos.", func() {
+					encoder, err := model.NewZundokoEncoderFor(string(format))
+					Expect(err).To(BeNil())
+					respond(200, func() ([]byte, error) { return encoder.EncodeList(zundokos) })
+
+					got, retErr := testee.GetZundokos(context.Background())
+
+					Expect(retErr).To(BeNil())
+					Expect(got).To(Equal(zundokos))
+					if format == FormatJSON {
+						Expect(sentReqs[0].Header.Get("Accept")).To(BeEmpty())
+					} else {
+						Expect(sentReqs[0].Header.Get("Accept")).To(Equal(string(format) + ", application/json;q=0.9"))
+					}
+				})
+
+				It("negotiates the format of GET Kiyoshies API and decodes identical Kiyoshies.", func() {
+					encoder, err := model.NewKiyoshiEncoderFor(string(format))
+					Expect(err).To(BeNil())
+					respond(200, func() ([]byte, error) { return encoder.EncodeList(kiyoshies) })
+
+					got, retErr := testee.GetKiyoshies(context.Background())
+
+					Expect(retErr).To(BeNil())
+					Expect(got).To(Equal(kiyoshies))
+				})
+
+				It("sends a Zundoko in the format and decodes the identical one from a 409 response.", func() {
+					encoder, err := model.NewZundokoEncoderFor(string(format))
+					Expect(err).To(BeNil())
+					respond(409, func() ([]byte, error) { return encoder.Encode(&zundokos[0]) })
+
+					retErr := testee.PostZundoko(context.Background(), &zundokos[0])
+
+					Expect(retErr).To(BeNil())
+					Expect(sentReqs[0].Header.Get("Content-type")).To(Equal(string(format)))
+					decoder, err := model.NewZundokoDecoderFor(string(format), 0)
+					Expect(err).To(BeNil())
+					sent, err := decoder.Decode(strings.NewReader(string(sentBody[0])))
+					Expect(err).To(BeNil())
+					Expect(*sent).To(Equal(zundokos[0]))
+				})
+
+				It("sends a Kiyoshi in the format and decodes the identical one from a 409 response.", func() {
+					encoder, err := model.NewKiyoshiEncoderFor(string(format))
+					Expect(err).To(BeNil())
+					respond(409, func() ([]byte, error) { return encoder.Encode(&kiyoshies[0]) })
+
+					retErr := testee.PostKiyoshi(context.Background(), &kiyoshies[0])
+
+					Expect(retErr).To(BeNil())
+					Expect(sentReqs[0].Header.Get("Content-type")).To(Equal(string(format)))
+					decoder, err := model.NewKiyoshiDecoderFor(string(format), 0)
+					Expect(err).To(BeNil())
+					sent, err := decoder.Decode(strings.NewReader(string(sentBody[0])))
+					Expect(err).To(BeNil())
+					Expect(*sent).To(Equal(kiyoshies[0]))
+				})
+
+				It("limits the size of a response body.", func() {
+					testee.(*client).maxBodySize = 10
+					testee.(*client).zundokoDecoder = model.NewZundokoDecoderWithMaxBodySize(10)
+					encoder, err := model.NewZundokoEncoderFor(string(format))
+					Expect(err).To(BeNil())
+					respond(200, func() ([]byte, error) { return encoder.EncodeList(zundokos) })
+
+					got, retErr := testee.GetZundokos(context.Background())
+
+					Expect(got).To(BeNil())
+					var maxBytesErr *http.MaxBytesError
+					Expect(errors.As(retErr, &maxBytesErr)).To(BeTrue())
+				})
+			})
+		}
+
+		It("returns an error for a response in an unsupported content type.", func() {
+			mockHTTPClient.EXPECT().Do(gomock.Any()).Return(
+				&http.Response{
+					StatusCode: 200,
+					Header:     http.Header{"Content-Type": []string{"application/xml"}},
+					Body:       ioutil.NopCloser(strings.NewReader("<zundokos/>")),
+				},
+				nil,
+			)
+
+			got, retErr := testee.GetZundokos(context.Background())
+
+			Expect(got).To(BeNil())
+			Expect(retErr).To(MatchError(`GET Zundoko API returned an invalid response: unsupported content type "application/xml" for Zundoko`))
 		})
 	})
 })
@@ -0,0 +1,41 @@
+package client
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DateReporter is implemented by Clients that report the Date header of responses from Zundoko Server,
+// which lets callers detect clock skew between the client and the server.
+type DateReporter interface {
+	// ServerDate returns the Date header of the last response that had one, and the local time it was received.
+	// ok is false if no response has had it.
+	ServerDate() (date, receivedAt time.Time, ok bool)
+}
+
+// dateRecorder records the Date header of the last response.
+type dateRecorder struct {
+	mu         sync.Mutex
+	date       time.Time
+	receivedAt time.Time
+}
+
+// record records the Date header of the response if any.
+func (d *dateRecorder) record(resp *http.Response, receivedAt time.Time) {
+	date, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.date = date
+	d.receivedAt = receivedAt
+}
+
+func (d *dateRecorder) get() (date, receivedAt time.Time, ok bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.date, d.receivedAt, !d.receivedAt.IsZero()
+}
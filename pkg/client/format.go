@@ -0,0 +1,87 @@
+package client
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/model"
+)
+
+// Format is a wire format of request and response bodies, identified by its media type.
+type Format string
+
+const (
+	// FormatJSON is JSON, the default format.
+	FormatJSON Format = "application/json"
+
+	// FormatMessagePack is MessagePack.
+	FormatMessagePack Format = "application/msgpack"
+
+	// FormatProtobuf is Protocol Buffers.
+	FormatProtobuf Format = "application/x-protobuf"
+)
+
+// ParseFormat returns the Format of the name: json, msgpack, or protobuf.
+func ParseFormat(name string) (Format, error) {
+	switch name {
+	case "json":
+		return FormatJSON, nil
+	case "msgpack":
+		return FormatMessagePack, nil
+	case "protobuf":
+		return FormatProtobuf, nil
+	default:
+		return "", fmt.Errorf("unknown format: %s", name)
+	}
+}
+
+// WithFormat makes a Client send request bodies in the format and ask for response bodies in it,
+// falling back to JSON. Response bodies are decoded by their Content-Type regardless of the format.
+func WithFormat(format Format) Option {
+	return func(c *client) {
+		c.format = format
+	}
+}
+
+// contentType returns the media type of request bodies, which is JSON unless WithFormat is given.
+func (c *client) contentType() string {
+	if c.format == "" {
+		return string(FormatJSON)
+	}
+	return string(c.format)
+}
+
+// accept adds the Accept header to the request to negotiate the format. It adds nothing for JSON,
+// which Zundoko Server responds in by default.
+func (c *client) accept(req *http.Request) {
+	if c.contentType() != string(FormatJSON) {
+		req.Header.Add("Accept", c.contentType()+", "+string(FormatJSON)+";q=0.9")
+	}
+}
+
+// isJSON returns true if the response body is in JSON, which is assumed if the Content-Type header is absent.
+func isJSON(resp *http.Response) bool {
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == string(FormatJSON)
+}
+
+// zundokoDecoderFor returns a decoder for the Content-Type of the response.
+func (c *client) zundokoDecoderFor(resp *http.Response) (model.ZundokoDecoder, error) {
+	if isJSON(resp) {
+		return c.zundokoDecoder, nil
+	}
+	return model.NewZundokoDecoderFor(resp.Header.Get("Content-Type"), c.maxBodySize)
+}
+
+// kiyoshiDecoderFor returns a decoder for the Content-Type of the response.
+func (c *client) kiyoshiDecoderFor(resp *http.Response) (model.KiyoshiDecoder, error) {
+	if isJSON(resp) {
+		return c.kiyoshiDecoder, nil
+	}
+	return model.NewKiyoshiDecoderFor(resp.Header.Get("Content-Type"), c.maxBodySize)
+}
@@ -0,0 +1,193 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Operation represents an API operation of Client.
+type Operation string
+
+// List of Operation
+const (
+	OperationGetZundokos  Operation = "GetZundokos"
+	OperationPostZundoko  Operation = "PostZundoko"
+	OperationPostKiyoshi  Operation = "PostKiyoshi"
+	OperationGetKiyoshies Operation = "GetKiyoshies"
+
+	// OperationAll represents all the operations. A limit for it is shared among them.
+	OperationAll Operation = "*"
+)
+
+// Limit represents limits on calls of an operation.
+type Limit struct {
+	// Rate is the number of calls allowed per second by a token bucket. Zero means no limit.
+	Rate float64
+
+	// Burst is the size of the token bucket. It's treated as 1 if less than 1.
+	Burst int
+
+	// MaxInFlight is the maximum number of concurrent calls. Zero means no limit.
+	MaxInFlight int
+}
+
+// Limits maps operations to their limits.
+type Limits map[Operation]Limit
+
+// WaitStats represents statistics of time callers waited for limits.
+type WaitStats struct {
+	// Calls is the number of calls that passed the limits.
+	Calls int `json:"calls"`
+
+	// Total is the total time the calls waited.
+	Total time.Duration `json:"total"`
+
+	// Max is the longest time a call waited.
+	Max time.Duration `json:"max"`
+}
+
+// Mean returns the average time the calls waited.
+func (s WaitStats) Mean() time.Duration {
+	if s.Calls == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Calls)
+}
+
+// Limiter limits calls of API operations so that callers sharing a Client can't overwhelm the server.
+type Limiter interface {
+	// Acquire blocks until a call of the operation is allowed or the given context is done.
+	// If it's allowed, it returns a func that must be called when the call finishes.
+	Acquire(ctx context.Context, operation Operation) (release func(), err error)
+
+	// Stats returns statistics of time callers waited in Acquire for each operation.
+	Stats() map[Operation]WaitStats
+}
+
+// NewLimiter creates a Limiter instance with the given limits.
+// A call of an operation waits for both the limit of the operation and the one of OperationAll.
+func NewLimiter(limits Limits) Limiter {
+	l := &limiter{
+		gates: make(map[Operation]*gate),
+		stats: make(map[Operation]WaitStats),
+	}
+	for op, limit := range limits {
+		l.gates[op] = newGate(limit)
+	}
+	return l
+}
+
+type limiter struct {
+	gates map[Operation]*gate
+	mu    sync.Mutex
+	stats map[Operation]WaitStats
+}
+
+func (l *limiter) Acquire(ctx context.Context, operation Operation) (func(), error) {
+	start := time.Now()
+
+	var gates []*gate
+	for _, op := range []Operation{operation, OperationAll} {
+		if g, ok := l.gates[op]; ok {
+			gates = append(gates, g)
+		}
+	}
+
+	for _, g := range gates {
+		if err := g.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	for i, g := range gates {
+		if err := g.enter(ctx); err != nil {
+			for _, entered := range gates[:i] {
+				entered.leave()
+			}
+			return nil, err
+		}
+	}
+
+	l.record(operation, time.Since(start))
+
+	return func() {
+		for _, g := range gates {
+			g.leave()
+		}
+	}, nil
+}
+
+func (l *limiter) record(operation Operation, waited time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stats := l.stats[operation]
+	stats.Calls++
+	stats.Total += waited
+	if waited > stats.Max {
+		stats.Max = waited
+	}
+	l.stats[operation] = stats
+}
+
+func (l *limiter) Stats() map[Operation]WaitStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stats := make(map[Operation]WaitStats, len(l.stats))
+	for op, s := range l.stats {
+		stats[op] = s
+	}
+	return stats
+}
+
+// gate applies a Limit with a token bucket and a semaphore.
+type gate struct {
+	bucket   *rate.Limiter
+	inFlight chan struct{}
+}
+
+func newGate(limit Limit) *gate {
+	g := &gate{}
+	if limit.Rate > 0 {
+		burst := limit.Burst
+		if burst < 1 {
+			burst = 1
+		}
+		g.bucket = rate.NewLimiter(rate.Limit(limit.Rate), burst)
+	}
+	if limit.MaxInFlight > 0 {
+		g.inFlight = make(chan struct{}, limit.MaxInFlight)
+	}
+	return g
+}
+
+// wait blocks until a token is available in the bucket.
+func (g *gate) wait(ctx context.Context) error {
+	if g.bucket == nil {
+		return ctx.Err()
+	}
+	return g.bucket.Wait(ctx)
+}
+
+// enter blocks until the number of calls in flight gets below the limit.
+func (g *gate) enter(ctx context.Context) error {
+	if g.inFlight == nil {
+		return nil
+	}
+	select {
+	case g.inFlight <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// leave tells a call in flight finished.
+func (g *gate) leave() {
+	if g.inFlight != nil {
+		<-g.inFlight
+	}
+}
@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Limiter", func() {
+	Describe("Acquire()", func() {
+		It("doesn't block if no limit is configured for the operation.", func() {
+			testee := NewLimiter(Limits{OperationPostKiyoshi: {MaxInFlight: 1}})
+
+			for i := 0; i < 3; i++ {
+				release, err := testee.Acquire(context.Background(), OperationGetZundokos)
+				Expect(err).To(BeNil())
+				Expect(release).NotTo(BeNil())
+			}
+		})
+
+		It("makes calls wait for tokens of the bucket.", func() {
+			testee := NewLimiter(Limits{OperationGetZundokos: {Rate: 100, Burst: 2}})
+
+			start := time.Now()
+			for i := 0; i < 6; i++ {
+				release, err := testee.Acquire(context.Background(), OperationGetZundokos)
+				Expect(err).To(BeNil())
+				release()
+			}
+
+			Expect(time.Since(start)).To(BeNumerically(">=", 35*time.Millisecond))
+		})
+
+		It("blocks calls while the maximum calls are in flight.", func() {
+			testee := NewLimiter(Limits{OperationPostZundoko: {MaxInFlight: 2}})
+			release1, _ := testee.Acquire(context.Background(), OperationPostZundoko)
+			_, _ = testee.Acquire(context.Background(), OperationPostZundoko)
+
+			acquired := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				release, err := testee.Acquire(context.Background(), OperationPostZundoko)
+				Expect(err).To(BeNil())
+				release()
+				close(acquired)
+			}()
+
+			Consistently(acquired, 30*time.Millisecond).ShouldNot(BeClosed())
+			release1()
+			Eventually(acquired).Should(BeClosed())
+		})
+
+		It("applies the limit of OperationAll to all the operations.", func() {
+			testee := NewLimiter(Limits{OperationAll: {MaxInFlight: 1}})
+			release, _ := testee.Acquire(context.Background(), OperationGetZundokos)
+			defer release()
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+
+			_, err := testee.Acquire(ctx, OperationPostKiyoshi)
+
+			Expect(err).To(Equal(context.DeadlineExceeded))
+		})
+
+		It("returns an error and releases acquired slots if the context is done while waiting.", func() {
+			testee := NewLimiter(Limits{
+				OperationGetZundokos: {MaxInFlight: 1},
+				OperationAll:         {MaxInFlight: 1},
+			})
+			release, _ := testee.Acquire(context.Background(), OperationPostKiyoshi)
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+
+			_, err := testee.Acquire(ctx, OperationGetZundokos)
+
+			Expect(err).To(Equal(context.DeadlineExceeded))
+			release()
+			release, err = testee.Acquire(context.Background(), OperationGetZundokos)
+			Expect(err).To(BeNil())
+			release()
+		})
+	})
+
+	Describe("Stats()", func() {
+		It("returns statistics of time callers waited for each operation.", func() {
+			testee := NewLimiter(Limits{OperationPostZundoko: {MaxInFlight: 1}})
+			release, _ := testee.Acquire(context.Background(), OperationPostZundoko)
+
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				release, _ := testee.Acquire(context.Background(), OperationPostZundoko)
+				release()
+			}()
+			time.Sleep(20 * time.Millisecond)
+			release()
+			wg.Wait()
+			release, _ = testee.Acquire(context.Background(), OperationGetZundokos)
+			release()
+
+			stats := testee.Stats()
+
+			Expect(stats).To(HaveLen(2))
+			Expect(stats[OperationPostZundoko].Calls).To(Equal(2))
+			Expect(stats[OperationPostZundoko].Max).To(BeNumerically(">=", 20*time.Millisecond))
+			Expect(stats[OperationPostZundoko].Total).To(BeNumerically(">=", stats[OperationPostZundoko].Max))
+			Expect(stats[OperationPostZundoko].Mean()).To(Equal(stats[OperationPostZundoko].Total / 2))
+			Expect(stats[OperationGetZundokos].Calls).To(Equal(1))
+		})
+	})
+})
@@ -0,0 +1,287 @@
+package conformance
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/runner"
+	"github.com/kaitoy/zundoko-go-client/pkg/util"
+)
+
+const (
+	zundokosPath  = "/zundokos"
+	kiyoshiesPath = "/kiyoshies"
+)
+
+// entity has the properties of both Zundoko and Kiyoshi, where Word is empty for a Kiyoshi.
+type entity struct {
+	Id       string    `json:"id"`
+	Sequence int64     `json:"sequence,omitempty"`
+	SaidAt   time.Time `json:"saidAt"`
+	Word     string    `json:"word,omitempty"`
+}
+
+// newEntity creates an entity to post with a new id, which is a Kiyoshi if word is empty.
+func newEntity(word string) *entity {
+	return &entity{
+		Id:     util.NewUUID().String(),
+		SaidAt: time.Now().UTC().Truncate(time.Millisecond),
+		Word:   word,
+	}
+}
+
+// list gets the entities at the path. It records failures and returns false if the response doesn't conform.
+func (t *tester) list(path string) ([]entity, bool) {
+	resp := t.call("GET", path, nil, "")
+	if !t.conforms("GET", path, resp, 200) {
+		return nil, false
+	}
+	var entities []entity
+	if err := json.Unmarshal(resp.body, &entities); err != nil {
+		t.errorf("GET %s responded an undecodable body: %s", path, err)
+		return nil, false
+	}
+	return entities, true
+}
+
+// create posts the entity to the path. It records failures and returns false if the response doesn't conform.
+func (t *tester) create(path string, e *entity) bool {
+	return t.conforms("POST", path, t.call("POST", path, e, e.Id), 201)
+}
+
+// find returns the entities with the id.
+func find(entities []entity, id string) []entity {
+	var found []entity
+	for _, e := range entities {
+		if e.Id == id {
+			found = append(found, e)
+		}
+	}
+	return found
+}
+
+func testGetZundokos(t *tester) {
+	t.list(zundokosPath)
+}
+
+func testGetKiyoshies(t *tester) {
+	t.list(kiyoshiesPath)
+}
+
+func testPostZundoko(t *tester) {
+	testCreate(t, zundokosPath, newEntity("Zun"))
+}
+
+func testPostKiyoshi(t *tester) {
+	if !t.completePattern() {
+		return
+	}
+	testCreate(t, kiyoshiesPath, newEntity(""))
+}
+
+func testPostZundokoIdempotency(t *tester) {
+	testIdempotency(t, zundokosPath, newEntity("Doko"), nil)
+}
+
+// testPostKiyoshiIdempotency completes a pattern before each post, so that the reused idempotency key is
+// responded 409 for itself, not for a duplicate Kiyoshi of the pattern.
+func testPostKiyoshiIdempotency(t *tester) {
+	testIdempotency(t, kiyoshiesPath, newEntity(""), (*tester).completePattern)
+}
+
+func testPostKiyoshiDuplicate(t *tester) {
+	if !t.completePattern() {
+		return
+	}
+	first := newEntity("")
+	if !t.create(kiyoshiesPath, first) {
+		return
+	}
+	duplicate := newEntity("")
+	resp := t.call("POST", kiyoshiesPath, duplicate, duplicate.Id)
+	if !t.conforms("POST", kiyoshiesPath, resp, 409) {
+		return
+	}
+	var existing entity
+	if err := json.Unmarshal(resp.body, &existing); err != nil {
+		t.errorf("POST %s responded 409 with an undecodable body: %s", kiyoshiesPath, err)
+		return
+	}
+	if existing.Id != first.Id {
+		t.errorf("POST %s responded 409 with Kiyoshi %s instead of %s made for the pattern", kiyoshiesPath, existing.Id, first.Id)
+	}
+
+	entities, ok := t.list(kiyoshiesPath)
+	if !ok {
+		return
+	}
+	if len(find(entities, duplicate.Id)) > 0 {
+		t.errorf("GET %s returned the duplicate Kiyoshi %s", kiyoshiesPath, duplicate.Id)
+	}
+}
+
+// completePattern posts Zundokos making the pattern, for which a Kiyoshi can be posted next.
+// It records failures and returns false if the responses don't conform.
+func (t *tester) completePattern() bool {
+	for i, word := range runner.Pattern() {
+		e := newEntity(word)
+		e.SaidAt = e.SaidAt.Add(time.Duration(i) * time.Millisecond)
+		if !t.create(zundokosPath, e) {
+			return false
+		}
+	}
+	return true
+}
+
+// testCreate tests the entity posted to the path is got from there as it is.
+func testCreate(t *tester, path string, e *entity) {
+	if !t.create(path, e) {
+		return
+	}
+	entities, ok := t.list(path)
+	if !ok {
+		return
+	}
+	found := find(entities, e.Id)
+	if len(found) != 1 {
+		t.errorf("GET %s returned %d entities with the posted id %s instead of 1", path, len(found), e.Id)
+		return
+	}
+	if found[0].Word != e.Word {
+		t.errorf("GET %s returned the posted entity %s with word %q instead of %q", path, e.Id, found[0].Word, e.Word)
+	}
+	if !found[0].SaidAt.Equal(e.SaidAt) {
+		t.errorf("GET %s returned the posted entity %s with saidAt %s instead of %s",
+			path, e.Id, found[0].SaidAt.Format(time.RFC3339Nano), e.SaidAt.Format(time.RFC3339Nano))
+	}
+}
+
+// testIdempotency tests the entity posted twice to the path with the same idempotency key is created just once,
+// and the second post is responded 409 with the created one. prepare is called before each post unless nil.
+func testIdempotency(t *tester, path string, e *entity, prepare func(t *tester) bool) {
+	if prepare != nil && !prepare(t) {
+		return
+	}
+	if !t.create(path, e) {
+		return
+	}
+	if prepare != nil && !prepare(t) {
+		return
+	}
+	resp := t.call("POST", path, e, e.Id)
+	if !t.conforms("POST", path, resp, 409) {
+		return
+	}
+	var existing entity
+	if err := json.Unmarshal(resp.body, &existing); err != nil {
+		t.errorf("POST %s responded 409 with an undecodable body: %s", path, err)
+		return
+	}
+	if existing.Id != e.Id || existing.Word != e.Word {
+		t.errorf("POST %s responded 409 with entity %s %q instead of the posted %s %q", path, existing.Id, existing.Word, e.Id, e.Word)
+	}
+
+	entities, ok := t.list(path)
+	if !ok {
+		return
+	}
+	if found := find(entities, e.Id); len(found) != 1 {
+		t.errorf("GET %s returned %d entities with the id %s posted twice instead of 1", path, len(found), e.Id)
+	}
+}
+
+func testPostZundokoConflict(t *tester) {
+	e := newEntity("Zun")
+	if !t.create(zundokosPath, e) {
+		return
+	}
+	another := *e
+	another.Word = "Doko"
+	resp := t.call("POST", zundokosPath, &another, another.Id)
+	if !t.conforms("POST", zundokosPath, resp, 409) {
+		return
+	}
+	var existing entity
+	if err := json.Unmarshal(resp.body, &existing); err != nil {
+		t.errorf("POST %s responded 409 with an undecodable body: %s", zundokosPath, err)
+		return
+	}
+	if existing.Word != e.Word {
+		t.errorf("POST %s responded 409 with word %q instead of the existing %q", zundokosPath, existing.Word, e.Word)
+	}
+
+	entities, ok := t.list(zundokosPath)
+	if !ok {
+		return
+	}
+	for _, found := range find(entities, e.Id) {
+		if found.Word != e.Word {
+			t.errorf("GET %s returned Zundoko %s with word %q overwritten by a reused idempotency key", zundokosPath, e.Id, found.Word)
+		}
+	}
+}
+
+func testPostUnknownWord(t *tester) {
+	e := newEntity("Don")
+	resp := t.call("POST", zundokosPath, e, e.Id)
+	if resp == nil {
+		return
+	}
+	if resp.status < 400 || resp.status >= 500 {
+		t.errorf("POST %s responded %d instead of 4xx for word %q not in the enum", zundokosPath, resp.status, e.Word)
+	}
+
+	entities, ok := t.list(zundokosPath)
+	if !ok {
+		return
+	}
+	if len(find(entities, e.Id)) > 0 {
+		t.errorf("GET %s returned Zundoko %s with word %q not in the enum", zundokosPath, e.Id, e.Word)
+	}
+}
+
+func testZundokoOrder(t *tester) {
+	posted := []*entity{newEntity("Zun"), newEntity("Zun"), newEntity("Doko")}
+	for i, e := range posted {
+		e.SaidAt = e.SaidAt.Add(time.Duration(i) * time.Millisecond)
+		if !t.create(zundokosPath, e) {
+			return
+		}
+	}
+
+	entities, ok := t.list(zundokosPath)
+	if !ok {
+		return
+	}
+	last := -1
+	for _, e := range posted {
+		index := -1
+		for i := range entities {
+			if entities[i].Id == e.Id {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			t.errorf("GET %s didn't return the posted Zundoko %s", zundokosPath, e.Id)
+			return
+		}
+		if index < last {
+			t.errorf("GET %s returned Zundoko %s before %s posted before it", zundokosPath, e.Id, entities[last].Id)
+		}
+		last = index
+	}
+
+	var previous *entity
+	for i := range entities {
+		e := &entities[i]
+		if e.Sequence == 0 {
+			continue
+		}
+		if previous != nil && e.Sequence <= previous.Sequence {
+			t.errorf("GET %s returned Zundoko %s of sequence %d after %s of sequence %d",
+				zundokosPath, e.Id, e.Sequence, previous.Id, previous.Sequence)
+		}
+		previous = e
+	}
+}
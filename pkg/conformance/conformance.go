@@ -0,0 +1,219 @@
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/openapi"
+	"github.com/kaitoy/zundoko-go-client/pkg/util"
+)
+
+// Config represents configuration of a conformance test.
+type Config struct {
+	// Server is the URL of the Zundoko Server to test.
+	Server string
+
+	// HTTPClient sends the requests of the test. Defaults to an http.Client with a timeout of 10 seconds.
+	HTTPClient util.HTTPClient
+}
+
+// Result represents the result of a test case.
+type Result struct {
+	// Operation is the id of the operation the test case is about in the OpenAPI spec.
+	Operation string `json:"operation"`
+
+	// Name describes the test case.
+	Name string `json:"name"`
+
+	// Elapsed is the time the test case took.
+	Elapsed time.Duration `json:"elapsed"`
+
+	// Failures is the failures found by the test case. It's empty if the test case passed.
+	Failures []string `json:"failures,omitempty"`
+}
+
+// Passed tells if the test case passed.
+func (r Result) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// Report represents the result of a conformance test.
+type Report struct {
+	// Server is the URL of the tested Zundoko Server.
+	Server string `json:"server"`
+
+	// StartedAt is the time the test started.
+	StartedAt time.Time `json:"startedAt"`
+
+	// Elapsed is the time the test took.
+	Elapsed time.Duration `json:"elapsed"`
+
+	// Results is the results of the test cases in order.
+	Results []Result `json:"results"`
+}
+
+// Failed returns the number of the failed test cases.
+func (r *Report) Failed() int {
+	failed := 0
+	for _, result := range r.Results {
+		if !result.Passed() {
+			failed++
+		}
+	}
+	return failed
+}
+
+// jsonMediaType is the media type of JSON, which the test requests and expects responses in.
+const jsonMediaType = "application/json"
+
+// testCase is a test case of the conformance test.
+type testCase struct {
+	operation string
+	name      string
+	run       func(t *tester)
+}
+
+// testCases is the test cases of the conformance test in order.
+var testCases = []testCase{
+	{"getZundokos", "GET /zundokos responds Zundokos", testGetZundokos},
+	{"getZundokos", "GET /zundokos returns Zundokos in the order they were posted", testZundokoOrder},
+	{"postZundoko", "POST /zundokos creates a Zundoko", testPostZundoko},
+	{"postZundoko", "POST /zundokos is idempotent", testPostZundokoIdempotency},
+	{"postZundoko", "POST /zundokos responds the existing Zundoko for a reused idempotency key", testPostZundokoConflict},
+	{"postZundoko", "POST /zundokos rejects an unknown word", testPostUnknownWord},
+	{"getKiyoshies", "GET /kiyoshies responds Kiyoshies", testGetKiyoshies},
+	{"postKiyoshi", "POST /kiyoshies creates a Kiyoshi", testPostKiyoshi},
+	{"postKiyoshi", "POST /kiyoshies is idempotent", testPostKiyoshiIdempotency},
+	{"postKiyoshi", "POST /kiyoshies responds the existing Kiyoshi for a duplicate of the pattern", testPostKiyoshiDuplicate},
+}
+
+// Run runs the conformance test against the Zundoko Server, checking its responses against swagger/swagger.yaml:
+// status codes, content types, schemas including enums and formats, and ordering and idempotency behaviors.
+// Note the Zundokos and Kiyoshies posted by the test remain on the server.
+// It returns an error only if the test can't be run.
+func Run(ctx context.Context, config Config) (*Report, error) {
+	spec, err := openapi.ZundokoSpec()
+	if err != nil {
+		return nil, err
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	report := &Report{Server: config.Server, StartedAt: time.Now()}
+	for _, c := range testCases {
+		t := &tester{ctx: ctx, config: config, spec: spec}
+		start := time.Now()
+		c.run(t)
+		report.Results = append(report.Results, Result{
+			Operation: c.operation,
+			Name:      c.name,
+			Elapsed:   time.Since(start),
+			Failures:  t.failures,
+		})
+	}
+	report.Elapsed = time.Since(report.StartedAt)
+	return report, nil
+}
+
+// tester runs a test case, recording its failures.
+type tester struct {
+	ctx      context.Context
+	config   Config
+	spec     *openapi.Spec
+	failures []string
+}
+
+// response is a response of Zundoko Server with the body read.
+type response struct {
+	status      int
+	contentType string
+	body        []byte
+}
+
+// errorf records a failure.
+func (t *tester) errorf(format string, args ...interface{}) {
+	t.failures = append(t.failures, fmt.Sprintf(format, args...))
+}
+
+// call sends a request of the method to the path with the entity in JSON as the body if not nil,
+// and the id of it as the idempotency key if not empty. It records a failure and returns nil if the request failed.
+func (t *tester) call(method, path string, entity interface{}, id string) *response {
+	var body io.Reader
+	if entity != nil {
+		b, _ := json.Marshal(entity)
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(t.ctx, method, t.config.Server+path, body)
+	if err != nil {
+		t.errorf("%s %s: %s", method, path, err)
+		return nil
+	}
+	req.Header.Set("Accept", jsonMediaType)
+	if entity != nil {
+		req.Header.Set("Content-Type", jsonMediaType)
+	}
+	if id != "" {
+		req.Header.Set("Idempotency-Key", id)
+	}
+
+	resp, err := t.config.HTTPClient.Do(req)
+	if err != nil {
+		t.errorf("%s %s failed: %s", method, path, err)
+		return nil
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.errorf("%s %s: failed to read the response body: %s", method, path, err)
+		return nil
+	}
+	return &response{status: resp.StatusCode, contentType: resp.Header.Get("Content-Type"), body: b}
+}
+
+// conforms checks the response of the method and path has the status, and its content type and body
+// conform to the OpenAPI spec. It records failures and returns false if it doesn't conform.
+func (t *tester) conforms(method, path string, resp *response, status int) bool {
+	if resp == nil {
+		return false
+	}
+	if resp.status != status {
+		t.errorf("%s %s responded %d instead of %d: %s", method, path, resp.status, status, truncate(resp.body))
+		return false
+	}
+
+	expected := t.spec.Operation(method, path).Response(status)
+	if expected == nil || len(expected.Content) == 0 {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(resp.contentType)
+	if err != nil {
+		t.errorf("%s %s responded %d with an invalid Content-Type %q", method, path, status, resp.contentType)
+		return false
+	}
+	content, ok := expected.Content[jsonMediaType]
+	if !ok || mediaType != jsonMediaType {
+		t.errorf("%s %s responded %d with Content-Type %q instead of %s", method, path, status, resp.contentType, jsonMediaType)
+		return false
+	}
+	if err := content.Schema.ValidateJSON(resp.body); err != nil {
+		t.errorf("%s %s responded %d with a body violating the schema: %s", method, path, status, err)
+		return false
+	}
+	return true
+}
+
+// truncate returns the body as a string for messages, truncated if long.
+func truncate(body []byte) string {
+	const max = 200
+	if len(body) > max {
+		return string(body[:max]) + "..."
+	}
+	return string(body)
+}
@@ -0,0 +1,181 @@
+package conformance
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// fakeServer is a Zundoko Server conforming to the spec unless broken by its fields.
+type fakeServer struct {
+	// textPlain makes GET APIs respond in text/plain.
+	textPlain bool
+
+	// acceptAnyWord makes POST /zundokos accept words not in the enum.
+	acceptAnyWord bool
+
+	// notIdempotent makes POST APIs create another entity for a reused idempotency key.
+	notIdempotent bool
+
+	// reversed makes GET APIs return entities in the reverse order.
+	reversed bool
+
+	// acceptDuplicates makes POST /kiyoshies create another Kiyoshi for the same pattern.
+	acceptDuplicates bool
+
+	mu       sync.Mutex
+	entities map[string][]map[string]interface{}
+
+	// claimed is the Kiyoshi created since the last Zundoko, if any.
+	claimed map[string]interface{}
+}
+
+func (s *fakeServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if req.Method == "GET" {
+		entities := append([]map[string]interface{}{}, s.entities[req.URL.Path]...)
+		if s.reversed {
+			for i, j := 0, len(entities)-1; i < j; i, j = i+1, j-1 {
+				entities[i], entities[j] = entities[j], entities[i]
+			}
+		}
+		if s.textPlain {
+			w.Header().Set("Content-Type", "text/plain")
+		} else {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		}
+		json.NewEncoder(w).Encode(entities)
+		return
+	}
+
+	var e map[string]interface{}
+	json.NewDecoder(req.Body).Decode(&e)
+	if word, ok := e["word"]; ok && word != "Zun" && word != "Doko" && !s.acceptAnyWord {
+		w.WriteHeader(400)
+		return
+	}
+	if !s.notIdempotent {
+		for _, existing := range s.entities[req.URL.Path] {
+			if existing["id"] == req.Header.Get("Idempotency-Key") {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(409)
+				json.NewEncoder(w).Encode(existing)
+				return
+			}
+		}
+	}
+	switch {
+	case req.URL.Path == zundokosPath:
+		e["sequence"] = len(s.entities[req.URL.Path]) + 1
+		s.claimed = nil
+	case s.claimed != nil && !s.acceptDuplicates:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(409)
+		json.NewEncoder(w).Encode(s.claimed)
+		return
+	default:
+		s.claimed = e
+	}
+	if s.entities == nil {
+		s.entities = make(map[string][]map[string]interface{})
+	}
+	s.entities[req.URL.Path] = append(s.entities[req.URL.Path], e)
+	w.WriteHeader(201)
+}
+
+var _ = Describe("Run()", func() {
+	var (
+		server *fakeServer
+		ts     *httptest.Server
+	)
+
+	BeforeEach(func() {
+		server = &fakeServer{}
+		ts = httptest.NewServer(server)
+	})
+
+	AfterEach(func() {
+		ts.Close()
+	})
+
+	// failures runs the test and returns the failures by the names of the test cases.
+	failures := func() map[string][]string {
+		report, err := Run(context.Background(), Config{Server: ts.URL})
+		Expect(err).To(BeNil())
+		Expect(report.Results).To(HaveLen(len(testCases)))
+		failures := make(map[string][]string)
+		for _, result := range report.Results {
+			if !result.Passed() {
+				failures[result.Name] = result.Failures
+			}
+		}
+		return failures
+	}
+
+	It("passes a conforming server.", func() {
+		Expect(failures()).To(BeEmpty())
+	})
+
+	It("checks the content types.", func() {
+		server.textPlain = true
+
+		f := failures()
+
+		Expect(f["GET /kiyoshies responds Kiyoshies"]).To(ConsistOf(`GET /kiyoshies responded 200 with Content-Type "text/plain" instead of application/json`))
+		Expect(f).To(HaveLen(len(testCases)))
+	})
+
+	It("checks the enum of words.", func() {
+		server.acceptAnyWord = true
+
+		f := failures()
+
+		Expect(f).To(HaveLen(1))
+		Expect(f["POST /zundokos rejects an unknown word"]).To(ConsistOf(
+			`POST /zundokos responded 201 instead of 4xx for word "Don" not in the enum`,
+			`GET /zundokos responded 200 with a body violating the schema: 1 schema violations: [6].word: "Don" is not one of ["Zun", "Doko"]`,
+		))
+	})
+
+	It("checks the idempotency.", func() {
+		server.notIdempotent = true
+
+		f := failures()
+
+		Expect(f).To(HaveLen(3))
+		Expect(f["POST /zundokos is idempotent"]).To(ConsistOf(ContainSubstring("responded 201 instead of 409")))
+		Expect(f).To(HaveKey("POST /zundokos responds the existing Zundoko for a reused idempotency key"))
+		Expect(f).To(HaveKey("POST /kiyoshies is idempotent"))
+	})
+
+	It("checks duplicate Kiyoshies.", func() {
+		server.acceptDuplicates = true
+
+		f := failures()
+
+		Expect(f).To(HaveLen(1))
+		Expect(f["POST /kiyoshies responds the existing Kiyoshi for a duplicate of the pattern"]).To(ConsistOf(ContainSubstring("responded 201 instead of 409")))
+	})
+
+	It("checks the order.", func() {
+		server.reversed = true
+
+		f := failures()
+
+		Expect(f).To(HaveLen(1))
+		Expect(f["GET /zundokos returns Zundokos in the order they were posted"]).To(ContainElement(ContainSubstring("of sequence 2 after")))
+	})
+
+	It("fails every test case for an unreachable server.", func() {
+		ts.Close()
+
+		Expect(failures()).To(HaveLen(len(testCases)))
+	})
+})
@@ -0,0 +1,2 @@
+// Package conformance provides a test suite that checks a Zundoko Server implementation against the OpenAPI spec.
+package conformance
@@ -0,0 +1,121 @@
+package conformance
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report in JUnit XML, where each operation is a test suite.
+func (r *Report) WriteJUnit(w io.Writer) error {
+	suites := junitTestSuites{
+		Name:     "Zundoko Server conformance: " + r.Server,
+		Tests:    len(r.Results),
+		Failures: r.Failed(),
+		Time:     junitTime(r.Elapsed),
+	}
+	indexes := make(map[string]int)
+	for _, result := range r.Results {
+		i, ok := indexes[result.Operation]
+		if !ok {
+			i = len(suites.Suites)
+			indexes[result.Operation] = i
+			suites.Suites = append(suites.Suites, junitTestSuite{
+				Name:      result.Operation,
+				Timestamp: r.StartedAt.Format("2006-01-02T15:04:05"),
+			})
+		}
+		suite := &suites.Suites[i]
+
+		c := junitTestCase{Name: result.Name, Classname: "conformance." + result.Operation, Time: junitTime(result.Elapsed)}
+		if !result.Passed() {
+			c.Failure = &junitFailure{Message: result.Failures[0], Text: strings.Join(result.Failures, "\n")}
+			suite.Failures++
+		}
+		suite.Tests++
+		suite.Cases = append(suite.Cases, c)
+	}
+	for i := range suites.Suites {
+		var elapsed time.Duration
+		for _, result := range r.Results {
+			if result.Operation == suites.Suites[i].Name {
+				elapsed += result.Elapsed
+			}
+		}
+		suites.Suites[i].Time = junitTime(elapsed)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// WriteText writes the report in a human-readable text format, a line for each test case followed by its failures.
+func (r *Report) WriteText(w io.Writer) error {
+	var b strings.Builder
+	for _, result := range r.Results {
+		status := "PASS"
+		if !result.Passed() {
+			status = "FAIL"
+		}
+		fmt.Fprintf(&b, "%s  %s (%s)\n", status, result.Name, result.Elapsed.Round(time.Millisecond))
+		for _, failure := range result.Failures {
+			fmt.Fprintf(&b, "      %s\n", failure)
+		}
+	}
+	fmt.Fprintf(&b, "%d passed, %d failed in %s\n", len(r.Results)-r.Failed(), r.Failed(), r.Elapsed.Round(time.Millisecond))
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteJSON writes the report in JSON. Durations are in nanoseconds.
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// junitTime formats d in seconds as JUnit XML does.
+func junitTime(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
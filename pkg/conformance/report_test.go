@@ -0,0 +1,60 @@
+package conformance
+
+import (
+	"bytes"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Report", func() {
+	report := &Report{
+		Server:    "http://localhost:8080",
+		StartedAt: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Elapsed:   60 * time.Millisecond,
+		Results: []Result{
+			{Operation: "getZundokos", Name: "GET /zundokos responds Zundokos", Elapsed: 10 * time.Millisecond},
+			{Operation: "postZundoko", Name: "POST /zundokos is idempotent", Elapsed: 20 * time.Millisecond, Failures: []string{"a <failure>", "another"}},
+			{Operation: "getZundokos", Name: "GET /zundokos returns Zundokos in order", Elapsed: 30 * time.Millisecond},
+		},
+	}
+
+	Describe("WriteJUnit()", func() {
+		It("writes a test suite for each operation.", func() {
+			var b bytes.Buffer
+
+			Expect(report.WriteJUnit(&b)).To(Succeed())
+
+			Expect(b.String()).To(Equal(`<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="Zundoko Server conformance: http://localhost:8080" tests="3" failures="1" time="0.060">
+  <testsuite name="getZundokos" tests="2" failures="0" time="0.040" timestamp="2020-01-02T03:04:05">
+    <testcase name="GET /zundokos responds Zundokos" classname="conformance.getZundokos" time="0.010"></testcase>
+    <testcase name="GET /zundokos returns Zundokos in order" classname="conformance.getZundokos" time="0.030"></testcase>
+  </testsuite>
+  <testsuite name="postZundoko" tests="1" failures="1" time="0.020" timestamp="2020-01-02T03:04:05">
+    <testcase name="POST /zundokos is idempotent" classname="conformance.postZundoko" time="0.020">
+      <failure message="a &lt;failure&gt;">a &lt;failure&gt;&#xA;another</failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`))
+		})
+	})
+
+	Describe("WriteText()", func() {
+		It("writes a line for each test case followed by its failures.", func() {
+			var b bytes.Buffer
+
+			Expect(report.WriteText(&b)).To(Succeed())
+
+			Expect(b.String()).To(Equal(`PASS  GET /zundokos responds Zundokos (10ms)
+FAIL  POST /zundokos is idempotent (20ms)
+      a <failure>
+      another
+PASS  GET /zundokos returns Zundokos in order (30ms)
+2 passed, 1 failed in 60ms
+`))
+		})
+	})
+})
@@ -0,0 +1,183 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/logging"
+	"github.com/kaitoy/zundoko-go-client/pkg/runner"
+	"github.com/robfig/cron/v3"
+)
+
+// Config represents configuration of a Daemon.
+type Config struct {
+	// Schedule is a cron expression of standard 5 fields or a descriptor like "@every 5m" or "@hourly",
+	// which decides when to start sessions. If empty, sessions are run back to back after the Cooldown.
+	Schedule string
+
+	// Count is the number of sessions to run. Zero means no limit.
+	Count int
+
+	// Cooldown is the minimum time between the end of a session and the start of the next one.
+	Cooldown time.Duration
+
+	// Jitter is the maximum random delay added to the start of each session,
+	// so that many daemons on the same schedule don't hit Zundoko Server at once.
+	Jitter time.Duration
+
+	// Backoff is the minimum time between a failed session and the next one, which doubles for each
+	// consecutive failure up to MaxBackoff. Defaults to 1 second.
+	Backoff time.Duration
+
+	// MaxBackoff is the maximum of the backoff. Defaults to 10 minutes.
+	MaxBackoff time.Duration
+}
+
+// Record represents the result of a session run by a Daemon, which is written to the results as a JSON line.
+type Record struct {
+	// Session is the sequence number of the session from 1.
+	Session int `json:"session"`
+
+	// Succeeded tells if the session ended without an error.
+	Succeeded bool `json:"succeeded"`
+
+	// Error is the error the session ended with.
+	Error string `json:"error,omitempty"`
+
+	// ConsecutiveFailures is the number of sessions failed in a row until this one.
+	ConsecutiveFailures int `json:"consecutiveFailures"`
+
+	// Result is the result of the session.
+	Result *runner.Result `json:"result"`
+}
+
+// Daemon runs Zundoko Kiyoshi sessions repeatedly.
+type Daemon interface {
+	// Run runs sessions until the Count is reached or ctx is done, writing their Records to the results.
+	// A session interrupted by ctx is not recorded. It returns nil when ctx is done.
+	Run(ctx context.Context) error
+}
+
+// Option configures a Daemon.
+type Option func(d *daemon)
+
+// WithClock makes a Daemon wait for sessions by the Clock instead of the real one.
+func WithClock(clock runner.Clock) Option {
+	return func(d *daemon) {
+		d.clock = clock
+	}
+}
+
+// WithRand makes a Daemon decide jitters with rnd instead of a time-seeded one.
+func WithRand(rnd runner.Rand) Option {
+	return func(d *daemon) {
+		d.rnd = rnd
+	}
+}
+
+// NewDaemon creates a Daemon that runs sessions by Runners created by newRunner, and writes their Records to results.
+// It returns an error if the Schedule can't be parsed.
+func NewDaemon(newRunner func() runner.Runner, results io.Writer, config Config, opts ...Option) (Daemon, error) {
+	if config.Backoff <= 0 {
+		config.Backoff = time.Second
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = 10 * time.Minute
+	}
+	d := &daemon{
+		newRunner: newRunner,
+		results:   json.NewEncoder(results),
+		config:    config,
+		clock:     runner.NewRealClock(),
+		rnd:       rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	if config.Schedule != "" {
+		schedule, err := cron.ParseStandard(config.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %w", config.Schedule, err)
+		}
+		d.schedule = schedule
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d, nil
+}
+
+type daemon struct {
+	newRunner func() runner.Runner
+	config    Config
+	schedule  cron.Schedule
+	clock     runner.Clock
+	rnd       runner.Rand
+	results   *json.Encoder
+}
+
+func (d *daemon) Run(ctx context.Context) error {
+	failures := 0
+	for session := 1; d.config.Count == 0 || session <= d.config.Count; session++ {
+		next := d.nextStart(session, failures)
+		logging.GetLogger().Infow("Wait for the next session.", "session", session, "at", next)
+		if err := d.waitUntil(ctx, next); err != nil {
+			return nil
+		}
+
+		result, err := d.newRunner().Run(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		record := Record{Session: session, Succeeded: err == nil, Result: result}
+		if err != nil {
+			failures++
+			record.Error = err.Error()
+			logging.GetLogger().Warnw("A session failed.", "session", session, "consecutiveFailures", failures, "err", err)
+		} else {
+			failures = 0
+		}
+		record.ConsecutiveFailures = failures
+		if err := d.results.Encode(record); err != nil {
+			return fmt.Errorf("failed to write a result: %w", err)
+		}
+	}
+	return nil
+}
+
+// nextStart returns the time to start the session, which is the sequence number from 1,
+// after the number of consecutive failures.
+func (d *daemon) nextStart(session, failures int) time.Time {
+	now := d.clock.Now()
+	next := now
+	if session > 1 {
+		next = next.Add(d.config.Cooldown)
+	}
+	if failures > 0 {
+		if earliest := now.Add(runner.Backoff(d.config.Backoff, d.config.MaxBackoff, failures)); earliest.After(next) {
+			next = earliest
+		}
+	}
+	if d.schedule != nil {
+		next = d.schedule.Next(next)
+	}
+	if d.config.Jitter > 0 {
+		next = next.Add(time.Duration(d.rnd.Int63n(int64(d.config.Jitter) + 1)))
+	}
+	return next
+}
+
+// waitUntil waits until the time by the clock, or until ctx is done.
+func (d *daemon) waitUntil(ctx context.Context, t time.Time) error {
+	wait := t.Sub(d.clock.Now())
+	if wait <= 0 {
+		return ctx.Err()
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-d.clock.After(wait):
+		return nil
+	}
+}
@@ -0,0 +1,255 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/client"
+	"github.com/kaitoy/zundoko-go-client/pkg/history"
+	"github.com/kaitoy/zundoko-go-client/pkg/model"
+	"github.com/kaitoy/zundoko-go-client/pkg/runner"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// fakeRunner is a runner.Runner that fails with the errors in order and then succeeds.
+type fakeRunner struct {
+	mu     sync.Mutex
+	errs   []error
+	starts []time.Time
+	clock  runner.Clock
+}
+
+func (r *fakeRunner) Run(ctx context.Context) (*runner.Result, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.starts = append(r.starts, r.clock.Now())
+	if len(r.errs) == 0 {
+		return &runner.Result{}, nil
+	}
+	err := r.errs[0]
+	r.errs = r.errs[1:]
+	return &runner.Result{}, err
+}
+
+func (r *fakeRunner) startTimes() []time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]time.Time(nil), r.starts...)
+}
+
+// failingClient is a client.Client that fails to get Zundokos.
+type failingClient struct {
+	client.Client
+}
+
+func (failingClient) GetZundokos(ctx context.Context) ([]model.Zundoko, error) {
+	return nil, errors.New("unavailable")
+}
+
+// maxRand is a runner.Rand that always returns the maximum.
+type maxRand struct{}
+
+func (maxRand) Intn(n int) int       { return n - 1 }
+func (maxRand) Int63n(n int64) int64 { return n - 1 }
+
+var _ = Describe("Daemon", func() {
+	var (
+		start   time.Time
+		clock   *runner.FakeClock
+		fake    *fakeRunner
+		results *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		start = time.Date(2020, 1, 2, 3, 4, 30, 0, time.UTC)
+		clock = runner.NewFakeClock(start)
+		fake = &fakeRunner{clock: clock}
+		results = &bytes.Buffer{}
+	})
+
+	newDaemon := func(config Config, opts ...Option) Daemon {
+		d, err := NewDaemon(func() runner.Runner { return fake }, results, config, append([]Option{WithClock(clock)}, opts...)...)
+		Expect(err).To(BeNil())
+		return d
+	}
+
+	// runAdvancing runs the daemon advancing the clock by step whenever it waits, and returns the error of it.
+	runAdvancing := func(d Daemon, step time.Duration) error {
+		done := make(chan error, 1)
+		go func() { done <- d.Run(context.Background()) }()
+		for {
+			select {
+			case err := <-done:
+				return err
+			case <-time.After(time.Millisecond):
+				if clock.Waiters() > 0 {
+					clock.Advance(step)
+				}
+			}
+		}
+	}
+
+	records := func() []Record {
+		var rs []Record
+		for _, line := range strings.Split(strings.TrimSpace(results.String()), "\n") {
+			var r Record
+			Expect(json.Unmarshal([]byte(line), &r)).To(Succeed())
+			rs = append(rs, r)
+		}
+		return rs
+	}
+
+	Describe("NewDaemon()", func() {
+		It("returns an error for an invalid schedule.", func() {
+			_, err := NewDaemon(func() runner.Runner { return fake }, results, Config{Schedule: "every minute"})
+
+			Expect(err).To(MatchError(ContainSubstring("every minute")))
+		})
+	})
+
+	Describe("Run()", func() {
+		It("runs the sessions back to back after the cooldown and records them.", func() {
+			d := newDaemon(Config{Count: 3, Cooldown: 10 * time.Second})
+
+			Expect(runAdvancing(d, time.Second)).To(Succeed())
+
+			Expect(fake.startTimes()).To(Equal([]time.Time{
+				start,
+				start.Add(10 * time.Second),
+				start.Add(20 * time.Second),
+			}))
+			rs := records()
+			Expect(rs).To(HaveLen(3))
+			for i, r := range rs {
+				Expect(r.Session).To(Equal(i + 1))
+				Expect(r.Succeeded).To(BeTrue())
+				Expect(r.Result).NotTo(BeNil())
+			}
+		})
+
+		It("runs the sessions on the schedule.", func() {
+			d := newDaemon(Config{Count: 2, Schedule: "*/5 * * * *"})
+
+			Expect(runAdvancing(d, time.Second)).To(Succeed())
+
+			Expect(fake.startTimes()).To(Equal([]time.Time{
+				time.Date(2020, 1, 2, 3, 5, 0, 0, time.UTC),
+				time.Date(2020, 1, 2, 3, 10, 0, 0, time.UTC),
+			}))
+		})
+
+		It("adds the jitter to the start of the sessions.", func() {
+			d := newDaemon(Config{Count: 2, Schedule: "@every 1m", Jitter: 5 * time.Second}, WithRand(maxRand{}))
+
+			Expect(runAdvancing(d, time.Second)).To(Succeed())
+
+			Expect(fake.startTimes()).To(Equal([]time.Time{
+				start.Add(time.Minute + 5*time.Second),
+				start.Add(2*time.Minute + 10*time.Second),
+			}))
+		})
+
+		It("backs off exponentially on consecutive failures.", func() {
+			fake.errs = []error{errors.New("a"), errors.New("b"), errors.New("c")}
+			d := newDaemon(Config{Count: 5, Backoff: 10 * time.Second, MaxBackoff: 30 * time.Second})
+
+			Expect(runAdvancing(d, time.Second)).To(Succeed())
+
+			Expect(fake.startTimes()).To(Equal([]time.Time{
+				start,
+				start.Add(10 * time.Second),
+				start.Add(30 * time.Second),
+				start.Add(60 * time.Second),
+				start.Add(60 * time.Second),
+			}))
+			rs := records()
+			Expect(rs[0].Error).To(Equal("a"))
+			Expect(rs[2].ConsecutiveFailures).To(Equal(3))
+			Expect(rs[3].Succeeded).To(BeTrue())
+			Expect(rs[3].ConsecutiveFailures).To(Equal(0))
+		})
+
+		It("grows the backoff for Runners failing in a row and resets it after a success.", func() {
+			// The Runners are created as the run command does, with clients failing in the sessions 1, 2, 4, and 5.
+			fails := []bool{true, true, false, true, true, false}
+			created := 0
+			newRunner := func() runner.Runner {
+				cl := history.NewMemoryClient(nil)
+				if fails[created] {
+					cl = failingClient{cl}
+				}
+				created++
+				return runner.NewRunner(
+					cl,
+					runner.WithPresenter(runner.NewSilentPresenter()),
+					runner.WithPacer(runner.NewFastPacer()),
+					runner.WithClock(clock),
+				)
+			}
+			d, err := NewDaemon(
+				newRunner,
+				results,
+				Config{Count: len(fails), Backoff: 10 * time.Second, MaxBackoff: time.Minute},
+				WithClock(clock),
+			)
+			Expect(err).To(BeNil())
+
+			Expect(runAdvancing(d, time.Second)).To(Succeed())
+
+			var starts []time.Time
+			var failures []int
+			for _, r := range records() {
+				starts = append(starts, r.Result.StartedAt)
+				failures = append(failures, r.ConsecutiveFailures)
+				Expect(r.Succeeded).To(Equal(r.ConsecutiveFailures == 0))
+			}
+			Expect(starts).To(Equal([]time.Time{
+				start,
+				start.Add(10 * time.Second),
+				start.Add(30 * time.Second),
+				start.Add(30 * time.Second),
+				start.Add(40 * time.Second),
+				start.Add(60 * time.Second),
+			}))
+			Expect(failures).To(Equal([]int{1, 2, 0, 1, 2, 0}))
+		})
+
+		It("stops without an error when the context is done.", func() {
+			d := newDaemon(Config{Schedule: "@hourly"})
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() { done <- d.Run(ctx) }()
+			Eventually(clock.Waiters).Should(Equal(1))
+
+			cancel()
+
+			Eventually(done).Should(Receive(BeNil()))
+			Expect(fake.startTimes()).To(BeEmpty())
+			Expect(results.Len()).To(BeZero())
+		})
+	})
+
+	Describe("nextStart()", func() {
+		It("never waits less than the cooldown on the schedule.", func() {
+			d := newDaemon(Config{Schedule: "*/5 * * * *", Cooldown: 4 * time.Minute}).(*daemon)
+			clock.Advance(4*time.Minute + 30*time.Second)
+
+			Expect(d.nextStart(2, 0)).To(Equal(time.Date(2020, 1, 2, 3, 15, 0, 0, time.UTC)))
+		})
+
+		It("keeps the jitter in its bound.", func() {
+			d := newDaemon(Config{Jitter: time.Second}, WithRand(rand.New(rand.NewSource(1)))).(*daemon)
+
+			for i := 0; i < 100; i++ {
+				Expect(d.nextStart(1, 0)).To(BeTemporally("~", start.Add(500*time.Millisecond), 500*time.Millisecond))
+			}
+		})
+	})
+})
@@ -0,0 +1,2 @@
+// Package daemon provides a daemon that runs Zundoko Kiyoshi sessions repeatedly, e.g. for synthetic monitoring.
+package daemon
@@ -0,0 +1,62 @@
+package history
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+func writeCSV(w io.Writer, h *History) error {
+	cw := csv.NewWriter(w)
+	cw.Write(columns)
+	for _, r := range rowsOf(h) {
+		sequence := ""
+		if r.Sequence != 0 {
+			sequence = strconv.FormatInt(r.Sequence, 10)
+		}
+		cw.Write([]string{r.Type, r.Id, sequence, r.SaidAt, r.Word, r.MadeBy})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func readCSV(r io.Reader) (*History, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = len(columns)
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the header: %w", err)
+	}
+	if strings.Join(header, ",") != strings.Join(columns, ",") {
+		return nil, fmt.Errorf("unexpected header: %s", strings.Join(header, ","))
+	}
+
+	h := &History{}
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return h, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		var sequence int64
+		if record[2] != "" {
+			if sequence, err = strconv.ParseInt(record[2], 10, 64); err != nil {
+				return nil, fmt.Errorf("invalid sequence of %s %s: %w", record[0], record[1], err)
+			}
+		}
+		if err := h.add(row{
+			Type:     record[0],
+			Id:       record[1],
+			Sequence: sequence,
+			SaidAt:   record[3],
+			Word:     record[4],
+			MadeBy:   record[5],
+		}); err != nil {
+			return nil, err
+		}
+	}
+}
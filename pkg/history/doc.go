@@ -0,0 +1,2 @@
+// Package history provides export, import, and replay of histories of Zundoko Kiyoshi games.
+package history
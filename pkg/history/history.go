@@ -0,0 +1,123 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/client"
+	"github.com/kaitoy/zundoko-go-client/pkg/model"
+	"github.com/kaitoy/zundoko-go-client/pkg/runner"
+)
+
+// History represents a history of Zundoko Kiyoshi games, i.e. Zundokos and Kiyoshies on Zundoko Server.
+type History struct {
+	// Zundokos are the Zundokos in the order they were said.
+	Zundokos []model.Zundoko
+
+	// Kiyoshies are the Kiyoshies in the order they were said.
+	Kiyoshies []model.Kiyoshi
+}
+
+// Fetch gets the history from Zundoko Server.
+func Fetch(ctx context.Context, cl client.Client) (*History, error) {
+	zundokos, err := cl.GetZundokos(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Zundokos: %w", err)
+	}
+	kiyoshies, err := cl.GetKiyoshies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Kiyoshies: %w", err)
+	}
+	h := &History{Zundokos: zundokos, Kiyoshies: kiyoshies}
+	h.sort()
+	return h, nil
+}
+
+// sort sorts the Zundokos and Kiyoshies in the order they were said.
+func (h *History) sort() {
+	runner.SortZundokos(h.Zundokos)
+	sortKiyoshies(h.Kiyoshies)
+}
+
+// Format represents a file format of an exported history.
+type Format string
+
+// List of Format
+const (
+	// FormatJSONLines writes a JSON object per line, which has either "zundoko" or "kiyoshi" field.
+	FormatJSONLines Format = "jsonl"
+
+	// FormatCSV writes a row per Zundoko or Kiyoshi with a header row.
+	FormatCSV Format = "csv"
+
+	// FormatParquet writes an Apache Parquet file with the same columns as FormatCSV.
+	FormatParquet Format = "parquet"
+)
+
+// ParseFormat returns the Format of the name, which is "jsonl", "csv", or "parquet".
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case FormatJSONLines, FormatCSV, FormatParquet:
+		return f, nil
+	case "json", "ndjson":
+		return FormatJSONLines, nil
+	default:
+		return "", fmt.Errorf("unknown history format: %s", name)
+	}
+}
+
+// FormatOf returns the Format of the file by the extension of the path.
+func FormatOf(path string) (Format, error) {
+	return ParseFormat(strings.TrimPrefix(filepath.Ext(path), "."))
+}
+
+// Write writes the history to w in the format.
+func Write(w io.Writer, h *History, format Format) error {
+	switch format {
+	case FormatJSONLines:
+		return writeJSONLines(w, h)
+	case FormatCSV:
+		return writeCSV(w, h)
+	case FormatParquet:
+		return writeParquet(w, h)
+	default:
+		return fmt.Errorf("unknown history format: %s", format)
+	}
+}
+
+// Read reads a history in the format from r.
+// The Zundokos and Kiyoshies are sorted in the order they were said.
+func Read(r io.Reader, format Format) (*History, error) {
+	var h *History
+	var err error
+	switch format {
+	case FormatJSONLines:
+		h, err = readJSONLines(r)
+	case FormatCSV:
+		h, err = readCSV(r)
+	case FormatParquet:
+		h, err = readParquet(r)
+	default:
+		return nil, fmt.Errorf("unknown history format: %s", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read a history in %s: %w", format, err)
+	}
+	h.sort()
+	return h, nil
+}
+
+// sortKiyoshies sorts the Kiyoshies by SaidAt, and then by Id.
+func sortKiyoshies(kiyoshies []model.Kiyoshi) {
+	sort.SliceStable(kiyoshies, func(i, j int) bool {
+		a, b := kiyoshies[i], kiyoshies[j]
+		if !a.SaidAt.Equal(b.SaidAt) {
+			return a.SaidAt.Before(b.SaidAt)
+		}
+		return a.Id < b.Id
+	})
+}
@@ -0,0 +1,270 @@
+package history
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"flag"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// update makes tests update the golden files in testdata instead of comparing with them.
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// newHistory returns a history of the words said a second apart from start, followed by a Kiyoshi.
+func newHistory(start time.Time, words ...string) *History {
+	h := &History{}
+	for i, word := range words {
+		h.Zundokos = append(h.Zundokos, model.Zundoko{
+			Id:       "zd" + string(rune('a'+i)),
+			Sequence: int64(i + 1),
+			SaidAt:   start.Add(time.Duration(i) * time.Second),
+			Word:     word,
+		})
+	}
+	h.Kiyoshies = append(h.Kiyoshies, model.Kiyoshi{
+		Id:     "k1",
+		SaidAt: start.Add(time.Duration(len(words)) * time.Second),
+		MadeBy: "kaitoy@example.com",
+	})
+	return h
+}
+
+var _ = Describe("History", func() {
+	var (
+		start time.Time
+	)
+
+	BeforeEach(func() {
+		start = time.Date(2021, 1, 1, 0, 0, 0, 123456789, time.UTC)
+	})
+
+	Describe("Fetch()", func() {
+		It("gets Zundokos and Kiyoshies sorted in the order they were said.", func() {
+			h := newHistory(start, "Zun", "Doko")
+			h.Zundokos[0], h.Zundokos[1] = h.Zundokos[1], h.Zundokos[0]
+
+			fetched, err := Fetch(context.Background(), NewMemoryClient(h))
+
+			Expect(err).To(BeNil())
+			Expect(fetched).To(Equal(newHistory(start, "Zun", "Doko")))
+		})
+	})
+
+	Describe("ParseFormat()", func() {
+		It("accepts the format names and their aliases in any case.", func() {
+			for name, expected := range map[string]Format{
+				"jsonl":   FormatJSONLines,
+				"NDJSON":  FormatJSONLines,
+				"csv":     FormatCSV,
+				"Parquet": FormatParquet,
+			} {
+				format, err := ParseFormat(name)
+				Expect(err).To(BeNil())
+				Expect(format).To(Equal(expected))
+			}
+		})
+
+		It("returns an error for an unknown format.", func() {
+			_, err := ParseFormat("xml")
+			Expect(err).To(MatchError(ContainSubstring("xml")))
+		})
+	})
+
+	Describe("FormatOf()", func() {
+		It("tells the format by the extension.", func() {
+			format, err := FormatOf("/tmp/history.parquet")
+			Expect(err).To(BeNil())
+			Expect(format).To(Equal(FormatParquet))
+		})
+	})
+
+	Describe("Write() and Read()", func() {
+		for _, format := range []Format{FormatJSONLines, FormatCSV, FormatParquet} {
+			format := format
+
+			Context("in "+string(format), func() {
+				It("round-trip a history.", func() {
+					h := newHistory(start, "Zun", "Zun", "Zun", "Zun", "Doko")
+					h.Zundokos[4].Sequence = 0
+					h.Zundokos[3].SaidAt = start.Add(3 * time.Second).In(time.FixedZone("JST", 9*60*60))
+					var buf bytes.Buffer
+
+					Expect(Write(&buf, h, format)).To(Succeed())
+					read, err := Read(&buf, format)
+
+					Expect(err).To(BeNil())
+					Expect(read.Zundokos).To(HaveLen(5))
+					for i, zd := range read.Zundokos {
+						Expect(zd.Id).To(Equal(h.Zundokos[i].Id))
+						Expect(zd.Sequence).To(Equal(h.Zundokos[i].Sequence))
+						Expect(zd.SaidAt.Equal(h.Zundokos[i].SaidAt)).To(BeTrue())
+						Expect(zd.Word).To(Equal(h.Zundokos[i].Word))
+					}
+					Expect(read.Kiyoshies).To(Equal(h.Kiyoshies))
+				})
+
+				It("round-trip an empty history.", func() {
+					var buf bytes.Buffer
+
+					Expect(Write(&buf, &History{}, format)).To(Succeed())
+					read, err := Read(&buf, format)
+
+					Expect(err).To(BeNil())
+					Expect(read.Zundokos).To(BeEmpty())
+					Expect(read.Kiyoshies).To(BeEmpty())
+				})
+
+				It("round-trip entities without SaidAt.", func() {
+					h := &History{Zundokos: []model.Zundoko{{Id: "zd1", Word: "Zun"}}}
+					var buf bytes.Buffer
+
+					Expect(Write(&buf, h, format)).To(Succeed())
+					read, err := Read(&buf, format)
+
+					Expect(err).To(BeNil())
+					Expect(read.Zundokos).To(Equal(h.Zundokos))
+				})
+			})
+		}
+
+		It("writes a JSON object per line in jsonl.", func() {
+			var buf bytes.Buffer
+
+			Expect(Write(&buf, newHistory(start, "Zun"), FormatJSONLines)).To(Succeed())
+
+			Expect(buf.String()).To(Equal(
+				`{"zundoko":{"id":"zda","sequence":1,"saidAt":"2021-01-01T00:00:00.123456789Z","word":"Zun"}}` + "\n" +
+					`{"kiyoshi":{"id":"k1","saidAt":"2021-01-01T00:00:01.123456789Z","madeBy":"kaitoy@example.com"}}` + "\n",
+			))
+		})
+
+		It("writes a header and a row per entity in csv.", func() {
+			var buf bytes.Buffer
+
+			Expect(Write(&buf, newHistory(start, "Zun"), FormatCSV)).To(Succeed())
+
+			Expect(buf.String()).To(Equal(
+				"type,id,sequence,saidAt,word,madeBy\n" +
+					"zundoko,zda,1,2021-01-01T00:00:00.123456789Z,Zun,\n" +
+					"kiyoshi,k1,,2021-01-01T00:00:01.123456789Z,,kaitoy@example.com\n",
+			))
+		})
+
+		It("writes a Parquet file with the magic numbers in parquet.", func() {
+			var buf bytes.Buffer
+
+			Expect(Write(&buf, newHistory(start, "Zun"), FormatParquet)).To(Succeed())
+
+			Expect(buf.String()).To(HavePrefix(parquetMagic))
+			Expect(buf.String()).To(HaveSuffix(parquetMagic))
+			Expect(buf.String()).To(ContainSubstring("kaitoy@example.com"))
+		})
+
+		It("writes saidAt as an optional timestamp of nanoseconds in UTC and strings of the STRING logical type in parquet.", func() {
+			var buf bytes.Buffer
+			Expect(Write(&buf, newHistory(start, "Zun"), FormatParquet)).To(Succeed())
+			file := buf.Bytes()
+			footerSize := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+			meta, err := thriftReader{bytes.NewReader(file[len(file)-8-footerSize : len(file)-8])}.readStruct()
+			Expect(err).To(BeNil())
+
+			elements := map[string]thriftFields{}
+			for _, element := range meta.structs(2)[1:] {
+				elements[element.string(4)] = element
+			}
+			Expect(elements["saidAt"].int(1)).To(Equal(int64(parquetInt64)))
+			Expect(elements["saidAt"].int(3)).To(Equal(int64(parquetOptional)))
+			timestamp := elements["saidAt"].strct(10).strct(parquetLogicalTimestamp)
+			Expect(timestamp[1]).To(Equal(true))
+			Expect(timestamp.strct(2).has(parquetNanos)).To(BeTrue())
+			for _, name := range []string{"type", "id", "word", "madeBy"} {
+				Expect(elements[name].int(1)).To(Equal(int64(parquetByteArray)))
+				Expect(elements[name].int(3)).To(Equal(int64(parquetRequired)))
+				Expect(elements[name].strct(10).has(parquetLogicalString)).To(BeTrue())
+			}
+			Expect(elements["sequence"].int(1)).To(Equal(int64(parquetInt64)))
+		})
+
+		It("writes the same Parquet file as the golden one in testdata.", func() {
+			h := newHistory(start, "Zun", "Doko")
+			h.Zundokos[1].SaidAt = time.Time{}
+			var buf bytes.Buffer
+			Expect(Write(&buf, h, FormatParquet)).To(Succeed())
+			if *update {
+				Expect(ioutil.WriteFile("testdata/history.parquet", buf.Bytes(), 0644)).To(Succeed())
+			}
+
+			golden, err := ioutil.ReadFile("testdata/history.parquet")
+
+			Expect(err).To(BeNil())
+			Expect(buf.Bytes()).To(Equal(golden))
+			read, err := Read(bytes.NewReader(golden), FormatParquet)
+			Expect(err).To(BeNil())
+			Expect(read.Zundokos).To(HaveLen(2))
+			Expect(read.Zundokos[0].SaidAt.Equal(start)).To(BeTrue())
+			Expect(read.Zundokos[1].SaidAt.IsZero()).To(BeTrue())
+			Expect(read.Kiyoshies).To(Equal(h.Kiyoshies))
+		})
+
+		It("writes a Parquet file pyarrow reads as written.", func() {
+			if exec.Command("python3", "-c", "import pyarrow").Run() != nil {
+				if os.Getenv("PARQUET_READER") == "required" {
+					Fail("pyarrow is required to read Parquet files but not installed")
+				}
+				Skip("pyarrow is not installed, which make test installs")
+			}
+			h := newHistory(start, "Zun", "Doko")
+			h.Zundokos[1].SaidAt = time.Time{}
+			path := filepath.Join(GinkgoT().TempDir(), "history.parquet")
+			f, err := os.Create(path)
+			Expect(err).To(BeNil())
+			Expect(Write(f, h, FormatParquet)).To(Succeed())
+			Expect(f.Close()).To(Succeed())
+
+			out, err := exec.Command("python3", "testdata/check_parquet.py", path).CombinedOutput()
+
+			Expect(err).To(BeNil(), string(out))
+			Expect(string(out)).To(Equal("OK\n"))
+		})
+
+		It("decodes bit-packed definition levels, which other writers use.", func() {
+			defined := make([]bool, 10)
+
+			Expect(decodeLevels([]byte{0x03, 0x05, 0x04, 0x01}, defined)).To(Succeed())
+
+			Expect(defined).To(Equal([]bool{true, false, true, false, false, false, false, false, true, true}))
+		})
+
+		It("returns an error for a broken file.", func() {
+			var buf bytes.Buffer
+			Expect(Write(&buf, newHistory(start, "Zun"), FormatParquet)).To(Succeed())
+			broken := buf.Bytes()[:buf.Len()-10]
+
+			_, err := Read(bytes.NewReader(append(broken, parquetMagic...)), FormatParquet)
+
+			Expect(err).NotTo(BeNil())
+		})
+
+		It("returns an error for a csv with an unexpected header.", func() {
+			_, err := Read(strings.NewReader("type,id\nzundoko,zd1\n"), FormatCSV)
+
+			Expect(err).To(MatchError(ContainSubstring("csv")))
+		})
+
+		It("returns an error for an unknown row type.", func() {
+			_, err := Read(strings.NewReader("type,id,sequence,saidAt,word,madeBy\nhoge,zd1,,,,\n"), FormatCSV)
+
+			Expect(err).To(MatchError(ContainSubstring("hoge")))
+		})
+	})
+})
@@ -0,0 +1,57 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/model"
+)
+
+// jsonLine is a line in JSON lines format, which has either a Zundoko or a Kiyoshi.
+type jsonLine struct {
+	Zundoko *model.Zundoko `json:"zundoko,omitempty"`
+	Kiyoshi *model.Kiyoshi `json:"kiyoshi,omitempty"`
+}
+
+func writeJSONLines(w io.Writer, h *History) error {
+	enc := json.NewEncoder(w)
+	for i := range h.Zundokos {
+		if err := enc.Encode(jsonLine{Zundoko: &h.Zundokos[i]}); err != nil {
+			return err
+		}
+	}
+	for i := range h.Kiyoshies {
+		if err := enc.Encode(jsonLine{Kiyoshi: &h.Kiyoshies[i]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readJSONLines(r io.Reader) (*History, error) {
+	h := &History{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var line jsonLine
+		if err := json.Unmarshal(text, &line); err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		switch {
+		case line.Zundoko != nil:
+			h.Zundokos = append(h.Zundokos, *line.Zundoko)
+		case line.Kiyoshi != nil:
+			h.Kiyoshies = append(h.Kiyoshies, *line.Kiyoshi)
+		default:
+			return nil, fmt.Errorf("line %d: neither a Zundoko nor a Kiyoshi", n)
+		}
+	}
+	return h, scanner.Err()
+}
@@ -0,0 +1,93 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/client"
+	"github.com/kaitoy/zundoko-go-client/pkg/model"
+)
+
+// NewMemoryClient creates a client.Client that keeps Zundokos and Kiyoshies in memory instead of calling
+// the APIs of Zundoko Server, starting with the ones in the history if not nil.
+// Like Zundoko Server, it assigns Sequence to posted Zundokos, and accepts a post of an entity with the id
+// of an existing one only if they are the same, returning an error wrapping client.ErrConflict otherwise.
+// It also returns an error wrapping client.ErrConflict for a Kiyoshi posted after another one with no Zundoko
+// posted in between, which is a duplicate Kiyoshi for the same pattern.
+// The pattern is claimed at the start if the last Kiyoshi in the history was said after the last Zundoko.
+// It's safe for concurrent use.
+func NewMemoryClient(h *History) client.Client {
+	c := &memoryClient{}
+	if h != nil {
+		c.zundokos = append(c.zundokos, h.Zundokos...)
+		c.kiyoshies = append(c.kiyoshies, h.Kiyoshies...)
+		if len(c.kiyoshies) > 0 {
+			last := c.kiyoshies[len(c.kiyoshies)-1]
+			if len(c.zundokos) == 0 || !last.SaidAt.Before(c.zundokos[len(c.zundokos)-1].SaidAt) {
+				c.claimed = &last
+			}
+		}
+	}
+	return c
+}
+
+type memoryClient struct {
+	mu        sync.Mutex
+	zundokos  []model.Zundoko
+	kiyoshies []model.Kiyoshi
+
+	// claimed is the Kiyoshi made for the last Zundokos, or nil if none has been made since the last Zundoko.
+	claimed *model.Kiyoshi
+}
+
+func (c *memoryClient) GetZundokos(ctx context.Context) ([]model.Zundoko, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]model.Zundoko(nil), c.zundokos...), nil
+}
+
+func (c *memoryClient) PostZundoko(ctx context.Context, zundoko *model.Zundoko) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, existing := range c.zundokos {
+		if existing.Id != zundoko.Id {
+			continue
+		}
+		if existing.Word == zundoko.Word {
+			return nil
+		}
+		return fmt.Errorf("Zundoko %s exists with another word: %w", existing.Id, client.ErrConflict)
+	}
+
+	created := *zundoko
+	created.Sequence = int64(len(c.zundokos) + 1)
+	c.zundokos = append(c.zundokos, created)
+	c.claimed = nil
+	return nil
+}
+
+func (c *memoryClient) PostKiyoshi(ctx context.Context, kiyoshi *model.Kiyoshi) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, existing := range c.kiyoshies {
+		if existing.Id == kiyoshi.Id {
+			return nil
+		}
+	}
+	if c.claimed != nil {
+		return fmt.Errorf("Kiyoshi %s has been made for the pattern: %w", c.claimed.Id, client.ErrConflict)
+	}
+	created := *kiyoshi
+	c.kiyoshies = append(c.kiyoshies, created)
+	c.claimed = &created
+	return nil
+}
+
+func (c *memoryClient) GetKiyoshies(ctx context.Context) ([]model.Kiyoshi, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]model.Kiyoshi(nil), c.kiyoshies...), nil
+}
@@ -0,0 +1,59 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/client"
+	"github.com/kaitoy/zundoko-go-client/pkg/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewMemoryClient()", func() {
+	ctx := context.Background()
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	It("assigns sequences to Zundokos and rejects another word with a used id.", func() {
+		cl := NewMemoryClient(nil)
+
+		Expect(cl.PostZundoko(ctx, &model.Zundoko{Id: "zd1", Word: "Zun"})).To(Succeed())
+		Expect(cl.PostZundoko(ctx, &model.Zundoko{Id: "zd1", Word: "Zun"})).To(Succeed())
+		err := cl.PostZundoko(ctx, &model.Zundoko{Id: "zd1", Word: "Doko"})
+
+		Expect(errors.Is(err, client.ErrConflict)).To(BeTrue())
+		zundokos, _ := cl.GetZundokos(ctx)
+		Expect(zundokos).To(Equal([]model.Zundoko{{Id: "zd1", Sequence: 1, Word: "Zun"}}))
+	})
+
+	It("rejects a duplicate Kiyoshi for the same pattern until a Zundoko is posted.", func() {
+		cl := NewMemoryClient(nil)
+		Expect(cl.PostZundoko(ctx, &model.Zundoko{Id: "zd1", Word: "Doko"})).To(Succeed())
+		Expect(cl.PostKiyoshi(ctx, &model.Kiyoshi{Id: "k1"})).To(Succeed())
+
+		Expect(cl.PostKiyoshi(ctx, &model.Kiyoshi{Id: "k1"})).To(Succeed())
+		err := cl.PostKiyoshi(ctx, &model.Kiyoshi{Id: "k2"})
+		Expect(errors.Is(err, client.ErrConflict)).To(BeTrue())
+		Expect(err).To(MatchError(ContainSubstring("k1")))
+
+		Expect(cl.PostZundoko(ctx, &model.Zundoko{Id: "zd2", Word: "Doko"})).To(Succeed())
+		Expect(cl.PostKiyoshi(ctx, &model.Kiyoshi{Id: "k2"})).To(Succeed())
+		kiyoshies, _ := cl.GetKiyoshies(ctx)
+		Expect(kiyoshies).To(HaveLen(2))
+	})
+
+	It("starts with the pattern claimed if the last Kiyoshi in the history was said after the last Zundoko.", func() {
+		claimed := NewMemoryClient(&History{
+			Zundokos:  []model.Zundoko{{Id: "zd1", SaidAt: start, Word: "Doko"}},
+			Kiyoshies: []model.Kiyoshi{{Id: "k1", SaidAt: start.Add(time.Second)}},
+		})
+		unclaimed := NewMemoryClient(&History{
+			Zundokos:  []model.Zundoko{{Id: "zd2", SaidAt: start.Add(2 * time.Second), Word: "Doko"}},
+			Kiyoshies: []model.Kiyoshi{{Id: "k1", SaidAt: start.Add(time.Second)}},
+		})
+
+		Expect(errors.Is(claimed.PostKiyoshi(ctx, &model.Kiyoshi{Id: "k2"}), client.ErrConflict)).To(BeTrue())
+		Expect(unclaimed.PostKiyoshi(ctx, &model.Kiyoshi{Id: "k2"})).To(Succeed())
+	})
+})
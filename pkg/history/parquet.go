@@ -0,0 +1,498 @@
+package history
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+)
+
+// The subset of Apache Parquet needed to export a history is implemented here, which is a file of
+// flat columns with a data page of PLAIN encoded, uncompressed values per column chunk.
+// Strings are BYTE_ARRAY of the STRING logical type, and saidAt is an optional INT64 of the TIMESTAMP logical type
+// in nanoseconds adjusted to UTC, which is null for the zero time. Definition levels of optional columns are
+// encoded in the RLE/bit-packing hybrid encoding.
+// Reading supports only such files, e.g. ones written by writeParquet, and timestamps in any unit.
+
+const parquetMagic = "PAR1"
+
+// Parquet physical types, repetition types, encodings, codecs, and page types used in files.
+const (
+	parquetInt64        = 2
+	parquetByteArray    = 6
+	parquetRequired     = 0
+	parquetOptional     = 1
+	parquetPlain        = 0
+	parquetRLE          = 3
+	parquetUncompressed = 0
+	parquetDataPage     = 0
+)
+
+// Parquet converted types and logical types, which is a union of the fields of these ids, used in files.
+const (
+	parquetUTF8             = 0
+	parquetLogicalString    = 1
+	parquetLogicalTimestamp = 8
+)
+
+// parquetTimeUnits maps the field ids of TimeUnit, which is a union, to the durations of the units.
+var parquetTimeUnits = map[int16]time.Duration{
+	1: time.Millisecond,
+	2: time.Microsecond,
+	3: time.Nanosecond,
+}
+
+// parquetNanos is the field id of NANOS in TimeUnit.
+const parquetNanos = 3
+
+// parquetColumn represents a column of rows in Parquet format.
+// Exactly one of str, num, and time is set to get the field of a row by the type of the column.
+type parquetColumn struct {
+	name string
+	str  func(r *row) *string
+	num  func(r *row) *int64
+
+	// time gets a time formatted by formatTime, which is stored as a timestamp, or null if it's empty.
+	time func(r *row) *string
+}
+
+func (c parquetColumn) physicalType() int32 {
+	if c.str != nil {
+		return parquetByteArray
+	}
+	return parquetInt64
+}
+
+func (c parquetColumn) repetitionType() int32 {
+	if c.time != nil {
+		return parquetOptional
+	}
+	return parquetRequired
+}
+
+var parquetColumns = []parquetColumn{
+	{name: "type", str: func(r *row) *string { return &r.Type }},
+	{name: "id", str: func(r *row) *string { return &r.Id }},
+	{name: "sequence", num: func(r *row) *int64 { return &r.Sequence }},
+	{name: "saidAt", time: func(r *row) *string { return &r.SaidAt }},
+	{name: "word", str: func(r *row) *string { return &r.Word }},
+	{name: "madeBy", str: func(r *row) *string { return &r.MadeBy }},
+}
+
+// parquetChunk holds the location of a column chunk written in a file.
+type parquetChunk struct {
+	column parquetColumn
+	offset int64
+	size   int64
+}
+
+func writeParquet(w io.Writer, h *History) error {
+	rows := rowsOf(h)
+	var file bytes.Buffer
+	file.WriteString(parquetMagic)
+
+	var chunks []parquetChunk
+	if len(rows) > 0 {
+		for _, column := range parquetColumns {
+			page, err := encodePage(rows, column)
+			if err != nil {
+				return fmt.Errorf("failed to write column %s: %w", column.name, err)
+			}
+			offset := int64(file.Len())
+			file.Write(dataPageHeader(len(rows), len(page)))
+			file.Write(page)
+			chunks = append(chunks, parquetChunk{column: column, offset: offset, size: int64(file.Len()) - offset})
+		}
+	}
+
+	footer := fileMetaData(len(rows), chunks)
+	file.Write(footer)
+	binary.Write(&file, binary.LittleEndian, uint32(len(footer)))
+	file.WriteString(parquetMagic)
+
+	_, err := w.Write(file.Bytes())
+	return err
+}
+
+// encodePage encodes the values of the column of the rows into a data page, prefixed with the definition levels
+// if the column is optional.
+func encodePage(rows []row, column parquetColumn) ([]byte, error) {
+	var page, values bytes.Buffer
+	var levels []bool
+	for i := range rows {
+		switch {
+		case column.num != nil:
+			binary.Write(&values, binary.LittleEndian, *column.num(&rows[i]))
+		case column.time != nil:
+			t, err := parseTime(*column.time(&rows[i]))
+			if err != nil {
+				return nil, err
+			}
+			levels = append(levels, !t.IsZero())
+			if t.IsZero() {
+				continue
+			}
+			nanos := t.UnixNano()
+			if !time.Unix(0, nanos).Equal(t) {
+				return nil, fmt.Errorf("time out of the range of nanosecond timestamps: %s", t)
+			}
+			binary.Write(&values, binary.LittleEndian, nanos)
+		default:
+			s := *column.str(&rows[i])
+			binary.Write(&values, binary.LittleEndian, uint32(len(s)))
+			values.WriteString(s)
+		}
+	}
+
+	if column.repetitionType() == parquetOptional {
+		encoded := encodeLevels(levels)
+		binary.Write(&page, binary.LittleEndian, uint32(len(encoded)))
+		page.Write(encoded)
+	}
+	page.Write(values.Bytes())
+	return page.Bytes(), nil
+}
+
+// encodeLevels encodes definition levels of 1 bit width in runs of the RLE/bit-packing hybrid encoding.
+func encodeLevels(levels []bool) []byte {
+	var buf bytes.Buffer
+	var b [binary.MaxVarintLen64]byte
+	for i := 0; i < len(levels); {
+		run := 1
+		for i+run < len(levels) && levels[i+run] == levels[i] {
+			run++
+		}
+		buf.Write(b[:binary.PutUvarint(b[:], uint64(run)<<1)])
+		if levels[i] {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+		i += run
+	}
+	return buf.Bytes()
+}
+
+func dataPageHeader(numValues, size int) []byte {
+	var w thriftWriter
+	w.structValue(func() {
+		w.i32Field(1, parquetDataPage)
+		w.i32Field(2, int32(size))
+		w.i32Field(3, int32(size))
+		w.structField(5, func() {
+			w.i32Field(1, int32(numValues))
+			w.i32Field(2, parquetPlain)
+			w.i32Field(3, parquetRLE)
+			w.i32Field(4, parquetRLE)
+		})
+	})
+	return w.buf.Bytes()
+}
+
+func fileMetaData(numRows int, chunks []parquetChunk) []byte {
+	var w thriftWriter
+	w.structValue(func() {
+		w.i32Field(1, 1)
+		w.listField(2, thriftStruct, len(parquetColumns)+1)
+		w.structValue(func() {
+			w.binaryField(4, "schema")
+			w.i32Field(5, int32(len(parquetColumns)))
+		})
+		for _, column := range parquetColumns {
+			column := column
+			w.structValue(func() {
+				w.i32Field(1, column.physicalType())
+				w.i32Field(3, column.repetitionType())
+				w.binaryField(4, column.name)
+				switch {
+				case column.str != nil:
+					w.i32Field(6, parquetUTF8)
+					w.structField(10, func() {
+						w.structField(parquetLogicalString, func() {})
+					})
+				case column.time != nil:
+					w.structField(10, func() {
+						w.structField(parquetLogicalTimestamp, func() {
+							w.boolField(1, true)
+							w.structField(2, func() {
+								w.structField(parquetNanos, func() {})
+							})
+						})
+					})
+				}
+			})
+		}
+		w.i64Field(3, int64(numRows))
+		numRowGroups := 0
+		if len(chunks) > 0 {
+			numRowGroups = 1
+		}
+		w.listField(4, thriftStruct, numRowGroups)
+		if numRowGroups > 0 {
+			w.structValue(func() {
+				var total int64
+				w.listField(1, thriftStruct, len(chunks))
+				for _, chunk := range chunks {
+					chunk := chunk
+					total += chunk.size
+					w.structValue(func() {
+						w.i64Field(2, chunk.offset)
+						w.structField(3, func() {
+							w.i32Field(1, chunk.column.physicalType())
+							w.listField(2, thriftI32, 2)
+							w.varint(parquetPlain)
+							w.varint(parquetRLE)
+							w.listField(3, thriftBinary, 1)
+							w.binary(chunk.column.name)
+							w.i32Field(4, parquetUncompressed)
+							w.i64Field(5, int64(numRows))
+							w.i64Field(6, chunk.size)
+							w.i64Field(7, chunk.size)
+							w.i64Field(9, chunk.offset)
+						})
+					})
+				}
+				w.i64Field(2, total)
+				w.i64Field(3, int64(numRows))
+			})
+		}
+		w.binaryField(6, "zundoko-go-client")
+	})
+	return w.buf.Bytes()
+}
+
+var errUnsupportedParquet = errors.New("unsupported Parquet file")
+
+func readParquet(r io.Reader) (*History, error) {
+	file, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	size := len(file)
+	if size < 2*len(parquetMagic)+4 ||
+		string(file[:len(parquetMagic)]) != parquetMagic ||
+		string(file[size-len(parquetMagic):]) != parquetMagic {
+		return nil, errors.New("not a Parquet file")
+	}
+	footerSize := int(binary.LittleEndian.Uint32(file[size-len(parquetMagic)-4:]))
+	footerStart := size - len(parquetMagic) - 4 - footerSize
+	if footerStart < len(parquetMagic) {
+		return nil, errThriftTruncated
+	}
+	meta, err := thriftReader{bytes.NewReader(file[footerStart : size-len(parquetMagic)-4])}.readStruct()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the file metadata: %w", err)
+	}
+
+	columns, err := parquetSchema(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &History{}
+	for _, rowGroup := range meta.structs(4) {
+		rows := make([]row, rowGroup.int(3))
+		for _, chunk := range rowGroup.structs(1) {
+			chunkMeta := chunk.strct(3)
+			path := chunkMeta.list(3)
+			if len(path) != 1 {
+				continue
+			}
+			name, _ := path[0].([]byte)
+			column, ok := columns[string(name)]
+			if !ok {
+				continue
+			}
+			if chunkMeta.int(4) != parquetUncompressed {
+				return nil, fmt.Errorf("%w: column %s is compressed", errUnsupportedParquet, name)
+			}
+			if chunkMeta.has(11) {
+				return nil, fmt.Errorf("%w: column %s is dictionary encoded", errUnsupportedParquet, name)
+			}
+			if err := readColumnChunk(file[:footerStart], chunkMeta.int(9), rows, column); err != nil {
+				return nil, fmt.Errorf("failed to read column %s: %w", name, err)
+			}
+		}
+		for _, r := range rows {
+			if err := h.add(r); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return h, nil
+}
+
+// parquetFileColumn is a column in a file with the unit of its timestamps if it's a time column.
+type parquetFileColumn struct {
+	parquetColumn
+	unit time.Duration
+}
+
+// parquetSchema checks the schema in the file metadata has the columns with the expected physical types
+// and repetition types, and returns them by name.
+func parquetSchema(meta thriftFields) (map[string]parquetFileColumn, error) {
+	elements := make(map[string]thriftFields)
+	for i, element := range meta.structs(2) {
+		if i == 0 {
+			continue
+		}
+		if element.int(5) > 0 {
+			return nil, fmt.Errorf("%w: nested column %s", errUnsupportedParquet, element.string(4))
+		}
+		elements[element.string(4)] = element
+	}
+
+	columns := make(map[string]parquetFileColumn, len(parquetColumns))
+	for _, column := range parquetColumns {
+		element, ok := elements[column.name]
+		if !ok {
+			return nil, fmt.Errorf("column %s is missing", column.name)
+		}
+		if element.int(1) != int64(column.physicalType()) {
+			return nil, fmt.Errorf("column %s has an unexpected type: %d", column.name, element.int(1))
+		}
+		if element.int(3) != int64(column.repetitionType()) {
+			return nil, fmt.Errorf("%w: column %s has repetition type %d", errUnsupportedParquet, column.name, element.int(3))
+		}
+		fileColumn := parquetFileColumn{parquetColumn: column}
+		if column.time != nil {
+			timestamp := element.strct(10).strct(parquetLogicalTimestamp)
+			for id := range timestamp.strct(2) {
+				fileColumn.unit = parquetTimeUnits[id]
+			}
+			if fileColumn.unit == 0 {
+				return nil, fmt.Errorf("column %s is not a timestamp", column.name)
+			}
+		}
+		columns[column.name] = fileColumn
+	}
+	return columns, nil
+}
+
+// readColumnChunk reads the values of the column from the data pages at the offset in data into the rows.
+func readColumnChunk(data []byte, offset int64, rows []row, column parquetFileColumn) error {
+	if offset < 0 || offset >= int64(len(data)) {
+		return errThriftTruncated
+	}
+	reader := bytes.NewReader(data[offset:])
+	for i := 0; i < len(rows); {
+		header, err := thriftReader{reader}.readStruct()
+		if err != nil {
+			return fmt.Errorf("failed to read a page header: %w", err)
+		}
+		size := int(header.int(3))
+		if size < 0 || size > reader.Len() {
+			return errThriftTruncated
+		}
+		page := make([]byte, size)
+		reader.Read(page)
+
+		if header.int(1) != parquetDataPage {
+			return fmt.Errorf("%w: page type %d", errUnsupportedParquet, header.int(1))
+		}
+		dataPage := header.strct(5)
+		if dataPage.int(2) != parquetPlain {
+			return fmt.Errorf("%w: encoding %d", errUnsupportedParquet, dataPage.int(2))
+		}
+		numValues := int(dataPage.int(1))
+		if numValues < 0 || numValues > len(rows)-i {
+			return fmt.Errorf("too many values: %d", numValues)
+		}
+
+		defined := make([]bool, numValues)
+		if column.repetitionType() == parquetOptional {
+			if dataPage.int(3) != parquetRLE {
+				return fmt.Errorf("%w: definition level encoding %d", errUnsupportedParquet, dataPage.int(3))
+			}
+			if len(page) < 4 || uint64(binary.LittleEndian.Uint32(page)) > uint64(len(page)-4) {
+				return errThriftTruncated
+			}
+			size := int(binary.LittleEndian.Uint32(page))
+			if err := decodeLevels(page[4:4+size], defined); err != nil {
+				return err
+			}
+			page = page[4+size:]
+		} else {
+			for j := range defined {
+				defined[j] = true
+			}
+		}
+
+		if err := decodePlain(page, rows[i:i+numValues], defined, column); err != nil {
+			return err
+		}
+		i += numValues
+	}
+	return nil
+}
+
+// decodeLevels decodes definition levels of 1 bit width in the RLE/bit-packing hybrid encoding
+// into whether the values are defined.
+func decodeLevels(data []byte, defined []bool) error {
+	reader := bytes.NewReader(data)
+	for i := 0; i < len(defined); {
+		header, err := binary.ReadUvarint(reader)
+		if err != nil {
+			return errThriftTruncated
+		}
+		if header&1 == 0 {
+			value, err := reader.ReadByte()
+			if err != nil {
+				return errThriftTruncated
+			}
+			for run := header >> 1; run > 0 && i < len(defined); run-- {
+				defined[i] = value != 0
+				i++
+			}
+			continue
+		}
+		for groups := header >> 1; groups > 0; groups-- {
+			bits, err := reader.ReadByte()
+			if err != nil {
+				return errThriftTruncated
+			}
+			for bit := 0; bit < 8 && i < len(defined); bit++ {
+				defined[i] = bits>>bit&1 != 0
+				i++
+			}
+		}
+	}
+	return nil
+}
+
+// decodePlain decodes the values of the column in PLAIN encoding into the rows whose values are defined.
+func decodePlain(page []byte, rows []row, defined []bool, column parquetFileColumn) error {
+	for i := range rows {
+		if !defined[i] {
+			continue
+		}
+		if column.str == nil {
+			if len(page) < 8 {
+				return errThriftTruncated
+			}
+			v := int64(binary.LittleEndian.Uint64(page))
+			page = page[8:]
+			if column.num != nil {
+				*column.num(&rows[i]) = v
+			} else {
+				perSecond := int64(time.Second / column.unit)
+				*column.time(&rows[i]) = formatTime(time.Unix(v/perSecond, v%perSecond*int64(column.unit)).UTC())
+			}
+			continue
+		}
+		if len(page) < 4 {
+			return errThriftTruncated
+		}
+		size := binary.LittleEndian.Uint32(page)
+		page = page[4:]
+		if uint64(size) > uint64(len(page)) {
+			return errThriftTruncated
+		}
+		*column.str(&rows[i]) = string(page[:size])
+		page = page[size:]
+	}
+	return nil
+}
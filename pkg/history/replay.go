@@ -0,0 +1,174 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/client"
+	"github.com/kaitoy/zundoko-go-client/pkg/model"
+	"github.com/kaitoy/zundoko-go-client/pkg/runner"
+)
+
+// Detection represents the result of playing a history back through the detection logic of runner.
+type Detection struct {
+	// Sessions are the results of the sessions that made a Kiyoshi.
+	Sessions []*runner.Result
+
+	// Remaining is the number of the last Zundokos that didn't complete the pattern.
+	Remaining int
+}
+
+// Detect plays the words of the Zundokos in the history back through Runners, as if they were said
+// by the Runners, to find where Kiyoshies are to be made.
+// Each session of a Runner starts with a new history in memory and ends with a Kiyoshi, and the next one
+// continues with the following words until they run out.
+// The Runners post the words as fast as possible without output by default, which can be changed by opts.
+func Detect(ctx context.Context, h *History, opts ...runner.Option) (*Detection, error) {
+	source := &historyWordSource{zundokos: h.Zundokos}
+	opts = append([]runner.Option{
+		runner.WithPresenter(runner.NewSilentPresenter()),
+		runner.WithPacer(runner.NewFastPacer()),
+	}, opts...)
+	opts = append(opts, runner.WithWordSource(source))
+
+	detection := &Detection{}
+	for {
+		result, err := runner.NewRunner(NewMemoryClient(nil), opts...).Run(ctx)
+		if errors.Is(err, io.EOF) {
+			detection.Remaining = len(result.Zundokos)
+			return detection, nil
+		}
+		if err != nil {
+			return detection, err
+		}
+		detection.Sessions = append(detection.Sessions, result)
+	}
+}
+
+// historyWordSource is a runner.WordSource that returns the words of the Zundokos in order.
+type historyWordSource struct {
+	zundokos []model.Zundoko
+	next     int
+}
+
+func (s *historyWordSource) NextWord(ctx context.Context) (string, error) {
+	if s.next >= len(s.zundokos) {
+		return "", io.EOF
+	}
+	s.next++
+	return s.zundokos[s.next-1].Word, nil
+}
+
+// ReplayConfig represents configuration of Replay.
+type ReplayConfig struct {
+	// Speed scales the intervals between the original SaidAt of the entities.
+	// 1 replays them at the original timing, 2 twice as fast, and so on.
+	// 0 or a negative value posts them without waiting.
+	Speed float64
+
+	// KeepSaidAt makes the entities posted with their original SaidAt instead of the time they are posted.
+	KeepSaidAt bool
+
+	// Clock is used to wait between posts. Defaults to the real one.
+	Clock runner.Clock
+}
+
+// Replay re-posts the Zundokos and Kiyoshies in the history to Zundoko Server in the order they were said,
+// waiting for the intervals between them scaled by the Speed.
+// They are posted with their original ids, so that replaying a history again doesn't duplicate them.
+// It returns the number of the posted entities even with an error.
+func Replay(ctx context.Context, cl client.Client, h *History, config ReplayConfig) (int, error) {
+	clock := config.Clock
+	if clock == nil {
+		clock = runner.NewRealClock()
+	}
+
+	events := eventsOf(h)
+	start := clock.Now()
+	for i, e := range events {
+		if err := waitFor(ctx, clock, start.Add(e.offset(events[0], config.Speed))); err != nil {
+			return i, err
+		}
+
+		saidAt := e.saidAt()
+		if !config.KeepSaidAt {
+			saidAt = clock.Now()
+		}
+		var err error
+		if e.zundoko != nil {
+			zundoko := model.Zundoko{Id: e.zundoko.Id, SaidAt: saidAt, Word: e.zundoko.Word}
+			err = cl.PostZundoko(ctx, &zundoko)
+		} else {
+			kiyoshi := model.Kiyoshi{Id: e.kiyoshi.Id, SaidAt: saidAt, MadeBy: e.kiyoshi.MadeBy}
+			err = cl.PostKiyoshi(ctx, &kiyoshi)
+		}
+		if err != nil {
+			return i, fmt.Errorf("failed to replay the %d-th entity %s: %w", i+1, e.id(), err)
+		}
+	}
+	return len(events), nil
+}
+
+// waitFor waits until the time by the clock, or until ctx is done.
+func waitFor(ctx context.Context, clock runner.Clock, until time.Time) error {
+	wait := until.Sub(clock.Now())
+	if wait <= 0 {
+		return ctx.Err()
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-clock.After(wait):
+		return nil
+	}
+}
+
+// event is either a Zundoko or a Kiyoshi in a history.
+type event struct {
+	zundoko *model.Zundoko
+	kiyoshi *model.Kiyoshi
+}
+
+// eventsOf merges the Zundokos and Kiyoshies in the history in the order they were said.
+// A Zundoko said at the same time as a Kiyoshi comes first, as the Kiyoshi follows the Doko.
+func eventsOf(h *History) []event {
+	events := make([]event, 0, len(h.Zundokos)+len(h.Kiyoshies))
+	zi, ki := 0, 0
+	for zi < len(h.Zundokos) || ki < len(h.Kiyoshies) {
+		if ki == len(h.Kiyoshies) ||
+			(zi < len(h.Zundokos) && !h.Kiyoshies[ki].SaidAt.Before(h.Zundokos[zi].SaidAt)) {
+			events = append(events, event{zundoko: &h.Zundokos[zi]})
+			zi++
+			continue
+		}
+		events = append(events, event{kiyoshi: &h.Kiyoshies[ki]})
+		ki++
+	}
+	return events
+}
+
+func (e event) saidAt() time.Time {
+	if e.zundoko != nil {
+		return e.zundoko.SaidAt
+	}
+	return e.kiyoshi.SaidAt
+}
+
+func (e event) id() string {
+	if e.zundoko != nil {
+		return "Zundoko " + e.zundoko.Id
+	}
+	return "Kiyoshi " + e.kiyoshi.Id
+}
+
+// offset returns the time from the first event to the event scaled by the speed.
+// It's zero if the speed isn't positive or either of them doesn't have SaidAt.
+func (e event) offset(first event, speed float64) time.Duration {
+	if speed <= 0 || e.saidAt().IsZero() || first.saidAt().IsZero() {
+		return 0
+	}
+	return time.Duration(float64(e.saidAt().Sub(first.saidAt())) / speed)
+}
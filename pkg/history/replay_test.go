@@ -0,0 +1,113 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/client"
+	"github.com/kaitoy/zundoko-go-client/pkg/model"
+	"github.com/kaitoy/zundoko-go-client/pkg/runner"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Replay", func() {
+	var (
+		start time.Time
+	)
+
+	BeforeEach(func() {
+		start = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	})
+
+	Describe("Detect()", func() {
+		It("finds where Kiyoshies are to be made.", func() {
+			h := newHistory(
+				start,
+				"Zun", "Doko", "Zun", "Zun", "Zun", "Zun", "Doko",
+				"Zun", "Zun", "Zun", "Zun", "Doko",
+				"Zun", "Zun",
+			)
+
+			detection, err := Detect(context.Background(), h)
+
+			Expect(err).To(BeNil())
+			Expect(detection.Sessions).To(HaveLen(2))
+			Expect(detection.Sessions[0].Zundokos).To(HaveLen(7))
+			Expect(detection.Sessions[0].Kiyoshi).NotTo(BeNil())
+			Expect(detection.Sessions[1].Zundokos).To(HaveLen(5))
+			Expect(detection.Remaining).To(Equal(2))
+		})
+
+		It("notifies the given Observers.", func() {
+			words := 0
+
+			_, err := Detect(
+				context.Background(),
+				newHistory(start, "Zun", "Zun", "Zun", "Zun", "Doko"),
+				runner.WithObserver(runner.ObserverFuncs{
+					Word: func(*model.Zundoko, int) { words++ },
+				}),
+			)
+
+			Expect(err).To(BeNil())
+			Expect(words).To(Equal(5))
+		})
+	})
+
+	Describe("Replay()", func() {
+		It("re-posts entities at the scaled timing.", func() {
+			h := newHistory(start, "Zun", "Doko")
+			clock := runner.NewFakeClock(start.Add(time.Hour))
+			target := NewMemoryClient(nil)
+			done := make(chan int)
+
+			go func() {
+				defer GinkgoRecover()
+				posted, err := Replay(context.Background(), target, h, ReplayConfig{Speed: 2, Clock: clock})
+				Expect(err).To(BeNil())
+				done <- posted
+			}()
+
+			Eventually(clock.Waiters).Should(Equal(1))
+			zundokos, _ := target.GetZundokos(context.Background())
+			Expect(zundokos).To(HaveLen(1))
+			Expect(zundokos[0].SaidAt).To(Equal(start.Add(time.Hour)))
+
+			clock.Advance(500 * time.Millisecond)
+			Eventually(clock.Waiters).Should(Equal(1))
+			zundokos, _ = target.GetZundokos(context.Background())
+			Expect(zundokos).To(HaveLen(2))
+			Expect(zundokos[1].Id).To(Equal("zdb"))
+
+			clock.Advance(500 * time.Millisecond)
+			Eventually(done).Should(Receive(Equal(3)))
+			kiyoshies, _ := target.GetKiyoshies(context.Background())
+			Expect(kiyoshies).To(HaveLen(1))
+			Expect(kiyoshies[0].MadeBy).To(Equal("kaitoy@example.com"))
+		})
+
+		It("re-posts entities with the original SaidAt without waiting if configured.", func() {
+			h := newHistory(start, "Zun", "Doko")
+			target := NewMemoryClient(nil)
+
+			posted, err := Replay(context.Background(), target, h, ReplayConfig{KeepSaidAt: true})
+
+			Expect(err).To(BeNil())
+			Expect(posted).To(Equal(3))
+			replayed, _ := Fetch(context.Background(), target)
+			Expect(replayed).To(Equal(h))
+		})
+
+		It("returns the error of a post with the number of posted entities.", func() {
+			h := newHistory(start, "Zun", "Doko")
+			target := NewMemoryClient(&History{Zundokos: []model.Zundoko{{Id: "zdb", Word: "Zun"}}})
+
+			posted, err := Replay(context.Background(), target, h, ReplayConfig{})
+
+			Expect(errors.Is(err, client.ErrConflict)).To(BeTrue())
+			Expect(posted).To(Equal(1))
+		})
+	})
+})
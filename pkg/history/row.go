@@ -0,0 +1,93 @@
+package history
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/model"
+)
+
+// Types of rows in CSV and Parquet formats.
+const (
+	rowTypeZundoko = "zundoko"
+	rowTypeKiyoshi = "kiyoshi"
+)
+
+// columns are the names of the columns of rows in CSV and Parquet formats.
+var columns = []string{"type", "id", "sequence", "saidAt", "word", "madeBy"}
+
+// row is a flattened Zundoko or Kiyoshi, which is a row in CSV and Parquet formats.
+// Fields a Zundoko or Kiyoshi doesn't have are left empty.
+type row struct {
+	Type     string
+	Id       string
+	Sequence int64
+	SaidAt   string
+	Word     string
+	MadeBy   string
+}
+
+// rowsOf flattens the history into rows of the Zundokos followed by the Kiyoshies.
+func rowsOf(h *History) []row {
+	rows := make([]row, 0, len(h.Zundokos)+len(h.Kiyoshies))
+	for _, zd := range h.Zundokos {
+		rows = append(rows, row{
+			Type:     rowTypeZundoko,
+			Id:       zd.Id,
+			Sequence: zd.Sequence,
+			SaidAt:   formatTime(zd.SaidAt),
+			Word:     zd.Word,
+		})
+	}
+	for _, k := range h.Kiyoshies {
+		rows = append(rows, row{
+			Type:   rowTypeKiyoshi,
+			Id:     k.Id,
+			SaidAt: formatTime(k.SaidAt),
+			MadeBy: k.MadeBy,
+		})
+	}
+	return rows
+}
+
+// add adds the Zundoko or Kiyoshi of the row to the history.
+func (h *History) add(r row) error {
+	saidAt, err := parseTime(r.SaidAt)
+	if err != nil {
+		return fmt.Errorf("invalid saidAt of %s %s: %w", r.Type, r.Id, err)
+	}
+	switch r.Type {
+	case rowTypeZundoko:
+		h.Zundokos = append(h.Zundokos, model.Zundoko{
+			Id:       r.Id,
+			Sequence: r.Sequence,
+			SaidAt:   saidAt,
+			Word:     r.Word,
+		})
+	case rowTypeKiyoshi:
+		h.Kiyoshies = append(h.Kiyoshies, model.Kiyoshi{
+			Id:     r.Id,
+			SaidAt: saidAt,
+			MadeBy: r.MadeBy,
+		})
+	default:
+		return fmt.Errorf("unknown row type: %s", r.Type)
+	}
+	return nil
+}
+
+// formatTime formats t in the same way as JSON, or returns an empty string for the zero time.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// parseTime parses a time formatted by formatTime.
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339Nano, s)
+}
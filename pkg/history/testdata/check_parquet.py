@@ -0,0 +1,40 @@
+#!/usr/bin/env python3
+"""Checks that pyarrow reads a Parquet file written by the built-in Parquet writer as expected.
+
+The file is the one given as the argument, or history.parquet, the golden file, by default.
+The history tests run it on a file they write if pyarrow is installed, which `make test` does:
+
+    pip install pyarrow
+    python3 pkg/history/testdata/check_parquet.py [file]
+"""
+
+import os
+import sys
+
+import pyarrow as pa
+import pyarrow.parquet as pq
+
+if len(sys.argv) > 1:
+    path = sys.argv[1]
+else:
+    path = os.path.join(os.path.dirname(os.path.abspath(__file__)), "history.parquet")
+table = pq.read_table(path)
+
+for name in ["type", "id", "word", "madeBy"]:
+    field = table.schema.field(name)
+    assert field.type == pa.string() and not field.nullable, field
+field = table.schema.field("sequence")
+assert field.type == pa.int64() and not field.nullable, field
+field = table.schema.field("saidAt")
+assert pa.types.is_timestamp(field.type) and field.type.unit == "ns" and field.nullable, field
+assert field.type.tz in ("UTC", "+00:00"), field
+
+# 2021-01-01T00:00:00.123456789Z and 2 seconds after it in nanoseconds.
+start = 1609459200123456789
+assert table.column("type").to_pylist() == ["zundoko", "zundoko", "kiyoshi"]
+assert table.column("id").to_pylist() == ["zda", "zdb", "k1"]
+assert table.column("sequence").to_pylist() == [1, 2, 0]
+assert table.column("saidAt").cast(pa.int64()).to_pylist() == [start, None, start + 2_000_000_000]
+assert table.column("word").to_pylist() == ["Zun", "Doko", ""]
+assert table.column("madeBy").to_pylist() == ["", "", "kaitoy@example.com"]
+print("OK")
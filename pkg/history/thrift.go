@@ -0,0 +1,265 @@
+package history
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Types of values in Thrift compact protocol, which Parquet uses for its metadata.
+const (
+	thriftTrue   = 1
+	thriftFalse  = 2
+	thriftByte   = 3
+	thriftI16    = 4
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftDouble = 7
+	thriftBinary = 8
+	thriftList   = 9
+	thriftSet    = 10
+	thriftMap    = 11
+	thriftStruct = 12
+)
+
+// thriftWriter writes Thrift structs in compact protocol.
+// Fields of a struct must be written in ascending order of their ids.
+type thriftWriter struct {
+	buf    bytes.Buffer
+	lastID int16
+}
+
+func (w *thriftWriter) fieldHeader(id int16, typ byte) {
+	if delta := id - w.lastID; delta > 0 && delta <= 15 {
+		w.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		w.buf.WriteByte(typ)
+		w.varint(int64(id))
+	}
+	w.lastID = id
+}
+
+func (w *thriftWriter) varint(v int64) {
+	w.uvarint(uint64(v<<1) ^ uint64(v>>63))
+}
+
+func (w *thriftWriter) uvarint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	w.buf.Write(b[:binary.PutUvarint(b[:], v)])
+}
+
+func (w *thriftWriter) i32Field(id int16, v int32) {
+	w.fieldHeader(id, thriftI32)
+	w.varint(int64(v))
+}
+
+func (w *thriftWriter) i64Field(id int16, v int64) {
+	w.fieldHeader(id, thriftI64)
+	w.varint(v)
+}
+
+func (w *thriftWriter) boolField(id int16, v bool) {
+	if v {
+		w.fieldHeader(id, thriftTrue)
+	} else {
+		w.fieldHeader(id, thriftFalse)
+	}
+}
+
+func (w *thriftWriter) binaryField(id int16, s string) {
+	w.fieldHeader(id, thriftBinary)
+	w.binary(s)
+}
+
+func (w *thriftWriter) binary(s string) {
+	w.uvarint(uint64(len(s)))
+	w.buf.WriteString(s)
+}
+
+// structField writes a field of a struct whose fields are written by fields.
+func (w *thriftWriter) structField(id int16, fields func()) {
+	w.fieldHeader(id, thriftStruct)
+	w.structValue(fields)
+}
+
+// structValue writes a struct whose fields are written by fields, e.g. as an element of a list.
+func (w *thriftWriter) structValue(fields func()) {
+	lastID := w.lastID
+	w.lastID = 0
+	fields()
+	w.buf.WriteByte(0)
+	w.lastID = lastID
+}
+
+// listField writes the header of a list field of size elements, which must be written next.
+func (w *thriftWriter) listField(id int16, elemType byte, size int) {
+	w.fieldHeader(id, thriftList)
+	if size < 15 {
+		w.buf.WriteByte(byte(size)<<4 | elemType)
+		return
+	}
+	w.buf.WriteByte(0xf0 | elemType)
+	w.uvarint(uint64(size))
+}
+
+// thriftFields is a Thrift struct read in compact protocol, which maps field ids to values.
+// A value is a bool, an int64 for integers, a float64, a []byte for binaries, a []interface{} for lists and sets,
+// or a thriftFields.
+type thriftFields map[int16]interface{}
+
+func (s thriftFields) int(id int16) int64 {
+	v, _ := s[id].(int64)
+	return v
+}
+
+func (s thriftFields) has(id int16) bool {
+	_, ok := s[id]
+	return ok
+}
+
+func (s thriftFields) string(id int16) string {
+	v, _ := s[id].([]byte)
+	return string(v)
+}
+
+func (s thriftFields) list(id int16) []interface{} {
+	v, _ := s[id].([]interface{})
+	return v
+}
+
+func (s thriftFields) structs(id int16) []thriftFields {
+	var structs []thriftFields
+	for _, v := range s.list(id) {
+		if elem, ok := v.(thriftFields); ok {
+			structs = append(structs, elem)
+		}
+	}
+	return structs
+}
+
+func (s thriftFields) strct(id int16) thriftFields {
+	v, _ := s[id].(thriftFields)
+	return v
+}
+
+var errThriftTruncated = errors.New("truncated Thrift data")
+
+// thriftReader reads Thrift structs in compact protocol.
+type thriftReader struct {
+	r *bytes.Reader
+}
+
+func (r thriftReader) readStruct() (thriftFields, error) {
+	s := thriftFields{}
+	var lastID int16
+	for {
+		b, err := r.r.ReadByte()
+		if err != nil {
+			return nil, errThriftTruncated
+		}
+		if b == 0 {
+			return s, nil
+		}
+
+		id := lastID + int16(b>>4)
+		if b>>4 == 0 {
+			v, err := r.varint()
+			if err != nil {
+				return nil, err
+			}
+			id = int16(v)
+		}
+		lastID = id
+
+		if s[id], err = r.readValue(b & 0x0f); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (r thriftReader) readValue(typ byte) (interface{}, error) {
+	switch typ {
+	case thriftTrue:
+		return true, nil
+	case thriftFalse:
+		return false, nil
+	case thriftByte:
+		b, err := r.r.ReadByte()
+		if err != nil {
+			return nil, errThriftTruncated
+		}
+		return int64(int8(b)), nil
+	case thriftI16, thriftI32, thriftI64:
+		return r.varint()
+	case thriftDouble:
+		var b [8]byte
+		if _, err := r.r.Read(b[:]); err != nil {
+			return nil, errThriftTruncated
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(b[:])), nil
+	case thriftBinary:
+		size, err := r.size()
+		if err != nil {
+			return nil, err
+		}
+		b := make([]byte, size)
+		if _, err := r.r.Read(b); err != nil && size > 0 {
+			return nil, errThriftTruncated
+		}
+		return b, nil
+	case thriftList, thriftSet:
+		return r.readList()
+	case thriftStruct:
+		return r.readStruct()
+	default:
+		return nil, fmt.Errorf("unsupported Thrift type: %d", typ)
+	}
+}
+
+func (r thriftReader) readList() ([]interface{}, error) {
+	b, err := r.r.ReadByte()
+	if err != nil {
+		return nil, errThriftTruncated
+	}
+	size, elemType := int(b>>4), b&0x0f
+	if size == 15 {
+		if size, err = r.size(); err != nil {
+			return nil, err
+		}
+	}
+
+	list := make([]interface{}, 0, size)
+	for i := 0; i < size; i++ {
+		var elem interface{}
+		if elemType == thriftTrue || elemType == thriftFalse {
+			b, err := r.r.ReadByte()
+			if err != nil {
+				return nil, errThriftTruncated
+			}
+			elem = b == thriftTrue
+		} else if elem, err = r.readValue(elemType); err != nil {
+			return nil, err
+		}
+		list = append(list, elem)
+	}
+	return list, nil
+}
+
+func (r thriftReader) varint() (int64, error) {
+	u, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return 0, errThriftTruncated
+	}
+	return int64(u>>1) ^ -int64(u&1), nil
+}
+
+// size reads a size of a binary or list, which must not exceed the remaining data.
+func (r thriftReader) size() (int, error) {
+	u, err := binary.ReadUvarint(r.r)
+	if err != nil || u > uint64(r.r.Len()) {
+		return 0, errThriftTruncated
+	}
+	return int(u), nil
+}
@@ -0,0 +1,2 @@
+// Package load provides a load tester that plays Zundoko Kiyoshi by many concurrent virtual players.
+package load
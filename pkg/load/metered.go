@@ -0,0 +1,69 @@
+package load
+
+import (
+	"context"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/client"
+	"github.com/kaitoy/zundoko-go-client/pkg/model"
+)
+
+// meteredClient is a Client that throttles API calls and records their latencies.
+type meteredClient struct {
+	cl      client.Client
+	limiter client.Limiter
+	rec     *recorder
+}
+
+func (c *meteredClient) GetZundokos(ctx context.Context) (zundokos []model.Zundoko, err error) {
+	err = c.call(ctx, client.OperationGetZundokos, func() error {
+		zundokos, err = c.cl.GetZundokos(ctx)
+		return err
+	})
+	return zundokos, err
+}
+
+func (c *meteredClient) PostZundoko(ctx context.Context, zundoko *model.Zundoko) error {
+	return c.call(ctx, client.OperationPostZundoko, func() error {
+		return c.cl.PostZundoko(ctx, zundoko)
+	})
+}
+
+func (c *meteredClient) PostKiyoshi(ctx context.Context, kiyoshi *model.Kiyoshi) error {
+	return c.call(ctx, client.OperationPostKiyoshi, func() error {
+		return c.cl.PostKiyoshi(ctx, kiyoshi)
+	})
+}
+
+func (c *meteredClient) GetKiyoshies(ctx context.Context) (kiyoshies []model.Kiyoshi, err error) {
+	err = c.call(ctx, client.OperationGetKiyoshies, func() error {
+		kiyoshies, err = c.cl.GetKiyoshies(ctx)
+		return err
+	})
+	return kiyoshies, err
+}
+
+// ServerDate implements client.DateReporter if the wrapped Client does.
+func (c *meteredClient) ServerDate() (date, receivedAt time.Time, ok bool) {
+	if reporter, isReporter := c.cl.(client.DateReporter); isReporter {
+		return reporter.ServerDate()
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// call waits for the limiter and calls the API, recording its latency and result.
+// The latency doesn't include the time waited for the limiter.
+func (c *meteredClient) call(ctx context.Context, operation client.Operation, api func() error) error {
+	release, err := c.limiter.Acquire(ctx, operation)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	start := time.Now()
+	err = api()
+	if ctx.Err() == nil {
+		c.rec.recordRequest(operation, time.Since(start), err)
+	}
+	return err
+}
@@ -0,0 +1,187 @@
+package load
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/client"
+	"github.com/kaitoy/zundoko-go-client/pkg/runner"
+	"github.com/kaitoy/zundoko-go-client/pkg/stats"
+)
+
+// Report represents the result of a load test.
+type Report struct {
+	// Players is the number of the virtual players.
+	Players int `json:"players"`
+
+	// Elapsed is the time the load test took.
+	Elapsed time.Duration `json:"elapsed"`
+
+	// Requests is the number of API requests.
+	Requests int `json:"requests"`
+
+	// Errors is the number of failed API requests. A Kiyoshi rejected as a duplicate of another player's one
+	// is not counted, since the server responded as the spec defines.
+	Errors int `json:"errors"`
+
+	// ErrorRate is the ratio of failed API requests.
+	ErrorRate float64 `json:"errorRate"`
+
+	// Throughput is the number of API requests per second.
+	Throughput float64 `json:"throughput"`
+
+	// Latencies is the latency distribution of API requests for each operation.
+	Latencies map[client.Operation]stats.Distribution `json:"latencies"`
+
+	// Waits is the statistics of time API requests waited for the request rate and concurrency limits.
+	Waits map[client.Operation]client.WaitStats `json:"waits"`
+
+	// Sessions is the number of finished Zundoko Kiyoshi sessions.
+	Sessions int `json:"sessions"`
+
+	// FailedSessions is the number of Zundoko Kiyoshi sessions that ended with an error.
+	FailedSessions int `json:"failedSessions"`
+
+	// YieldedSessions is the number of Zundoko Kiyoshi sessions that ended without a Kiyoshi, yielding it
+	// to another player who made one for the same pattern first. They are not failed.
+	YieldedSessions int `json:"yieldedSessions"`
+
+	// Zundokos is the number of Zundokos posted in the finished sessions.
+	Zundokos int `json:"zundokos"`
+
+	// TimeToKiyoshi is the distribution of time taken by successful sessions to make a Kiyoshi.
+	TimeToKiyoshi stats.Distribution `json:"timeToKiyoshi"`
+}
+
+// WriteText writes the report in a human-readable text format.
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Players:\t%d\n", r.Players)
+	fmt.Fprintf(tw, "Elapsed:\t%s\n", r.Elapsed.Round(time.Millisecond))
+	fmt.Fprintf(tw, "Requests:\t%d\n", r.Requests)
+	fmt.Fprintf(tw, "Errors:\t%d (%.2f%%)\n", r.Errors, r.ErrorRate*100)
+	fmt.Fprintf(tw, "Throughput:\t%.2f req/s\n", r.Throughput)
+	fmt.Fprintf(tw, "Sessions:\t%d (failed: %d, yielded: %d)\n", r.Sessions, r.FailedSessions, r.YieldedSessions)
+	fmt.Fprintf(tw, "Zundokos:\t%d\n", r.Zundokos)
+	fmt.Fprintln(tw)
+
+	fmt.Fprintln(tw, "\tcount\tmin\tmean\tp50\tp90\tp95\tp99\tmax")
+	operations := make([]client.Operation, 0, len(r.Latencies))
+	for op := range r.Latencies {
+		operations = append(operations, op)
+	}
+	sort.Slice(operations, func(i, j int) bool { return operations[i] < operations[j] })
+	for _, op := range operations {
+		writeDistribution(tw, string(op), r.Latencies[op])
+	}
+	writeDistribution(tw, "TimeToKiyoshi", r.TimeToKiyoshi)
+
+	if len(r.Waits) > 0 {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "Waits for limits\tcalls\tmean\tmax")
+		for _, op := range operations {
+			if waits, ok := r.Waits[op]; ok {
+				fmt.Fprintf(
+					tw,
+					"%s\t%d\t%s\t%s\n",
+					op,
+					waits.Calls,
+					waits.Mean().Round(time.Microsecond),
+					waits.Max.Round(time.Microsecond),
+				)
+			}
+		}
+	}
+
+	return tw.Flush()
+}
+
+func writeDistribution(w io.Writer, name string, d stats.Distribution) {
+	fmt.Fprintf(w, "%s\t%d", name, d.Count)
+	for _, v := range d.Durations(time.Microsecond) {
+		fmt.Fprintf(w, "\t%s", v)
+	}
+	fmt.Fprintln(w)
+}
+
+// WriteJSON writes the report in JSON. Durations are in nanoseconds.
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// recorder records results of API requests and sessions concurrently.
+type recorder struct {
+	mu              sync.Mutex
+	latencies       map[client.Operation][]time.Duration
+	errors          int
+	sessionDuration []time.Duration
+	failedSessions  int
+	yieldedSessions int
+	zundokos        int
+}
+
+func newRecorder() *recorder {
+	return &recorder{latencies: make(map[client.Operation][]time.Duration)}
+}
+
+func (r *recorder) recordRequest(operation client.Operation, latency time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.latencies[operation] = append(r.latencies[operation], latency)
+	if err != nil && !(operation == client.OperationPostKiyoshi && errors.Is(err, client.ErrConflict)) {
+		r.errors++
+	}
+}
+
+func (r *recorder) recordSession(result *runner.Result, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.zundokos += len(result.Zundokos)
+	if err != nil {
+		r.failedSessions++
+		return
+	}
+	if result.Duplicate {
+		r.yieldedSessions++
+		return
+	}
+	r.sessionDuration = append(r.sessionDuration, result.Elapsed)
+}
+
+func (r *recorder) report(players int, elapsed time.Duration) *Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := &Report{
+		Players:         players,
+		Elapsed:         elapsed,
+		Errors:          r.errors,
+		Latencies:       make(map[client.Operation]stats.Distribution),
+		Sessions:        len(r.sessionDuration) + r.failedSessions + r.yieldedSessions,
+		FailedSessions:  r.failedSessions,
+		YieldedSessions: r.yieldedSessions,
+		Zundokos:        r.zundokos,
+		TimeToKiyoshi:   stats.NewDistribution(r.sessionDuration),
+	}
+	for op, latencies := range r.latencies {
+		report.Requests += len(latencies)
+		report.Latencies[op] = stats.NewDistribution(latencies)
+	}
+	if report.Requests > 0 {
+		report.ErrorRate = float64(report.Errors) / float64(report.Requests)
+	}
+	if elapsed > 0 {
+		report.Throughput = float64(report.Requests) / elapsed.Seconds()
+	}
+	return report
+}
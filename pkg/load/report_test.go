@@ -0,0 +1,74 @@
+package load
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/client"
+	"github.com/kaitoy/zundoko-go-client/pkg/runner"
+	"github.com/kaitoy/zundoko-go-client/pkg/stats"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Report", func() {
+	Describe("WriteText()", func() {
+		It("writes the report with the distributions.", func() {
+			report := &Report{
+				Players:   2,
+				Requests:  10,
+				Latencies: map[client.Operation]stats.Distribution{client.OperationGetZundokos: {Count: 10}},
+			}
+			buf := new(bytes.Buffer)
+
+			Expect(report.WriteText(buf)).To(Succeed())
+
+			Expect(buf.String()).To(ContainSubstring("Players:"))
+			Expect(buf.String()).To(ContainSubstring(string(client.OperationGetZundokos)))
+			Expect(buf.String()).To(ContainSubstring("TimeToKiyoshi"))
+		})
+	})
+
+	Describe("WriteJSON()", func() {
+		It("writes the report that can be decoded into Report.", func() {
+			report := &Report{
+				Players:       2,
+				Requests:      10,
+				Latencies:     map[client.Operation]stats.Distribution{client.OperationGetZundokos: {Count: 10, P99: time.Second}},
+				TimeToKiyoshi: stats.Distribution{Count: 1, Max: time.Minute},
+			}
+			buf := new(bytes.Buffer)
+
+			Expect(report.WriteJSON(buf)).To(Succeed())
+
+			var decoded Report
+			Expect(json.Unmarshal(buf.Bytes(), &decoded)).To(Succeed())
+			Expect(&decoded).To(Equal(report))
+		})
+	})
+
+	Describe("recorder", func() {
+		It("counts yielded sessions and duplicate Kiyoshies apart from failures.", func() {
+			rec := newRecorder()
+			conflict := fmt.Errorf("POST Kiyoshi API returned a conflict with Kiyoshi other: %w", client.ErrConflict)
+			rec.recordRequest(client.OperationPostKiyoshi, time.Millisecond, conflict)
+			rec.recordRequest(client.OperationPostZundoko, time.Millisecond, conflict)
+			rec.recordSession(&runner.Result{Duplicate: true, Elapsed: time.Second}, nil)
+			rec.recordSession(&runner.Result{Elapsed: time.Minute}, nil)
+			rec.recordSession(&runner.Result{}, errors.New("some error"))
+
+			report := rec.report(1, time.Minute)
+
+			Expect(report.Requests).To(Equal(2))
+			Expect(report.Errors).To(Equal(1))
+			Expect(report.Sessions).To(Equal(3))
+			Expect(report.FailedSessions).To(Equal(1))
+			Expect(report.YieldedSessions).To(Equal(1))
+			Expect(report.TimeToKiyoshi.Count).To(Equal(1))
+			Expect(report.TimeToKiyoshi.Max).To(Equal(time.Minute))
+		})
+	})
+})
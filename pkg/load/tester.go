@@ -0,0 +1,187 @@
+package load
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/client"
+	"github.com/kaitoy/zundoko-go-client/pkg/logging"
+	"github.com/kaitoy/zundoko-go-client/pkg/runner"
+)
+
+// Config represents configuration of a load test.
+type Config struct {
+	// Players is the number of virtual players that run Zundoko Kiyoshi concurrently.
+	Players int
+
+	// RampUp is the time to take to start all the players. They are started at even intervals.
+	RampUp time.Duration
+
+	// Duration is the time limit of the load test. Zero means no limit.
+	Duration time.Duration
+
+	// Iterations is the number of Zundoko Kiyoshi sessions each player runs. Zero means no limit.
+	Iterations int
+
+	// RequestRate is the target rate of API requests per second of all the players. Zero means no limit.
+	RequestRate float64
+
+	// MaxInFlight is the maximum number of concurrent API requests of all the players. Zero means no limit.
+	MaxInFlight int
+
+	// Interval is the interval between Zundokos in a session.
+	Interval time.Duration
+
+	// LocalDetection makes players detect the pattern locally as configured instead of getting Zundokos
+	// before every post, if not nil.
+	LocalDetection *runner.LocalDetection
+
+	// FailureBackoff is the time a player pauses after a failed session, doubled for each consecutive failure
+	// up to MaxFailureBackoff, so that players don't flood a failing server with sessions.
+	// Zero means DefaultFailureBackoff.
+	FailureBackoff time.Duration
+}
+
+const (
+	// DefaultFailureBackoff is the default of Config.FailureBackoff.
+	DefaultFailureBackoff = 100 * time.Millisecond
+
+	// MaxFailureBackoff is the maximum time a player pauses after consecutive failed sessions.
+	MaxFailureBackoff = 30 * time.Second
+)
+
+// circuitOpenPause is the minimum time a player pauses after a session failed fast by an open circuit breaker.
+const circuitOpenPause = time.Second
+
+// Tester runs a load test.
+type Tester interface {
+	// Run runs a load test until the duration elapses, all the players finish their iterations,
+	// or the given context is done, and returns the report of it.
+	Run(ctx context.Context) (*Report, error)
+}
+
+// NewTester creates a Tester instance which makes players share the given Client.
+func NewTester(cl client.Client, config Config) Tester {
+	return &tester{cl, config, newSilentRunner, runner.NewRealClock()}
+}
+
+// newSilentRunner creates a Runner with the options that doesn't present words, which would flood the output.
+func newSilentRunner(cl client.Client, opts ...runner.Option) runner.Runner {
+	return runner.NewRunner(cl, append([]runner.Option{runner.WithPresenter(runner.NewSilentPresenter())}, opts...)...)
+}
+
+type tester struct {
+	cl        client.Client
+	config    Config
+	newRunner func(cl client.Client, opts ...runner.Option) runner.Runner
+	clock     runner.Clock
+}
+
+func (t *tester) Run(ctx context.Context) (*Report, error) {
+	if t.config.Players <= 0 {
+		return nil, fmt.Errorf("the number of players must be positive: %d", t.config.Players)
+	}
+	if t.config.Duration <= 0 && t.config.Iterations <= 0 {
+		return nil, errors.New("either duration or iterations must be specified")
+	}
+
+	if t.config.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.config.Duration)
+		defer cancel()
+	}
+
+	limiter := client.NewLimiter(client.Limits{
+		client.OperationAll: {Rate: t.config.RequestRate, MaxInFlight: t.config.MaxInFlight},
+	})
+	rec := newRecorder()
+	cl := &meteredClient{t.cl, limiter, rec}
+
+	logging.GetLogger().Infow(
+		"Start a load test.",
+		"players", t.config.Players,
+		"rampUp", t.config.RampUp,
+		"duration", t.config.Duration,
+		"iterations", t.config.Iterations,
+		"requestRate", t.config.RequestRate,
+		"maxInFlight", t.config.MaxInFlight,
+	)
+
+	start := t.clock.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < t.config.Players; i++ {
+		delay := t.config.RampUp * time.Duration(i) / time.Duration(t.config.Players)
+		wg.Add(1)
+		go func(player int) {
+			defer wg.Done()
+			t.play(ctx, player, delay, cl, rec)
+		}(i)
+	}
+	wg.Wait()
+
+	report := rec.report(t.config.Players, t.clock.Now().Sub(start))
+	report.Waits = limiter.Stats()
+	return report, nil
+}
+
+// play runs sessions of a player after the delay until the iterations finish or the context is done.
+// It pauses after a failed session as configured by FailureBackoff. A session that yielded its Kiyoshi
+// to another player is not failed.
+func (t *tester) play(
+	ctx context.Context,
+	player int,
+	delay time.Duration,
+	cl client.Client,
+	rec *recorder,
+) {
+	select {
+	case <-ctx.Done():
+		return
+	case <-t.clock.After(delay):
+	}
+
+	backoff := t.config.FailureBackoff
+	if backoff <= 0 {
+		backoff = DefaultFailureBackoff
+	}
+	// The players share the history, so one yields a Kiyoshi to another who made it for the pattern first.
+	opts := []runner.Option{
+		runner.WithPacer(runner.NewFixedPacer(t.config.Interval)),
+		runner.WithConsensus(runner.Consensus{OnDuplicate: runner.DuplicateYield}),
+	}
+	if t.config.LocalDetection != nil {
+		opts = append(opts, runner.WithLocalDetection(*t.config.LocalDetection))
+	}
+	r := t.newRunner(cl, opts...)
+	failures := 0
+	for i := 0; t.config.Iterations <= 0 || i < t.config.Iterations; i++ {
+		result, err := r.Run(ctx)
+		if ctx.Err() != nil {
+			// The session was interrupted by the end of the load test.
+			return
+		}
+		rec.recordSession(result, err)
+		if err == nil {
+			failures = 0
+			continue
+		}
+
+		failures++
+		logging.GetLogger().Warnw("A session failed.", "player", player, "consecutiveFailures", failures, "err", err)
+		if t.config.Iterations > 0 && i+1 >= t.config.Iterations {
+			return
+		}
+		pause := runner.Backoff(backoff, MaxFailureBackoff, failures)
+		if errors.Is(err, client.ErrCircuitOpen) && pause < circuitOpenPause {
+			pause = circuitOpenPause
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.clock.After(pause):
+		}
+	}
+}
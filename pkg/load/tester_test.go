@@ -0,0 +1,233 @@
+package load
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/kaitoy/zundoko-go-client/mock/pkg/mock_client"
+	"github.com/kaitoy/zundoko-go-client/mock/pkg/mock_runner"
+	"github.com/kaitoy/zundoko-go-client/pkg/client"
+	"github.com/kaitoy/zundoko-go-client/pkg/history"
+	"github.com/kaitoy/zundoko-go-client/pkg/model"
+	"github.com/kaitoy/zundoko-go-client/pkg/runner"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tester", func() {
+	var (
+		mockCtrl   *gomock.Controller
+		mockClient *mock_client.MockClient
+		mockRunner *mock_runner.MockRunner
+		mu         sync.Mutex
+		players    []client.Client
+		config     Config
+	)
+
+	newTestee := func() Tester {
+		return &tester{
+			mockClient,
+			config,
+			func(cl client.Client, _ ...runner.Option) runner.Runner {
+				mu.Lock()
+				defer mu.Unlock()
+				players = append(players, cl)
+				return mockRunner
+			},
+			runner.NewRealClock(),
+		}
+	}
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = mock_client.NewMockClient(mockCtrl)
+		mockRunner = mock_runner.NewMockRunner(mockCtrl)
+		players = nil
+		config = Config{Players: 3, Iterations: 2}
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	Describe("NewTester()", func() {
+		It("works.", func() {
+			Expect(NewTester(mockClient, config)).NotTo(BeNil())
+		})
+	})
+
+	Describe("Run()", func() {
+		Context("when the config is invalid", func() {
+			It("returns an error if players are not positive.", func() {
+				config.Players = 0
+
+				report, err := newTestee().Run(context.Background())
+
+				Expect(report).To(BeNil())
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("returns an error if neither duration nor iterations are specified.", func() {
+				config.Iterations = 0
+
+				report, err := newTestee().Run(context.Background())
+
+				Expect(report).To(BeNil())
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		It("runs sessions of each player for the iterations and reports the results.", func() {
+			mockClient.EXPECT().GetZundokos(gomock.Any()).Return([]model.Zundoko{}, nil).Times(6)
+			mockClient.EXPECT().PostKiyoshi(gomock.Any(), gomock.Any()).Return(nil).Times(5)
+			mockClient.EXPECT().PostKiyoshi(gomock.Any(), gomock.Any()).Return(fmt.Errorf("some error"))
+			mockRunner.EXPECT().Run(gomock.Any()).Times(6).DoAndReturn(
+				func(ctx context.Context) (*runner.Result, error) {
+					mu.Lock()
+					cl := players[0]
+					mu.Unlock()
+					result := &runner.Result{Zundokos: []model.Zundoko{{Word: "Zun"}}, Elapsed: time.Millisecond}
+					if _, err := cl.GetZundokos(ctx); err != nil {
+						return result, err
+					}
+					return result, cl.PostKiyoshi(ctx, &model.Kiyoshi{})
+				},
+			)
+
+			report, err := newTestee().Run(context.Background())
+
+			Expect(err).To(BeNil())
+			Expect(players).To(HaveLen(3))
+			Expect(report.Players).To(Equal(3))
+			Expect(report.Requests).To(Equal(12))
+			Expect(report.Errors).To(Equal(1))
+			Expect(report.ErrorRate).To(BeNumerically("~", 1.0/12))
+			Expect(report.Latencies[client.OperationGetZundokos].Count).To(Equal(6))
+			Expect(report.Latencies[client.OperationPostKiyoshi].Count).To(Equal(6))
+			Expect(report.Sessions).To(Equal(6))
+			Expect(report.FailedSessions).To(Equal(1))
+			Expect(report.Zundokos).To(Equal(6))
+			Expect(report.TimeToKiyoshi.Count).To(Equal(5))
+			Expect(report.TimeToKiyoshi.Max).To(Equal(time.Millisecond))
+		})
+
+		It("stops sessions when the duration elapses, discarding interrupted ones.", func() {
+			config.Iterations = 0
+			config.Duration = 50 * time.Millisecond
+			mockRunner.EXPECT().Run(gomock.Any()).AnyTimes().DoAndReturn(
+				func(ctx context.Context) (*runner.Result, error) {
+					<-ctx.Done()
+					return &runner.Result{}, ctx.Err()
+				},
+			)
+
+			report, err := newTestee().Run(context.Background())
+
+			Expect(err).To(BeNil())
+			Expect(report.Sessions).To(Equal(0))
+			Expect(report.Elapsed).To(BeNumerically(">=", config.Duration))
+		})
+
+		It("pauses a player after failed sessions, doubling the pause for each consecutive failure.", func() {
+			clock := runner.NewFakeClock(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+			config.Players = 1
+			config.Iterations = 4
+			config.FailureBackoff = time.Second
+			sessions := make(chan struct{}, 4)
+			gomock.InOrder(
+				mockRunner.EXPECT().Run(gomock.Any()).Times(2).DoAndReturn(
+					func(ctx context.Context) (*runner.Result, error) {
+						sessions <- struct{}{}
+						return &runner.Result{}, fmt.Errorf("some error")
+					},
+				),
+				mockRunner.EXPECT().Run(gomock.Any()).DoAndReturn(
+					func(ctx context.Context) (*runner.Result, error) {
+						sessions <- struct{}{}
+						return &runner.Result{}, nil
+					},
+				),
+				mockRunner.EXPECT().Run(gomock.Any()).DoAndReturn(
+					func(ctx context.Context) (*runner.Result, error) {
+						sessions <- struct{}{}
+						return &runner.Result{}, fmt.Errorf("some error")
+					},
+				),
+			)
+			testee := newTestee().(*tester)
+			testee.clock = clock
+			done := make(chan *Report)
+			go func() {
+				defer GinkgoRecover()
+				report, err := testee.Run(context.Background())
+				Expect(err).To(BeNil())
+				done <- report
+			}()
+
+			// The first failure pauses the player for 1s.
+			Eventually(sessions).Should(Receive())
+			Eventually(clock.Waiters).Should(Equal(1))
+			clock.Advance(999 * time.Millisecond)
+			Consistently(sessions, 50*time.Millisecond).ShouldNot(Receive())
+			clock.Advance(time.Millisecond)
+
+			// The second consecutive failure pauses it for 2s.
+			Eventually(sessions).Should(Receive())
+			Eventually(clock.Waiters).Should(Equal(1))
+			clock.Advance(1999 * time.Millisecond)
+			Consistently(sessions, 50*time.Millisecond).ShouldNot(Receive())
+			clock.Advance(time.Millisecond)
+
+			// A success resets the backoff, and the player ends without a pause after the last session.
+			Eventually(sessions).Should(Receive())
+			Eventually(sessions).Should(Receive())
+
+			var report *Report
+			Eventually(done).Should(Receive(&report))
+			Expect(clock.Waiters()).To(Equal(0))
+			Expect(report.Sessions).To(Equal(4))
+			Expect(report.FailedSessions).To(Equal(3))
+			Expect(report.Elapsed).To(Equal(3 * time.Second))
+		})
+
+		It("makes players sharing the history yield duplicate Kiyoshies instead of failing.", func() {
+			config.Players = 4
+			config.Iterations = 5
+			testee := NewTester(history.NewMemoryClient(nil), config)
+
+			report, err := testee.Run(context.Background())
+
+			Expect(err).To(BeNil())
+			Expect(report.Sessions).To(Equal(20))
+			Expect(report.FailedSessions).To(Equal(0))
+			Expect(report.Errors).To(Equal(0))
+			Expect(report.TimeToKiyoshi.Count + report.YieldedSessions).To(Equal(20))
+		})
+
+		It("throttles API requests to the request rate.", func() {
+			config.Players = 2
+			config.Iterations = 3
+			config.RequestRate = 100
+			mockClient.EXPECT().GetZundokos(gomock.Any()).Return([]model.Zundoko{}, nil).Times(6)
+			mockRunner.EXPECT().Run(gomock.Any()).Times(6).DoAndReturn(
+				func(ctx context.Context) (*runner.Result, error) {
+					mu.Lock()
+					cl := players[0]
+					mu.Unlock()
+					_, err := cl.GetZundokos(ctx)
+					return &runner.Result{}, err
+				},
+			)
+
+			report, err := newTestee().Run(context.Background())
+
+			Expect(err).To(BeNil())
+			Expect(report.Elapsed).To(BeNumerically(">=", 50*time.Millisecond))
+			Expect(report.Waits[client.OperationGetZundokos].Calls).To(Equal(6))
+			Expect(report.Waits[client.OperationGetZundokos].Total).To(BeNumerically(">", 0))
+		})
+	})
+})
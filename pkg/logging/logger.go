@@ -11,10 +11,11 @@ func init() {
 	sugaredLogger = zap.NewNop().Sugar()
 }
 
-// Init returns the logger.
+// Init initializes the logger to write to the standard error,
+// which keeps the standard output for data such as words, results, and reports.
 func Init(level zapcore.Level) {
 	logConfig := zap.Config{
-		OutputPaths:      []string{"stdout"},
+		OutputPaths:      []string{"stderr"},
 		ErrorOutputPaths: []string{"stderr"},
 		Level:            zap.NewAtomicLevelAt(level),
 		Encoding:         "console",
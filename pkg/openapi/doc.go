@@ -0,0 +1,3 @@
+// Package openapi provides validation of values against the schemas in the OpenAPI spec of Zundoko Server.
+// It supports the subset of OpenAPI used by the spec.
+package openapi
@@ -0,0 +1,154 @@
+package openapi
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/kaitoy/zundoko-go-client/swagger"
+	"gopkg.in/yaml.v2"
+)
+
+// Spec represents an OpenAPI spec.
+type Spec struct {
+	// Paths maps paths to their operations by lower-case HTTP methods.
+	Paths map[string]map[string]*Operation `yaml:"paths"`
+
+	// Components holds the reusable objects of the spec.
+	Components struct {
+		// Schemas maps names of schemas to them.
+		Schemas map[string]*Schema `yaml:"schemas"`
+	} `yaml:"components"`
+}
+
+// Operation represents an API operation.
+type Operation struct {
+	// OperationID is the id of the operation.
+	OperationID string `yaml:"operationId"`
+
+	// Responses maps status codes to the responses of the operation.
+	Responses map[int]*Response `yaml:"responses"`
+}
+
+// Response represents a response of an operation.
+type Response struct {
+	// Description is the description of the response.
+	Description string `yaml:"description"`
+
+	// Content maps media types to the content of the response in them. It's empty if the response has no body.
+	Content map[string]*MediaType `yaml:"content"`
+}
+
+// MediaType represents the content of a request or response body in a media type.
+type MediaType struct {
+	// Schema is the schema of the content.
+	Schema *Schema `yaml:"schema"`
+}
+
+// Schema represents a schema of values.
+type Schema struct {
+	// Ref is the reference to the schema in the components, which replaces this one when the spec is parsed.
+	Ref string `yaml:"$ref"`
+
+	// Type is the type of values: object, array, string, integer, number, or boolean.
+	Type string `yaml:"type"`
+
+	// Format is the format of values of Type, e.g. uuid or date-time.
+	Format string `yaml:"format"`
+
+	// Enum is the allowed values.
+	Enum []interface{} `yaml:"enum"`
+
+	// Minimum is the minimum of numeric values.
+	Minimum *float64 `yaml:"minimum"`
+
+	// Properties maps names of properties of objects to their schemas.
+	Properties map[string]*Schema `yaml:"properties"`
+
+	// Required is the names of properties objects must have.
+	Required []string `yaml:"required"`
+
+	// Items is the schema of items of arrays.
+	Items *Schema `yaml:"items"`
+
+	// ReadOnly tells the property is only sent by the server.
+	ReadOnly bool `yaml:"readOnly"`
+}
+
+// Parse parses an OpenAPI spec in YAML, resolving references to the schemas in the components.
+func Parse(data []byte) (*Spec, error) {
+	var spec Spec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse the OpenAPI spec: %w", err)
+	}
+
+	var resolve func(s **Schema) error
+	resolve = func(s **Schema) error {
+		if *s == nil {
+			return nil
+		}
+		if ref := (*s).Ref; ref != "" {
+			resolved, ok := spec.Components.Schemas[strings.TrimPrefix(ref, "#/components/schemas/")]
+			if !ok || !strings.HasPrefix(ref, "#/components/schemas/") {
+				return fmt.Errorf("unresolvable reference %q in the OpenAPI spec", ref)
+			}
+			*s = resolved
+			return nil
+		}
+		for name := range (*s).Properties {
+			property := (*s).Properties[name]
+			if err := resolve(&property); err != nil {
+				return err
+			}
+			(*s).Properties[name] = property
+		}
+		return resolve(&(*s).Items)
+	}
+	for name := range spec.Components.Schemas {
+		schema := spec.Components.Schemas[name]
+		if err := resolve(&schema); err != nil {
+			return nil, err
+		}
+	}
+	for _, operations := range spec.Paths {
+		for _, op := range operations {
+			for _, response := range op.Responses {
+				for _, content := range response.Content {
+					if err := resolve(&content.Schema); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+	}
+	return &spec, nil
+}
+
+var (
+	zundokoSpec     *Spec
+	zundokoSpecErr  error
+	zundokoSpecOnce sync.Once
+)
+
+// ZundokoSpec returns the parsed spec of Zundoko Server in swagger/swagger.yaml.
+func ZundokoSpec() (*Spec, error) {
+	zundokoSpecOnce.Do(func() {
+		zundokoSpec, zundokoSpecErr = Parse(swagger.YAML)
+	})
+	return zundokoSpec, zundokoSpecErr
+}
+
+// Operation returns the operation of the method and path, or nil if the spec doesn't have it.
+func (s *Spec) Operation(method, path string) *Operation {
+	return s.Paths[path][strings.ToLower(method)]
+}
+
+// Schema returns the schema with the name in the components, or nil if the spec doesn't have it.
+func (s *Spec) Schema(name string) *Schema {
+	return s.Components.Schemas[name]
+}
+
+// Response returns the response of the status code, or nil if the operation doesn't have it.
+func (o *Operation) Response(status int) *Response {
+	return o.Responses[status]
+}
@@ -0,0 +1,37 @@
+package openapi
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ZundokoSpec()", func() {
+	It("parses swagger.yaml resolving references.", func() {
+		spec, err := ZundokoSpec()
+
+		Expect(err).To(BeNil())
+		op := spec.Operation("GET", "/zundokos")
+		Expect(op.OperationID).To(Equal("getZundokos"))
+		schema := op.Response(200).Content["application/json"].Schema
+		Expect(schema.Type).To(Equal("array"))
+		Expect(schema.Items).To(BeIdenticalTo(spec.Schema("Zundoko")))
+		Expect(spec.Operation("POST", "/kiyoshies").Response(201).Content).To(BeEmpty())
+		Expect(spec.Operation("POST", "/kiyoshies").Response(409).Content["application/json"].Schema).
+			To(BeIdenticalTo(spec.Schema("Kiyoshi")))
+	})
+})
+
+var _ = Describe("Parse()", func() {
+	It("returns an error for an unresolvable reference.", func() {
+		_, err := Parse([]byte(`
+components:
+  schemas:
+    A:
+      type: array
+      items:
+        $ref: '#/components/schemas/B'
+`))
+
+		Expect(err).To(MatchError(ContainSubstring("#/components/schemas/B")))
+	})
+})
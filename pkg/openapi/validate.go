@@ -0,0 +1,249 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Violation represents a value violating a schema.
+type Violation struct {
+	// Path is the location of the value in the validated one, e.g. "[0].word". It's empty for the root.
+	Path string `json:"path"`
+
+	// Message describes the violation.
+	Message string `json:"message"`
+}
+
+func (v Violation) String() string {
+	if v.Path == "" {
+		return v.Message
+	}
+	return v.Path + ": " + v.Message
+}
+
+// ValidationError represents violations of a schema, which lists every violation found.
+type ValidationError struct {
+	// Violations is the violations found.
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.String()
+	}
+	return fmt.Sprintf("%d schema violations: %s", len(e.Violations), strings.Join(messages, "; "))
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// ValidateJSON validates the JSON against the schema, and returns a *ValidationError if it has violations.
+func (s *Schema) ValidateJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return &ValidationError{[]Violation{{Message: fmt.Sprintf("invalid JSON: %s", err)}}}
+	}
+	return s.Validate(v)
+}
+
+// Validate validates the value decoded from JSON against the schema, and returns a *ValidationError
+// if it has violations. Numbers in the value may be float64 or json.Number.
+func (s *Schema) Validate(v interface{}) error {
+	var violations []Violation
+	s.validate(v, "", &violations)
+	if len(violations) > 0 {
+		return &ValidationError{violations}
+	}
+	return nil
+}
+
+func (s *Schema) validate(v interface{}, path string, violations *[]Violation) {
+	violate := func(format string, args ...interface{}) {
+		*violations = append(*violations, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.Type != "" && !hasType(v, s.Type) {
+		violate("%s is not %s", describe(v), article(s.Type))
+		return
+	}
+	if len(s.Enum) > 0 && !inEnum(v, s.Enum) {
+		violate("%s is not one of %s", describe(v), describeEnum(s.Enum))
+	}
+
+	switch v := v.(type) {
+	case string:
+		if err := checkFormat(v, s.Format); err != nil {
+			violate("%s is not in %s format: %s", describe(v), s.Format, err)
+		}
+	case json.Number, float64:
+		f := toFloat(v)
+		if (s.Format == "int64" || s.Format == "int32") && !fitsInt(v, s.Format) {
+			violate("%s overflows %s", describe(v), s.Format)
+		}
+		if s.Minimum != nil && f < *s.Minimum {
+			violate("%s is less than the minimum %v", describe(v), *s.Minimum)
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				violate("missing required property %q", name)
+			}
+		}
+		names := make([]string, 0, len(s.Properties))
+		for name := range s.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if value, ok := v[name]; ok {
+				s.Properties[name].validate(value, joinPath(path, name), violations)
+			}
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i), violations)
+			}
+		}
+	}
+}
+
+// hasType tells if the value decoded from JSON is of the schema type.
+func hasType(v interface{}, typ string) bool {
+	switch typ {
+	case "object":
+		_, ok := v.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := v.([]interface{})
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "number":
+		switch v.(type) {
+		case json.Number, float64:
+			return true
+		}
+		return false
+	case "integer":
+		switch v := v.(type) {
+		case json.Number:
+			_, err := v.Int64()
+			return err == nil || !strings.ContainsAny(v.String(), ".eE")
+		case float64:
+			return v == math.Trunc(v)
+		}
+		return false
+	}
+	return true
+}
+
+// checkFormat checks the string is in the format, returning nil for an unknown format.
+func checkFormat(v, format string) error {
+	switch format {
+	case "uuid":
+		if !uuidPattern.MatchString(v) {
+			return fmt.Errorf("not 8-4-4-4-12 hex digits")
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339Nano, v); err != nil {
+			return err
+		}
+	case "date":
+		if _, err := time.Parse("2006-01-02", v); err != nil {
+			return err
+		}
+	case "email":
+		if _, err := mail.ParseAddress(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inEnum tells if the value decoded from JSON is one of the values of the enum.
+func inEnum(v interface{}, enum []interface{}) bool {
+	for _, e := range enum {
+		if fmt.Sprint(e) == fmt.Sprint(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// fitsInt tells if the number fits in the integer format, int64 or int32.
+func fitsInt(v interface{}, format string) bool {
+	var n int64
+	switch v := v.(type) {
+	case json.Number:
+		var err error
+		if n, err = v.Int64(); err != nil {
+			return false
+		}
+	case float64:
+		if v < math.MinInt64 || v >= math.MaxInt64 {
+			return false
+		}
+		n = int64(v)
+	}
+	return format == "int64" || n >= math.MinInt32 && n <= math.MaxInt32
+}
+
+func toFloat(v interface{}) float64 {
+	if n, ok := v.(json.Number); ok {
+		f, _ := n.Float64()
+		return f
+	}
+	return v.(float64)
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// describe describes the value decoded from JSON for messages.
+func describe(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return fmt.Sprintf("%q", v)
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	}
+	return fmt.Sprint(v)
+}
+
+func describeEnum(enum []interface{}) string {
+	values := make([]string, len(enum))
+	for i, e := range enum {
+		values[i] = describe(e)
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
+
+// article prefixes the word with an indefinite article.
+func article(word string) string {
+	if strings.ContainsAny(word[:1], "aeiouAEIOU") {
+		return "an " + word
+	}
+	return "a " + word
+}
@@ -0,0 +1,83 @@
+package openapi
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Schema", func() {
+	var zundokos *Schema
+
+	BeforeEach(func() {
+		spec, err := ZundokoSpec()
+		Expect(err).To(BeNil())
+		zundokos = spec.Operation("GET", "/zundokos").Response(200).Content["application/json"].Schema
+	})
+
+	// violations returns the violations of the error, which must be a *ValidationError.
+	violations := func(err error) []Violation {
+		var verr *ValidationError
+		Expect(errors.As(err, &verr)).To(BeTrue())
+		return verr.Violations
+	}
+
+	Describe("ValidateJSON()", func() {
+		It("accepts valid values.", func() {
+			Expect(zundokos.ValidateJSON([]byte(`[
+				{"id": "0b9ba4c7-4a8c-4b06-9d5c-2e5ad0a4e0f1", "sequence": 1, "saidAt": "2020-01-02T03:04:05.678+09:00", "word": "Zun"},
+				{"id": "0B9BA4C7-4A8C-4B06-9D5C-2E5AD0A4E0F2", "saidAt": "2020-01-02T03:04:06Z", "word": "Doko"}
+			]`))).To(Succeed())
+		})
+
+		It("lists every violation.", func() {
+			err := zundokos.ValidateJSON([]byte(`[
+				{"id": "not-a-uuid", "sequence": 0, "saidAt": "2020/01/02", "word": "Don"},
+				{"id": 1, "sequence": 1.5},
+				"Zun"
+			]`))
+
+			Expect(violations(err)).To(Equal([]Violation{
+				{Path: "[0].id", Message: `"not-a-uuid" is not in uuid format: not 8-4-4-4-12 hex digits`},
+				{Path: "[0].saidAt", Message: `"2020/01/02" is not in date-time format: parsing time "2020/01/02" as "2006-01-02T15:04:05.999999999Z07:00": cannot parse "/01/02" as "-"`},
+				{Path: "[0].sequence", Message: "0 is less than the minimum 1"},
+				{Path: "[0].word", Message: `"Don" is not one of ["Zun", "Doko"]`},
+				{Path: "[1]", Message: `missing required property "saidAt"`},
+				{Path: "[1]", Message: `missing required property "word"`},
+				{Path: "[1].id", Message: "1 is not a string"},
+				{Path: "[1].sequence", Message: "1.5 is not an integer"},
+				{Path: "[2]", Message: `"Zun" is not an object`},
+			}))
+			Expect(err.Error()).To(HavePrefix(`9 schema violations: [0].id: "not-a-uuid" is not in uuid format`))
+		})
+
+		It("reports invalid JSON.", func() {
+			err := zundokos.ValidateJSON([]byte(`[{`))
+
+			Expect(violations(err)).To(HaveLen(1))
+			Expect(violations(err)[0].Message).To(HavePrefix("invalid JSON"))
+		})
+	})
+
+	Describe("Validate()", func() {
+		It("checks required properties.", func() {
+			schema := &Schema{
+				Type:       "object",
+				Required:   []string{"id", "word"},
+				Properties: map[string]*Schema{"id": {Type: "string"}},
+			}
+
+			err := schema.Validate(map[string]interface{}{"id": "a"})
+
+			Expect(violations(err)).To(Equal([]Violation{{Message: `missing required property "word"`}}))
+		})
+
+		It("checks integers overflowing the format.", func() {
+			schema := &Schema{Type: "integer", Format: "int64"}
+
+			Expect(schema.ValidateJSON([]byte(`9223372036854775807`))).To(Succeed())
+			Expect(schema.ValidateJSON([]byte(`9223372036854775808`))).NotTo(Succeed())
+		})
+	})
+})
@@ -0,0 +1,2 @@
+// Package probe provides a synthetic monitoring probe that checks a read/write round-trip to Zundoko Server.
+package probe
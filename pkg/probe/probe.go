@@ -0,0 +1,251 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/client"
+	"github.com/kaitoy/zundoko-go-client/pkg/model"
+	"github.com/kaitoy/zundoko-go-client/pkg/runner"
+)
+
+// Status represents a result status of a probe, whose value is the exit code for it in the Nagios plugin convention.
+type Status int
+
+const (
+	// StatusOK means all the checks passed in time.
+	StatusOK Status = iota
+
+	// StatusWarning means all the checks passed but took longer than the warning threshold.
+	StatusWarning
+
+	// StatusCritical means a check failed or the checks took longer than the critical threshold.
+	StatusCritical
+
+	// StatusUnknown means the probe couldn't be done, e.g. due to an invalid configuration.
+	StatusUnknown
+)
+
+var statusNames = map[Status]string{
+	StatusOK:       "OK",
+	StatusWarning:  "WARNING",
+	StatusCritical: "CRITICAL",
+	StatusUnknown:  "UNKNOWN",
+}
+
+func (s Status) String() string {
+	if name, ok := statusNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("Status(%d)", int(s))
+}
+
+// MarshalText marshals the status to its name.
+func (s Status) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Names of checks of a probe, in the order they are done.
+const (
+	// CheckGet gets Zundokos.
+	CheckGet = "get"
+
+	// CheckPost posts a test Zundoko.
+	CheckPost = "post"
+
+	// CheckVerify gets Zundokos again and verifies the test Zundoko is in them.
+	CheckVerify = "verify"
+
+	// CheckKiyoshi posts test Zundokos completing the pattern and a test Kiyoshi for it,
+	// and verifies the Kiyoshi is in the Kiyoshies got after that.
+	CheckKiyoshi = "kiyoshi"
+)
+
+// Config represents configuration of a probe.
+type Config struct {
+	// Word is the word of the test Zundoko. Defaults to "Zun".
+	Word string
+
+	// Kiyoshi enables CheckKiyoshi.
+	Kiyoshi bool
+
+	// Warning is the elapsed time of the whole probe over which it's StatusWarning. Zero disables it.
+	Warning time.Duration
+
+	// Critical is the elapsed time of the whole probe over which it's StatusCritical. Zero disables it.
+	Critical time.Duration
+}
+
+// Check represents the result of a check of a probe.
+type Check struct {
+	// Name is the name of the check, which is one of the Check constants.
+	Name string `json:"name"`
+
+	// Passed tells if the check passed.
+	Passed bool `json:"passed"`
+
+	// Elapsed is the time the check took.
+	Elapsed time.Duration `json:"elapsed"`
+
+	// Error is why the check failed.
+	Error string `json:"error,omitempty"`
+}
+
+// Report represents the result of a probe.
+type Report struct {
+	// Status is the status of the probe.
+	Status Status `json:"status"`
+
+	// Message is a one-line summary of the probe.
+	Message string `json:"message"`
+
+	// StartedAt is the time the probe started.
+	StartedAt time.Time `json:"startedAt"`
+
+	// Elapsed is the time the whole probe took.
+	Elapsed time.Duration `json:"elapsed"`
+
+	// Warning and Critical are the thresholds of Elapsed, which are zero if disabled.
+	Warning  time.Duration `json:"warning,omitempty"`
+	Critical time.Duration `json:"critical,omitempty"`
+
+	// Checks is the results of the checks done in order. Checks after a failed one are not done.
+	Checks []Check `json:"checks"`
+}
+
+// Option configures a probe.
+type Option func(p *prober)
+
+// WithClock makes a probe measure time by the Clock instead of the real one.
+func WithClock(clock runner.Clock) Option {
+	return func(p *prober) {
+		p.clock = clock
+	}
+}
+
+// WithIDGenerator makes a probe generate ids of the test Zundoko and Kiyoshi by the IDGenerator instead of UUIDv4.
+func WithIDGenerator(ids runner.IDGenerator) Option {
+	return func(p *prober) {
+		p.ids = ids
+	}
+}
+
+type prober struct {
+	cl     client.Client
+	config Config
+	clock  runner.Clock
+	ids    runner.IDGenerator
+	report *Report
+}
+
+// Probe does a read/write round-trip to Zundoko Server via the Client: it gets Zundokos, posts a test Zundoko,
+// and verifies it's in the Zundokos got next. If Kiyoshi is configured, it also completes the pattern and posts
+// a test Kiyoshi for it, and verifies it's accepted. A 409 with another Kiyoshi, which another client made for
+// the pattern first, is also accepted. It stops at the first failed check, which makes the report StatusCritical.
+// Note the test Zundokos and Kiyoshi remain on Zundoko Server.
+func Probe(ctx context.Context, cl client.Client, config Config, opts ...Option) *Report {
+	if config.Word == "" {
+		config.Word = "Zun"
+	}
+	p := &prober{
+		cl:     cl,
+		config: config,
+		clock:  runner.NewRealClock(),
+		ids:    runner.NewUUIDGenerator(),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p.probe(ctx)
+}
+
+func (p *prober) probe(ctx context.Context) *Report {
+	p.report = &Report{
+		StartedAt: p.clock.Now(),
+		Warning:   p.config.Warning,
+		Critical:  p.config.Critical,
+	}
+	zundoko := &model.Zundoko{Id: p.ids.NewID(), SaidAt: p.clock.Now(), Word: p.config.Word}
+
+	failed := p.check(CheckGet, func() error {
+		_, err := p.cl.GetZundokos(ctx)
+		return err
+	}) || p.check(CheckPost, func() error {
+		return p.cl.PostZundoko(ctx, zundoko)
+	}) || p.check(CheckVerify, func() error {
+		zundokos, err := p.cl.GetZundokos(ctx)
+		if err != nil {
+			return err
+		}
+		for _, z := range zundokos {
+			if z.Id == zundoko.Id {
+				if z.Word != zundoko.Word {
+					return fmt.Errorf("Zundoko %s has %q instead of the posted %q", z.Id, z.Word, zundoko.Word)
+				}
+				return nil
+			}
+		}
+		return fmt.Errorf("Zundoko %s is not found in %d Zundokos", zundoko.Id, len(zundokos))
+	}) || p.config.Kiyoshi && p.check(CheckKiyoshi, func() error {
+		for _, word := range runner.Pattern() {
+			if err := p.cl.PostZundoko(ctx, &model.Zundoko{Id: p.ids.NewID(), SaidAt: p.clock.Now(), Word: word}); err != nil {
+				return err
+			}
+		}
+		kiyoshi := &model.Kiyoshi{Id: p.ids.NewID(), SaidAt: p.clock.Now()}
+		if err := p.cl.PostKiyoshi(ctx, kiyoshi); err != nil {
+			if errors.Is(err, client.ErrConflict) {
+				return nil
+			}
+			return err
+		}
+		kiyoshies, err := p.cl.GetKiyoshies(ctx)
+		if err != nil {
+			return err
+		}
+		for _, k := range kiyoshies {
+			if k.Id == kiyoshi.Id {
+				return nil
+			}
+		}
+		return fmt.Errorf("Kiyoshi %s is not found in %d Kiyoshies", kiyoshi.Id, len(kiyoshies))
+	})
+
+	r := p.report
+	r.Elapsed = p.clock.Now().Sub(r.StartedAt)
+	switch {
+	case failed:
+		last := r.Checks[len(r.Checks)-1]
+		r.Status = StatusCritical
+		r.Message = fmt.Sprintf("%s failed: %s", last.Name, last.Error)
+	case r.Critical > 0 && r.Elapsed > r.Critical:
+		r.Status = StatusCritical
+		r.Message = fmt.Sprintf("round-trip took %s, over the critical threshold %s", round(r.Elapsed), r.Critical)
+	case r.Warning > 0 && r.Elapsed > r.Warning:
+		r.Status = StatusWarning
+		r.Message = fmt.Sprintf("round-trip took %s, over the warning threshold %s", round(r.Elapsed), r.Warning)
+	default:
+		r.Status = StatusOK
+		r.Message = fmt.Sprintf("round-trip passed in %s", round(r.Elapsed))
+	}
+	return r
+}
+
+// check does the check by f, records the result of it to the report, and returns true if it failed.
+func (p *prober) check(name string, f func() error) bool {
+	start := p.clock.Now()
+	err := f()
+	c := Check{Name: name, Passed: err == nil, Elapsed: p.clock.Now().Sub(start)}
+	if err != nil {
+		c.Error = err.Error()
+	}
+	p.report.Checks = append(p.report.Checks, c)
+	return err != nil
+}
+
+// round rounds d for messages.
+func round(d time.Duration) time.Duration {
+	return d.Round(time.Millisecond)
+}
@@ -0,0 +1,208 @@
+package probe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/kaitoy/zundoko-go-client/mock/pkg/mock_client"
+	"github.com/kaitoy/zundoko-go-client/pkg/client"
+	"github.com/kaitoy/zundoko-go-client/pkg/history"
+	"github.com/kaitoy/zundoko-go-client/pkg/model"
+	"github.com/kaitoy/zundoko-go-client/pkg/runner"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// sequentialIDs generates ids "id-1", "id-2", and so on.
+type sequentialIDs struct {
+	n int
+}
+
+func (g *sequentialIDs) NewID() string {
+	g.n++
+	return fmt.Sprintf("id-%d", g.n)
+}
+
+var _ = Describe("Probe()", func() {
+	var (
+		mockCtrl   *gomock.Controller
+		mockClient *mock_client.MockClient
+		clock      *runner.FakeClock
+		opts       []Option
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = mock_client.NewMockClient(mockCtrl)
+		clock = runner.NewFakeClock(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+		opts = []Option{WithClock(clock), WithIDGenerator(&sequentialIDs{})}
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	// advancing returns a func advancing the clock by d, to be called by a mock.
+	advancing := func(d time.Duration) func(...interface{}) {
+		return func(...interface{}) { clock.Advance(d) }
+	}
+
+	It("passes a round-trip.", func() {
+		cl := history.NewMemoryClient(nil)
+
+		r := Probe(context.Background(), cl, Config{Kiyoshi: true}, opts...)
+
+		Expect(r.Status).To(Equal(StatusOK))
+		Expect(r.Message).To(Equal("round-trip passed in 0s"))
+		Expect(r.Checks).To(HaveLen(4))
+		for i, name := range []string{CheckGet, CheckPost, CheckVerify, CheckKiyoshi} {
+			Expect(r.Checks[i].Name).To(Equal(name))
+			Expect(r.Checks[i].Passed).To(BeTrue())
+		}
+		zundokos, _ := cl.GetZundokos(context.Background())
+		var words []string
+		for _, zd := range zundokos {
+			words = append(words, zd.Word)
+		}
+		Expect(zundokos[0].Id).To(Equal("id-1"))
+		Expect(words).To(Equal([]string{"Zun", "Zun", "Zun", "Zun", "Zun", "Doko"}))
+		kiyoshies, _ := cl.GetKiyoshies(context.Background())
+		Expect(kiyoshies).To(HaveLen(1))
+		Expect(kiyoshies[0].Id).To(Equal("id-7"))
+	})
+
+	It("passes the Kiyoshi check on a conflict with another Kiyoshi for the pattern.", func() {
+		mockClient.EXPECT().GetZundokos(gomock.Any()).Return(nil, nil)
+		mockClient.EXPECT().PostZundoko(gomock.Any(), gomock.Any()).Return(nil).Times(6)
+		mockClient.EXPECT().GetZundokos(gomock.Any()).Return([]model.Zundoko{{Id: "id-1", Word: "Zun"}}, nil)
+		mockClient.EXPECT().PostKiyoshi(gomock.Any(), gomock.Any()).
+			Return(fmt.Errorf("POST Kiyoshi API returned a conflict with Kiyoshi other: %w", client.ErrConflict))
+
+		r := Probe(context.Background(), mockClient, Config{Kiyoshi: true}, opts...)
+
+		Expect(r.Status).To(Equal(StatusOK))
+		Expect(r.Checks).To(HaveLen(4))
+	})
+
+	It("fails the Kiyoshi check on another error.", func() {
+		mockClient.EXPECT().GetZundokos(gomock.Any()).Return(nil, nil)
+		mockClient.EXPECT().PostZundoko(gomock.Any(), gomock.Any()).Return(nil).Times(6)
+		mockClient.EXPECT().GetZundokos(gomock.Any()).Return([]model.Zundoko{{Id: "id-1", Word: "Zun"}}, nil)
+		mockClient.EXPECT().PostKiyoshi(gomock.Any(), gomock.Any()).Return(errors.New("503 Service Unavailable"))
+
+		r := Probe(context.Background(), mockClient, Config{Kiyoshi: true}, opts...)
+
+		Expect(r.Status).To(Equal(StatusCritical))
+		Expect(r.Message).To(Equal("kiyoshi failed: 503 Service Unavailable"))
+	})
+
+	It("skips the Kiyoshi check unless configured.", func() {
+		r := Probe(context.Background(), history.NewMemoryClient(nil), Config{Word: "Doko"}, opts...)
+
+		Expect(r.Status).To(Equal(StatusOK))
+		Expect(r.Checks).To(HaveLen(3))
+	})
+
+	It("fails at the first failed check.", func() {
+		mockClient.EXPECT().GetZundokos(gomock.Any()).Return(nil, nil)
+		mockClient.EXPECT().PostZundoko(gomock.Any(), gomock.Any()).Return(errors.New("503 Service Unavailable"))
+
+		r := Probe(context.Background(), mockClient, Config{Kiyoshi: true}, opts...)
+
+		Expect(r.Status).To(Equal(StatusCritical))
+		Expect(r.Message).To(Equal("post failed: 503 Service Unavailable"))
+		Expect(r.Checks).To(HaveLen(2))
+		Expect(r.Checks[1].Passed).To(BeFalse())
+	})
+
+	It("fails if the posted Zundoko isn't got.", func() {
+		mockClient.EXPECT().GetZundokos(gomock.Any()).Return(nil, nil)
+		mockClient.EXPECT().PostZundoko(gomock.Any(), gomock.Any()).Return(nil)
+		mockClient.EXPECT().GetZundokos(gomock.Any()).Return([]model.Zundoko{{Id: "other", Word: "Zun"}}, nil)
+
+		r := Probe(context.Background(), mockClient, Config{}, opts...)
+
+		Expect(r.Status).To(Equal(StatusCritical))
+		Expect(r.Message).To(Equal("verify failed: Zundoko id-1 is not found in 1 Zundokos"))
+	})
+
+	It("fails if the posted Zundoko is got with another word.", func() {
+		mockClient.EXPECT().GetZundokos(gomock.Any()).Return(nil, nil)
+		mockClient.EXPECT().PostZundoko(gomock.Any(), gomock.Any()).Return(nil)
+		mockClient.EXPECT().GetZundokos(gomock.Any()).Return([]model.Zundoko{{Id: "id-1", Word: "Doko"}}, nil)
+
+		r := Probe(context.Background(), mockClient, Config{}, opts...)
+
+		Expect(r.Status).To(Equal(StatusCritical))
+		Expect(r.Checks[2].Error).To(ContainSubstring(`"Doko"`))
+	})
+
+	It("measures the checks against the thresholds.", func() {
+		mockClient.EXPECT().GetZundokos(gomock.Any()).Do(advancing(300*time.Millisecond)).Return(nil, nil)
+		mockClient.EXPECT().PostZundoko(gomock.Any(), gomock.Any()).Do(advancing(500 * time.Millisecond)).Return(nil)
+		mockClient.EXPECT().GetZundokos(gomock.Any()).Do(advancing(400*time.Millisecond)).
+			Return([]model.Zundoko{{Id: "id-1", Word: "Zun"}}, nil)
+
+		r := Probe(context.Background(), mockClient, Config{Warning: time.Second, Critical: 2 * time.Second}, opts...)
+
+		Expect(r.Status).To(Equal(StatusWarning))
+		Expect(r.Message).To(Equal("round-trip took 1.2s, over the warning threshold 1s"))
+		Expect(r.Elapsed).To(Equal(1200 * time.Millisecond))
+		Expect(r.Checks[1].Elapsed).To(Equal(500 * time.Millisecond))
+	})
+
+	It("is critical over the critical threshold.", func() {
+		mockClient.EXPECT().GetZundokos(gomock.Any()).Do(advancing(3*time.Second)).Return(nil, nil)
+		mockClient.EXPECT().PostZundoko(gomock.Any(), gomock.Any()).Return(nil)
+		mockClient.EXPECT().GetZundokos(gomock.Any()).Return([]model.Zundoko{{Id: "id-1", Word: "Zun"}}, nil)
+
+		r := Probe(context.Background(), mockClient, Config{Warning: time.Second, Critical: 2 * time.Second}, opts...)
+
+		Expect(r.Status).To(Equal(StatusCritical))
+	})
+})
+
+var _ = Describe("Report", func() {
+	report := &Report{
+		Status:   StatusCritical,
+		Message:  "verify failed: not found",
+		Elapsed:  1500 * time.Millisecond,
+		Warning:  time.Second,
+		Critical: 2 * time.Second,
+		Checks: []Check{
+			{Name: CheckGet, Passed: true, Elapsed: 250 * time.Millisecond},
+			{Name: CheckVerify, Elapsed: 1250 * time.Millisecond, Error: "not found"},
+		},
+	}
+
+	Describe("WriteNagios()", func() {
+		It("writes the status line with performance data and a line for each check.", func() {
+			var b bytes.Buffer
+
+			Expect(report.WriteNagios(&b)).To(Succeed())
+
+			Expect(b.String()).To(Equal(
+				"ZUNDOKO CRITICAL - verify failed: not found | total=1.5s;1;2;0; get=0.25s;;;0; verify=1.25s;;;0;\n" +
+					"get: passed in 250ms\n" +
+					"verify: failed in 1.25s: not found\n"))
+		})
+	})
+
+	Describe("WriteJSON()", func() {
+		It("writes the status by name.", func() {
+			var b bytes.Buffer
+
+			Expect(report.WriteJSON(&b)).To(Succeed())
+
+			var decoded map[string]interface{}
+			Expect(json.Unmarshal(b.Bytes(), &decoded)).To(Succeed())
+			Expect(decoded["status"]).To(Equal("CRITICAL"))
+			Expect(decoded["checks"]).To(HaveLen(2))
+		})
+	})
+})
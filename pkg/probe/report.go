@@ -0,0 +1,52 @@
+package probe
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WriteNagios writes the report in the output format of Nagios plugins, which Icinga also accepts:
+// a status line with performance data of the elapsed times in seconds, followed by a line for each check.
+//
+//	ZUNDOKO OK - round-trip passed in 35ms | total=0.035s;1;2;0; get=0.01s;;;0; ...
+func (r *Report) WriteNagios(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "ZUNDOKO %s - %s | total=%ss;%s;%s;0;", r.Status, r.Message, seconds(r.Elapsed), threshold(r.Warning), threshold(r.Critical))
+	for _, c := range r.Checks {
+		fmt.Fprintf(&b, " %s=%ss;;;0;", c.Name, seconds(c.Elapsed))
+	}
+	b.WriteString("\n")
+	for _, c := range r.Checks {
+		if c.Passed {
+			fmt.Fprintf(&b, "%s: passed in %s\n", c.Name, round(c.Elapsed))
+		} else {
+			fmt.Fprintf(&b, "%s: failed in %s: %s\n", c.Name, round(c.Elapsed), c.Error)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteJSON writes the report in JSON. Durations are in nanoseconds.
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// seconds formats d in seconds for performance data.
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
+
+// threshold formats a threshold for performance data, which is empty if disabled.
+func threshold(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	return seconds(d)
+}
@@ -0,0 +1,19 @@
+package runner
+
+import "time"
+
+// Backoff returns the time to wait after the number of consecutive failures, which is base after the first
+// failure and doubles for each subsequent one up to max. It's zero if failures is zero.
+func Backoff(base, max time.Duration, failures int) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+	backoff := base
+	for i := 1; i < failures && backoff < max; i++ {
+		backoff *= 2
+	}
+	if backoff > max {
+		return max
+	}
+	return backoff
+}
@@ -0,0 +1,23 @@
+package runner
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Backoff()", func() {
+	It("doubles the base for each consecutive failure.", func() {
+		Expect(Backoff(time.Second, time.Minute, 0)).To(Equal(time.Duration(0)))
+		Expect(Backoff(time.Second, time.Minute, 1)).To(Equal(time.Second))
+		Expect(Backoff(time.Second, time.Minute, 2)).To(Equal(2 * time.Second))
+		Expect(Backoff(time.Second, time.Minute, 4)).To(Equal(8 * time.Second))
+	})
+
+	It("caps the backoff at the max.", func() {
+		Expect(Backoff(time.Second, time.Minute, 7)).To(Equal(time.Minute))
+		Expect(Backoff(time.Second, time.Minute, 1000)).To(Equal(time.Minute))
+		Expect(Backoff(time.Minute, time.Second, 1)).To(Equal(time.Second))
+	})
+})
@@ -0,0 +1,76 @@
+package runner
+
+import (
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// fakeRand is a Rand that returns its values in turn, each capped to the given range.
+type fakeRand struct {
+	values []int
+	next   int
+}
+
+func (r *fakeRand) Intn(n int) int {
+	v := r.values[r.next%len(r.values)]
+	r.next++
+	if v >= n {
+		return n - 1
+	}
+	return v
+}
+
+func (r *fakeRand) Int63n(n int64) int64 {
+	return int64(r.Intn(int(n)))
+}
+
+// sequentialIDs is an IDGenerator that generates "id-1", "id-2", and so on.
+type sequentialIDs struct {
+	last int
+}
+
+func (g *sequentialIDs) NewID() string {
+	g.last++
+	return "id-" + strconv.Itoa(g.last)
+}
+
+var _ = Describe("FakeClock", func() {
+	start := time.Date(2021, 1, 1, 1, 50, 0, 0, time.UTC)
+
+	It("tells the time only advanced manually.", func() {
+		clock := NewFakeClock(start)
+		Expect(clock.Now()).To(Equal(start))
+
+		clock.Advance(time.Minute)
+
+		Expect(clock.Now()).To(Equal(start.Add(time.Minute)))
+	})
+
+	It("fires channels returned by After when their time has come.", func() {
+		clock := NewFakeClock(start)
+		first := clock.After(time.Second)
+		second := clock.After(3 * time.Second)
+		Expect(clock.Waiters()).To(Equal(2))
+
+		clock.Advance(2 * time.Second)
+
+		Expect(first).To(Receive(Equal(start.Add(2 * time.Second))))
+		Expect(second).NotTo(Receive())
+		Expect(clock.Waiters()).To(Equal(1))
+
+		clock.Advance(time.Second)
+
+		Expect(second).To(Receive(Equal(start.Add(3 * time.Second))))
+		Expect(clock.Waiters()).To(Equal(0))
+	})
+
+	It("fires a channel right away for a non-positive duration.", func() {
+		clock := NewFakeClock(start)
+
+		Expect(clock.After(0)).To(Receive(Equal(start)))
+		Expect(clock.Waiters()).To(Equal(0))
+	})
+})
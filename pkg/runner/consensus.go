@@ -0,0 +1,81 @@
+package runner
+
+import (
+	"github.com/kaitoy/zundoko-go-client/pkg/model"
+)
+
+// ClaimRule decides who may claim a Kiyoshi for a pattern completed in a history shared by many clients.
+type ClaimRule int
+
+const (
+	// ClaimAny lets any client that sees a completed pattern claim a Kiyoshi for it.
+	ClaimAny ClaimRule = iota
+
+	// ClaimCompleter lets only the client that posted the word completing a pattern claim a Kiyoshi for it.
+	ClaimCompleter
+)
+
+// DuplicateRule decides what to do when Zundoko Server rejects a Kiyoshi with 409 Conflict
+// since another one has been made for the pattern. As the OpenAPI spec defines, the 409 response has the other Kiyoshi,
+// whose id differs from the posted one, and the Client returns an error wrapping client.ErrConflict for it.
+type DuplicateRule int
+
+const (
+	// DuplicateFail makes Run return an error wrapping client.ErrConflict.
+	DuplicateFail DuplicateRule = iota
+
+	// DuplicateYield makes Run end successfully without a Kiyoshi, setting Result.Duplicate.
+	DuplicateYield
+)
+
+// Consensus defines rules for a Runner to play Zundoko Kiyoshi in a history shared with other clients.
+//
+// Under the rules, a Kiyoshi may be claimed for a pattern completed after the session started
+// anywhere in the history got from Zundoko Server, not only at the end of it,
+// so that a pattern followed by words of others before getting the history is not missed.
+// A pattern completed before the session started is never claimed.
+type Consensus struct {
+	// Claim decides who may claim a Kiyoshi.
+	Claim ClaimRule
+
+	// OwnWordsOnly makes a Runner count only words posted by itself, ignoring words of others,
+	// so that they can't complete or break its pattern.
+	OwnWordsOnly bool
+
+	// OnDuplicate decides what to do when a Kiyoshi is rejected as a duplicate.
+	OnDuplicate DuplicateRule
+}
+
+// claimable tells if a Kiyoshi may be claimed under the rules for the pattern in the Zundokos.
+// own holds ids of the Zundokos posted in the session, and old holds ids of ones existed at the start of it.
+func (c *Consensus) claimable(zundokos []model.Zundoko, own, old map[string]bool, pattern []string) bool {
+	sorted := sortedZundokos(zundokos)
+	if c.OwnWordsOnly {
+		mine := sorted[:0]
+		for _, zd := range sorted {
+			if own[zd.Id] {
+				mine = append(mine, zd)
+			}
+		}
+		sorted = mine
+	}
+
+	for end := len(sorted); end >= len(pattern); end-- {
+		completer := sorted[end-1]
+		if old[completer.Id] {
+			break
+		}
+		if c.Claim == ClaimCompleter && !own[completer.Id] {
+			continue
+		}
+
+		var words []string
+		for _, zd := range sorted[end-len(pattern) : end] {
+			words = append(words, zd.Word)
+		}
+		if matchesPattern(words, pattern) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package runner
+
+import (
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/model"
+)
+
+// patternWords is the default sequence of words that makes a Kiyoshi.
+var patternWords = [...]string{"Zun", "Zun", "Zun", "Zun", "Doko"}
+
+// LocalDetection configures a Runner to detect the pattern by words kept locally instead of
+// getting Zundokos before every post, which roughly halves API calls.
+// The words are synced with Zundoko Server at the start of a session, when a post conflicts,
+// and at the intervals below.
+// Words posted by others between syncs are not seen, so the pattern may be detected late or falsely.
+type LocalDetection struct {
+	// ResyncPosts is the number of posts after which the words are synced again. Zero means never.
+	ResyncPosts int
+
+	// ResyncInterval is the time after which the words are synced again. Zero means never.
+	ResyncInterval time.Duration
+}
+
+// wordRing is a ring buffer of the last words of a Zundoko Kiyoshi, long enough to detect the pattern.
+type wordRing struct {
+	pattern []string
+	words   []string
+	next    int
+	size    int
+
+	// synced tells if the words have been synced with Zundoko Server.
+	synced bool
+	// syncedAt is the time the words were synced last.
+	syncedAt time.Time
+	// posts is the number of words pushed since the last sync.
+	posts int
+}
+
+// newWordRing creates a wordRing to detect the pattern.
+func newWordRing(pattern []string) *wordRing {
+	return &wordRing{pattern: pattern, words: make([]string, len(pattern))}
+}
+
+// push adds a word posted by the Runner.
+func (r *wordRing) push(word string) {
+	r.words[r.next] = word
+	r.next = (r.next + 1) % len(r.words)
+	if r.size < len(r.words) {
+		r.size++
+	}
+	r.posts++
+}
+
+// sync replaces the words with the last ones of the Zundokos got from Zundoko Server.
+func (r *wordRing) sync(zundokos []model.Zundoko, now time.Time) {
+	*r = wordRing{pattern: r.pattern, words: r.words, synced: true, syncedAt: now}
+	sorted := sortedZundokos(zundokos)
+	if len(sorted) > len(r.words) {
+		sorted = sorted[len(sorted)-len(r.words):]
+	}
+	for _, zd := range sorted {
+		r.push(zd.Word)
+	}
+	r.posts = 0
+}
+
+// invalidate makes the words to be synced at the next check.
+func (r *wordRing) invalidate() {
+	r.synced = false
+}
+
+// needsSync tells if the words should be synced with Zundoko Server now.
+func (r *wordRing) needsSync(config *LocalDetection, now time.Time) bool {
+	switch {
+	case !r.synced:
+		return true
+	case config.ResyncPosts > 0 && r.posts >= config.ResyncPosts:
+		return true
+	case config.ResyncInterval > 0 && now.Sub(r.syncedAt) >= config.ResyncInterval:
+		return true
+	}
+	return false
+}
+
+// last returns the words in order from the oldest.
+func (r *wordRing) last() []string {
+	words := make([]string, 0, r.size)
+	for i := 0; i < r.size; i++ {
+		words = append(words, r.words[(r.next-r.size+i+len(r.words))%len(r.words)])
+	}
+	return words
+}
+
+// matches tells if the words make the pattern.
+func (r *wordRing) matches() bool {
+	return EndsWithPattern(r.last(), r.pattern)
+}
+
+// EndsWithPattern tells if the words, in the order they were said, end with the pattern, which means
+// it's time to go Kiyoshi. It's how a Runner detects the pattern, whether in the Zundokos got from Zundoko Server
+// or in the words kept by LocalDetection.
+func EndsWithPattern(words, pattern []string) bool {
+	if len(words) < len(pattern) {
+		return false
+	}
+	return matchesPattern(words[len(words)-len(pattern):], pattern)
+}
+
+// matchesPattern tells if the words make the pattern.
+func matchesPattern(words, pattern []string) bool {
+	if len(words) != len(pattern) {
+		return false
+	}
+	for i, word := range words {
+		if word != pattern[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Pattern returns the default sequence of words that makes a Kiyoshi.
+func Pattern() []string {
+	return append([]string(nil), patternWords[:]...)
+}
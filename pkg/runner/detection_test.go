@@ -0,0 +1,99 @@
+package runner
+
+import (
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("wordRing", func() {
+	start := time.Date(2021, 1, 1, 1, 50, 0, 0, time.UTC)
+
+	It("keeps the last words as long as the pattern.", func() {
+		ring := newWordRing(Pattern())
+		for _, word := range []string{"Doko", "Zun", "Zun"} {
+			ring.push(word)
+		}
+		Expect(ring.last()).To(Equal([]string{"Doko", "Zun", "Zun"}))
+		Expect(ring.matches()).To(BeFalse())
+
+		for _, word := range []string{"Zun", "Zun", "Doko"} {
+			ring.push(word)
+		}
+		Expect(ring.last()).To(Equal([]string{"Zun", "Zun", "Zun", "Zun", "Doko"}))
+		Expect(ring.matches()).To(BeTrue())
+	})
+
+	It("syncs with the last Zundokos in the order of SaidAt.", func() {
+		ring := newWordRing(Pattern())
+		ring.push("Doko")
+
+		ring.sync([]model.Zundoko{
+			{Word: "Doko", SaidAt: start.Add(5 * time.Second)},
+			{Word: "Zun", SaidAt: start.Add(1 * time.Second)},
+			{Word: "Doko", SaidAt: start},
+			{Word: "Zun", SaidAt: start.Add(2 * time.Second)},
+			{Word: "Zun", SaidAt: start.Add(3 * time.Second)},
+			{Word: "Zun", SaidAt: start.Add(4 * time.Second)},
+		}, start)
+
+		Expect(ring.last()).To(Equal([]string{"Zun", "Zun", "Zun", "Zun", "Doko"}))
+		Expect(ring.posts).To(Equal(0))
+	})
+
+	Describe("needsSync()", func() {
+		It("needs a sync at first and after invalidated.", func() {
+			ring := newWordRing(Pattern())
+			config := &LocalDetection{}
+			Expect(ring.needsSync(config, start)).To(BeTrue())
+
+			ring.sync(nil, start)
+			Expect(ring.needsSync(config, start.Add(time.Hour))).To(BeFalse())
+
+			ring.invalidate()
+			Expect(ring.needsSync(config, start)).To(BeTrue())
+		})
+
+		It("needs a sync after the posts.", func() {
+			ring := newWordRing(Pattern())
+			config := &LocalDetection{ResyncPosts: 2}
+			ring.sync(nil, start)
+
+			ring.push("Zun")
+			Expect(ring.needsSync(config, start)).To(BeFalse())
+			ring.push("Zun")
+			Expect(ring.needsSync(config, start)).To(BeTrue())
+		})
+
+		It("needs a sync after the interval.", func() {
+			ring := newWordRing(Pattern())
+			config := &LocalDetection{ResyncInterval: time.Minute}
+			ring.sync(nil, start)
+
+			Expect(ring.needsSync(config, start.Add(59*time.Second))).To(BeFalse())
+			Expect(ring.needsSync(config, start.Add(time.Minute))).To(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("Pattern()", func() {
+	It("returns a copy of the pattern.", func() {
+		pattern := Pattern()
+		Expect(pattern).To(Equal([]string{"Zun", "Zun", "Zun", "Zun", "Doko"}))
+
+		pattern[0] = "Doko"
+		Expect(Pattern()[0]).To(Equal("Zun"))
+	})
+})
+
+var _ = Describe("EndsWithPattern()", func() {
+	It("tells if the last words make the pattern.", func() {
+		Expect(EndsWithPattern([]string{"Zun", "Zun", "Zun", "Zun", "Doko"}, Pattern())).To(BeTrue())
+		Expect(EndsWithPattern([]string{"Doko", "Zun", "Zun", "Zun", "Zun", "Doko"}, Pattern())).To(BeTrue())
+		Expect(EndsWithPattern([]string{"Zun", "Zun", "Zun", "Zun", "Doko", "Zun"}, Pattern())).To(BeFalse())
+		Expect(EndsWithPattern([]string{"Zun", "Zun", "Zun", "Doko"}, Pattern())).To(BeFalse())
+		Expect(EndsWithPattern(nil, Pattern())).To(BeFalse())
+	})
+})
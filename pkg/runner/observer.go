@@ -0,0 +1,141 @@
+package runner
+
+import (
+	"errors"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/client"
+	"github.com/kaitoy/zundoko-go-client/pkg/logging"
+	"github.com/kaitoy/zundoko-go-client/pkg/model"
+)
+
+// Observer observes a Zundoko Kiyoshi session run by a Runner.
+// Its methods are called synchronously in the goroutine calling Run, so they should return quickly.
+type Observer interface {
+	// OnStart is called when a session starts.
+	OnStart()
+
+	// OnFetch is called with Zundokos got from Zundoko Server in each iteration.
+	OnFetch(zundokos []model.Zundoko)
+
+	// OnWord is called with a posted Zundoko. index is its 1-origin position in the session.
+	OnWord(zundoko *model.Zundoko, index int)
+
+	// OnKiyoshi is called with a posted Kiyoshi. index is its 1-origin position in the session.
+	OnKiyoshi(kiyoshi *model.Kiyoshi, index int)
+
+	// OnError is called with the error the session ends with.
+	OnError(err error)
+
+	// OnFinish is called with the result when a session ends, successfully or not.
+	OnFinish(result *Result)
+}
+
+// ObserverFuncs is an Observer that calls the funcs set to its fields. Nil fields are skipped.
+type ObserverFuncs struct {
+	Start   func()
+	Fetch   func(zundokos []model.Zundoko)
+	Word    func(zundoko *model.Zundoko, index int)
+	Kiyoshi func(kiyoshi *model.Kiyoshi, index int)
+	Error   func(err error)
+	Finish  func(result *Result)
+}
+
+func (o ObserverFuncs) OnStart() {
+	if o.Start != nil {
+		o.Start()
+	}
+}
+
+func (o ObserverFuncs) OnFetch(zundokos []model.Zundoko) {
+	if o.Fetch != nil {
+		o.Fetch(zundokos)
+	}
+}
+
+func (o ObserverFuncs) OnWord(zundoko *model.Zundoko, index int) {
+	if o.Word != nil {
+		o.Word(zundoko, index)
+	}
+}
+
+func (o ObserverFuncs) OnKiyoshi(kiyoshi *model.Kiyoshi, index int) {
+	if o.Kiyoshi != nil {
+		o.Kiyoshi(kiyoshi, index)
+	}
+}
+
+func (o ObserverFuncs) OnError(err error) {
+	if o.Error != nil {
+		o.Error(err)
+	}
+}
+
+func (o ObserverFuncs) OnFinish(result *Result) {
+	if o.Finish != nil {
+		o.Finish(result)
+	}
+}
+
+// NewPresentingObserver creates an Observer that presents posted words with the Presenter.
+func NewPresentingObserver(presenter Presenter) Observer {
+	return ObserverFuncs{
+		Word:    presenter.PresentZundoko,
+		Kiyoshi: presenter.PresentKiyoshi,
+	}
+}
+
+// NewLoggingObserver creates an Observer that logs the start and the end of a session.
+func NewLoggingObserver() Observer {
+	return ObserverFuncs{
+		Start: func() {
+			logging.GetLogger().Info("Start Zundoko Kiyoshi.")
+		},
+		Error: func(err error) {
+			if errors.Is(err, client.ErrCircuitOpen) {
+				logging.GetLogger().Warn("Give up Zundoko Kiyoshi since Zundoko Server seems to be down.")
+			}
+		},
+		Finish: func(result *Result) {
+			logging.GetLogger().Sync()
+		},
+	}
+}
+
+// observers is an Observer that notifies all of its elements in order.
+type observers []Observer
+
+func (os observers) OnStart() {
+	for _, o := range os {
+		o.OnStart()
+	}
+}
+
+func (os observers) OnFetch(zundokos []model.Zundoko) {
+	for _, o := range os {
+		o.OnFetch(zundokos)
+	}
+}
+
+func (os observers) OnWord(zundoko *model.Zundoko, index int) {
+	for _, o := range os {
+		o.OnWord(zundoko, index)
+	}
+}
+
+func (os observers) OnKiyoshi(kiyoshi *model.Kiyoshi, index int) {
+	for _, o := range os {
+		o.OnKiyoshi(kiyoshi, index)
+	}
+}
+
+func (os observers) OnError(err error) {
+	for _, o := range os {
+		o.OnError(err)
+	}
+}
+
+func (os observers) OnFinish(result *Result) {
+	for _, o := range os {
+		o.OnFinish(result)
+	}
+}
@@ -0,0 +1,75 @@
+package runner
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Observer", func() {
+	Describe("ObserverFuncs", func() {
+		It("calls the funcs set to its fields.", func() {
+			var events []string
+			o := ObserverFuncs{
+				Start:   func() { events = append(events, "start") },
+				Fetch:   func(zundokos []model.Zundoko) { events = append(events, "fetch") },
+				Word:    func(zundoko *model.Zundoko, index int) { events = append(events, zundoko.Word) },
+				Kiyoshi: func(kiyoshi *model.Kiyoshi, index int) { events = append(events, "kiyoshi") },
+				Error:   func(err error) { events = append(events, err.Error()) },
+				Finish:  func(result *Result) { events = append(events, "finish") },
+			}
+
+			o.OnStart()
+			o.OnFetch(nil)
+			o.OnWord(&model.Zundoko{Word: "Zun"}, 1)
+			o.OnKiyoshi(&model.Kiyoshi{}, 2)
+			o.OnError(errors.New("some error"))
+			o.OnFinish(&Result{})
+
+			Expect(events).To(Equal([]string{"start", "fetch", "Zun", "kiyoshi", "some error", "finish"}))
+		})
+
+		It("skips nil fields.", func() {
+			o := ObserverFuncs{}
+
+			Expect(func() {
+				o.OnStart()
+				o.OnFetch(nil)
+				o.OnWord(&model.Zundoko{}, 1)
+				o.OnKiyoshi(&model.Kiyoshi{}, 2)
+				o.OnError(errors.New("some error"))
+				o.OnFinish(&Result{})
+			}).NotTo(Panic())
+		})
+	})
+
+	Describe("NewPresentingObserver()", func() {
+		It("presents words with the Presenter.", func() {
+			output := new(bytes.Buffer)
+			o := NewPresentingObserver(NewTextPresenter(output))
+
+			o.OnStart()
+			o.OnWord(&model.Zundoko{Word: "Doko"}, 1)
+			o.OnKiyoshi(&model.Kiyoshi{}, 2)
+			o.OnFinish(&Result{})
+
+			Expect(output.String()).To(Equal("Doko\nKi Yo Shi !\n"))
+		})
+	})
+
+	Describe("observers", func() {
+		It("notifies all the Observers in order.", func() {
+			var events []string
+			newObserver := func(name string) Observer {
+				return ObserverFuncs{Start: func() { events = append(events, name) }}
+			}
+
+			observers{newObserver("first"), newObserver("second")}.OnStart()
+
+			Expect(events).To(Equal([]string{"first", "second"}))
+		})
+	})
+})
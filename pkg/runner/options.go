@@ -0,0 +1,89 @@
+package runner
+
+// Option configures a Runner.
+type Option func(r *runner)
+
+// WithPresenter makes a Runner present words with the Presenter.
+// By default, words are written to the standard output in plain text.
+func WithPresenter(presenter Presenter) Option {
+	return func(r *runner) {
+		r.presenter = presenter
+	}
+}
+
+// WithWordSource makes a Runner post words supplied by the WordSource instead of random ones,
+// which lets the Runner be a front-end of a game played by a human or another program.
+// By default, words are supplied by a WordSource created by NewRandomWordSource with the Rand given by WithRand.
+func WithWordSource(source WordSource) Option {
+	return func(r *runner) {
+		r.source = source
+	}
+}
+
+// WithObserver makes a Runner notify the Observer of the progress of a session.
+// Observers are notified in the order they are given, after the built-in one that presents words.
+// Pass the one created by NewLoggingObserver to log the start and the end of a session.
+func WithObserver(observer Observer) Option {
+	return func(r *runner) {
+		r.observers = append(r.observers, observer)
+	}
+}
+
+// WithPacer makes a Runner say words at the pace decided by the Pacer.
+// By default, words are said at a fixed interval of 1 second.
+func WithPacer(pacer Pacer) Option {
+	return func(r *runner) {
+		r.pacer = pacer
+	}
+}
+
+// WithClock makes a Runner tell the time with the Clock, which lets tests control the pace.
+// By default, a Clock created by NewRealClock is used.
+func WithClock(clock Clock) Option {
+	return func(r *runner) {
+		r.clock = clock
+	}
+}
+
+// WithRand makes a Runner choose random words with the Rand unless a WordSource is given by WithWordSource.
+// By default, the top-level funcs of math/rand are used.
+func WithRand(rnd Rand) Option {
+	return func(r *runner) {
+		r.rnd = rnd
+	}
+}
+
+// WithIDGenerator makes a Runner give ids generated by the IDGenerator to Zundokos and Kiyoshies.
+// By default, a UUIDv4 is given.
+func WithIDGenerator(ids IDGenerator) Option {
+	return func(r *runner) {
+		r.ids = ids
+	}
+}
+
+// WithLocalDetection makes a Runner detect the pattern by words kept locally as configured,
+// instead of getting Zundokos before every post.
+func WithLocalDetection(config LocalDetection) Option {
+	return func(r *runner) {
+		r.local = &config
+	}
+}
+
+// WithConsensus makes a Runner follow the Consensus to play in a history shared with other clients.
+// By default, a Runner claims a Kiyoshi whenever the last words in the history make the pattern,
+// and fails if it's rejected as a duplicate.
+func WithConsensus(consensus Consensus) Option {
+	return func(r *runner) {
+		r.consensus = &consensus
+	}
+}
+
+// WithPattern makes a Runner go Kiyoshi when the last words make the pattern instead of the default one
+// returned by Pattern. An empty pattern is ignored.
+func WithPattern(pattern []string) Option {
+	return func(r *runner) {
+		if len(pattern) > 0 {
+			r.pattern = append([]string(nil), pattern...)
+		}
+	}
+}
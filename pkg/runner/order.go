@@ -0,0 +1,77 @@
+package runner
+
+import (
+	"sort"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/client"
+	"github.com/kaitoy/zundoko-go-client/pkg/logging"
+	"github.com/kaitoy/zundoko-go-client/pkg/model"
+)
+
+// SortZundokos sorts the Zundokos in the order they were said.
+// Zundokos with Sequence come first in the order of it, since it's assigned by Zundoko Server and
+// free from clock skew between clients. Zero Sequence means it's absent.
+// The rest follow in the order of SaidAt, and then of Id, so that the order is deterministic
+// even for equal timestamps.
+func SortZundokos(zundokos []model.Zundoko) {
+	sort.SliceStable(zundokos, func(i, j int) bool {
+		return zundokoBefore(zundokos[i], zundokos[j])
+	})
+}
+
+// zundokoBefore tells if a is ordered before b. It's a strict total order on the key of
+// (having no Sequence, Sequence, SaidAt, Id), which sort requires even for a mix of Zundokos
+// with and without Sequence.
+func zundokoBefore(a, b model.Zundoko) bool {
+	aSequenced, bSequenced := a.Sequence > 0, b.Sequence > 0
+	if aSequenced != bSequenced {
+		return aSequenced
+	}
+	if a.Sequence != b.Sequence {
+		return a.Sequence < b.Sequence
+	}
+	if !a.SaidAt.Equal(b.SaidAt) {
+		return a.SaidAt.Before(b.SaidAt)
+	}
+	return a.Id < b.Id
+}
+
+// sortedZundokos returns a copy of the Zundokos sorted by SortZundokos.
+func sortedZundokos(zundokos []model.Zundoko) []model.Zundoko {
+	sorted := make([]model.Zundoko, len(zundokos))
+	copy(sorted, zundokos)
+	SortZundokos(sorted)
+	return sorted
+}
+
+// clockSkewThreshold is the clock skew against Zundoko Server to warn about.
+// It has a margin since the Date header has only a precision of seconds.
+const clockSkewThreshold = 2 * time.Second
+
+// checkClockSkew records the clock skew against Zundoko Server to the result once in a session,
+// and warns if it's beyond the threshold, which could order words wrongly by SaidAt.
+// It does nothing if the Client isn't a client.DateReporter or hasn't got a Date header yet.
+func (r *runner) checkClockSkew(s *session) {
+	if s.skewChecked {
+		return
+	}
+	reporter, ok := r.cl.(client.DateReporter)
+	if !ok {
+		return
+	}
+	date, receivedAt, ok := reporter.ServerDate()
+	if !ok {
+		return
+	}
+
+	s.skewChecked = true
+	skew := receivedAt.Sub(date)
+	s.result.ClockSkew = skew
+	if skew > clockSkewThreshold || skew < -clockSkewThreshold {
+		logging.GetLogger().Warnw(
+			"The local clock seems to be skewed from Zundoko Server's. Words without sequence may be ordered wrongly.",
+			"skew", skew,
+		)
+	}
+}
@@ -0,0 +1,127 @@
+package runner
+
+import (
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/kaitoy/zundoko-go-client/mock/pkg/mock_client"
+	"github.com/kaitoy/zundoko-go-client/pkg/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// dateReportingClient is a Client that reports a fixed server date.
+type dateReportingClient struct {
+	*mock_client.MockClient
+	date, receivedAt time.Time
+}
+
+func (c *dateReportingClient) ServerDate() (date, receivedAt time.Time, ok bool) {
+	return c.date, c.receivedAt, !c.receivedAt.IsZero()
+}
+
+var _ = Describe("SortZundokos()", func() {
+	base := time.Date(2021, 1, 1, 1, 50, 0, 0, time.UTC)
+
+	It("orders Zundokos by sequence over SaidAt.", func() {
+		zundokos := []model.Zundoko{
+			{Id: "a", Sequence: 2, SaidAt: base},
+			{Id: "b", Sequence: 3, SaidAt: base.Add(-time.Second)},
+			{Id: "c", Sequence: 1, SaidAt: base.Add(time.Second)},
+		}
+
+		SortZundokos(zundokos)
+
+		Expect([]string{zundokos[0].Id, zundokos[1].Id, zundokos[2].Id}).To(Equal([]string{"c", "a", "b"}))
+	})
+
+	It("falls back to SaidAt and then Id without sequence.", func() {
+		zundokos := []model.Zundoko{
+			{Id: "c", SaidAt: base},
+			{Id: "a", SaidAt: base.Add(time.Second)},
+			{Id: "b", SaidAt: base},
+		}
+
+		SortZundokos(zundokos)
+
+		Expect([]string{zundokos[0].Id, zundokos[1].Id, zundokos[2].Id}).To(Equal([]string{"b", "c", "a"}))
+	})
+
+	It("orders Zundokos with sequence before ones without it in a mixed list.", func() {
+		// By sequence only between sequenced ones, a < c, c < b, and b < a would make a cycle.
+		a := model.Zundoko{Id: "a", Sequence: 1, SaidAt: base.Add(2 * time.Second)}
+		b := model.Zundoko{Id: "b", SaidAt: base.Add(time.Second)}
+		c := model.Zundoko{Id: "c", Sequence: 2, SaidAt: base}
+		d := model.Zundoko{Id: "d", SaidAt: base}
+		expected := []model.Zundoko{a, c, d, b}
+
+		for _, zundokos := range [][]model.Zundoko{
+			{a, b, c, d},
+			{b, c, d, a},
+			{c, d, a, b},
+			{d, c, b, a},
+			{b, a, d, c},
+		} {
+			SortZundokos(zundokos)
+
+			Expect(zundokos).To(Equal(expected))
+		}
+	})
+
+	It("gives the same order for any input order of equal timestamps.", func() {
+		zundokos := []model.Zundoko{{Id: "x", SaidAt: base}, {Id: "y", SaidAt: base}, {Id: "z", SaidAt: base}}
+		reversed := []model.Zundoko{zundokos[2], zundokos[1], zundokos[0]}
+
+		SortZundokos(zundokos)
+		SortZundokos(reversed)
+
+		Expect(reversed).To(Equal(zundokos))
+	})
+})
+
+var _ = Describe("checkClockSkew()", func() {
+	var (
+		mockCtrl *gomock.Controller
+		cl       *dateReportingClient
+		s        *session
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		cl = &dateReportingClient{MockClient: mock_client.NewMockClient(mockCtrl)}
+		s = &session{result: newResult(time.Now())}
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	It("records the skew against the server date once.", func() {
+		cl.date = time.Date(2021, 1, 1, 1, 50, 0, 0, time.UTC)
+		cl.receivedAt = cl.date.Add(5 * time.Second)
+		r := NewRunner(cl).(*runner)
+
+		r.checkClockSkew(s)
+		cl.receivedAt = cl.date
+		r.checkClockSkew(s)
+
+		Expect(s.result.ClockSkew).To(Equal(5 * time.Second))
+	})
+
+	It("waits for a server date.", func() {
+		r := NewRunner(cl).(*runner)
+
+		r.checkClockSkew(s)
+
+		Expect(s.skewChecked).To(BeFalse())
+		Expect(s.result.ClockSkew).To(BeZero())
+	})
+
+	It("does nothing for a Client that doesn't report the server date.", func() {
+		r := NewRunner(cl.MockClient).(*runner)
+
+		r.checkClockSkew(s)
+
+		Expect(s.skewChecked).To(BeFalse())
+	})
+})
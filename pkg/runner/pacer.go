@@ -0,0 +1,77 @@
+package runner
+
+import (
+	"fmt"
+	"time"
+)
+
+// Pacer decides when to say the next word in a Zundoko Kiyoshi session.
+type Pacer interface {
+	// Next returns the time to say the next word, given the time the session started and the current time.
+	// A time not after now means saying it right away.
+	Next(start, now time.Time) time.Time
+}
+
+// NewFixedPacer creates a Pacer that says words at the fixed interval after the previous one.
+func NewFixedPacer(interval time.Duration) Pacer {
+	return fixedPacer(interval)
+}
+
+type fixedPacer time.Duration
+
+func (p fixedPacer) Next(start, now time.Time) time.Time {
+	return now.Add(time.Duration(p))
+}
+
+// NewJitterPacer creates a Pacer that says words at the interval after the previous one,
+// randomly shifted within ±jitter. The shifted interval is never negative.
+// If rnd is nil, the top-level funcs of math/rand are used.
+func NewJitterPacer(interval, jitter time.Duration, rnd Rand) Pacer {
+	return &jitterPacer{interval, jitter, orGlobalRand(rnd)}
+}
+
+type jitterPacer struct {
+	interval time.Duration
+	jitter   time.Duration
+	rnd      Rand
+}
+
+func (p *jitterPacer) Next(start, now time.Time) time.Time {
+	interval := p.interval
+	if p.jitter > 0 {
+		interval += time.Duration(p.rnd.Int63n(int64(2*p.jitter)+1)) - p.jitter
+	}
+	if interval < 0 {
+		interval = 0
+	}
+	return now.Add(interval)
+}
+
+// NewTempoPacer creates a Pacer that says words on the beats of the tempo in beats per minute counted from
+// the start of the session, so that the chant follows the rhythm of a song even if API calls take time.
+// A beat missed by slow API calls is skipped and the word is said on the next one.
+func NewTempoPacer(bpm float64) (Pacer, error) {
+	if bpm <= 0 {
+		return nil, fmt.Errorf("tempo must be positive: %v", bpm)
+	}
+	return tempoPacer(time.Duration(float64(time.Minute) / bpm)), nil
+}
+
+type tempoPacer time.Duration
+
+func (p tempoPacer) Next(start, now time.Time) time.Time {
+	beat := time.Duration(p)
+	beats := now.Sub(start)/beat + 1
+	return start.Add(beats * beat)
+}
+
+// NewFastPacer creates a Pacer that says words as fast as possible.
+func NewFastPacer() Pacer {
+	return fastPacer{}
+}
+
+type fastPacer struct{}
+
+func (fastPacer) Next(start, now time.Time) time.Time {
+	return now
+}
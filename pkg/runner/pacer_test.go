@@ -0,0 +1,67 @@
+package runner
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Pacer", func() {
+	start := time.Date(2021, 1, 1, 1, 50, 0, 0, time.UTC)
+	now := start.Add(1700 * time.Millisecond)
+
+	Describe("NewFixedPacer()", func() {
+		It("says the next word at the interval from now.", func() {
+			Expect(NewFixedPacer(time.Second).Next(start, now)).To(Equal(now.Add(time.Second)))
+		})
+	})
+
+	Describe("NewJitterPacer()", func() {
+		It("says the next word at the interval shifted within the jitter.", func() {
+			pacer := NewJitterPacer(time.Second, 200*time.Millisecond, nil)
+			for i := 0; i < 100; i++ {
+				next := pacer.Next(start, now)
+				Expect(next).To(BeTemporally(">=", now.Add(800*time.Millisecond)))
+				Expect(next).To(BeTemporally("<=", now.Add(1200*time.Millisecond)))
+			}
+		})
+
+		It("shifts the interval with the Rand.", func() {
+			pacer := NewJitterPacer(time.Second, 200*time.Millisecond, &fakeRand{values: []int{0, 400000000}})
+
+			Expect(pacer.Next(start, now)).To(Equal(now.Add(800 * time.Millisecond)))
+			Expect(pacer.Next(start, now)).To(Equal(now.Add(1200 * time.Millisecond)))
+		})
+
+		It("never says the next word in the past.", func() {
+			pacer := NewJitterPacer(0, time.Second, nil)
+			for i := 0; i < 100; i++ {
+				Expect(pacer.Next(start, now)).To(BeTemporally(">=", now))
+			}
+		})
+	})
+
+	Describe("NewTempoPacer()", func() {
+		It("says the next word on the next beat from the start.", func() {
+			pacer, err := NewTempoPacer(120)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pacer.Next(start, start)).To(Equal(start.Add(500 * time.Millisecond)))
+			Expect(pacer.Next(start, now)).To(Equal(start.Add(2 * time.Second)))
+			Expect(pacer.Next(start, start.Add(2*time.Second))).To(Equal(start.Add(2500 * time.Millisecond)))
+		})
+
+		It("fails for a non-positive tempo.", func() {
+			_, err := NewTempoPacer(0)
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("NewFastPacer()", func() {
+		It("says the next word right away.", func() {
+			Expect(NewFastPacer().Next(start, now)).To(Equal(now))
+		})
+	})
+})
@@ -0,0 +1,111 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/model"
+)
+
+// Presenter presents words said in a Zundoko Kiyoshi.
+type Presenter interface {
+	// PresentZundoko presents a posted Zundoko. index is its 1-origin position in the session.
+	PresentZundoko(zundoko *model.Zundoko, index int)
+
+	// PresentKiyoshi presents a posted Kiyoshi. index is its 1-origin position in the session.
+	PresentKiyoshi(kiyoshi *model.Kiyoshi, index int)
+}
+
+// kiyoshiWord is the word presented for a Kiyoshi.
+const kiyoshiWord = "Ki Yo Shi !"
+
+// NewTextPresenter creates a Presenter that writes words in plain text lines.
+func NewTextPresenter(w io.Writer) Presenter {
+	return &textPresenter{w}
+}
+
+type textPresenter struct {
+	w io.Writer
+}
+
+func (p *textPresenter) PresentZundoko(zundoko *model.Zundoko, index int) {
+	fmt.Fprintln(p.w, zundoko.Word)
+}
+
+func (p *textPresenter) PresentKiyoshi(kiyoshi *model.Kiyoshi, index int) {
+	fmt.Fprintln(p.w, kiyoshiWord)
+}
+
+// JSONLine is a line written by a Presenter created by NewJSONLinesPresenter.
+type JSONLine struct {
+	// Word is the word of a Zundoko, or "Kiyoshi".
+	Word string `json:"word"`
+
+	// Id is the id of the Zundoko or Kiyoshi.
+	Id string `json:"id"`
+
+	// Timestamp is the time the word was said.
+	Timestamp time.Time `json:"timestamp"`
+
+	// Index is the 1-origin position of the word in the session.
+	Index int `json:"index"`
+}
+
+// NewJSONLinesPresenter creates a Presenter that writes words in JSON lines of JSONLine.
+func NewJSONLinesPresenter(w io.Writer) Presenter {
+	return &jsonLinesPresenter{json.NewEncoder(w)}
+}
+
+type jsonLinesPresenter struct {
+	encoder *json.Encoder
+}
+
+func (p *jsonLinesPresenter) PresentZundoko(zundoko *model.Zundoko, index int) {
+	p.encoder.Encode(JSONLine{zundoko.Word, zundoko.Id, zundoko.SaidAt, index})
+}
+
+func (p *jsonLinesPresenter) PresentKiyoshi(kiyoshi *model.Kiyoshi, index int) {
+	p.encoder.Encode(JSONLine{"Kiyoshi", kiyoshi.Id, kiyoshi.SaidAt, index})
+}
+
+// ANSI escape sequences to colorize words.
+const (
+	colorZun     = "\x1b[32m"
+	colorDoko    = "\x1b[33m"
+	colorKiyoshi = "\x1b[1;35m"
+	colorReset   = "\x1b[0m"
+)
+
+// NewColorPresenter creates a Presenter that writes words in text lines colorized for a TTY.
+func NewColorPresenter(w io.Writer) Presenter {
+	return &colorPresenter{w}
+}
+
+type colorPresenter struct {
+	w io.Writer
+}
+
+func (p *colorPresenter) PresentZundoko(zundoko *model.Zundoko, index int) {
+	color := colorZun
+	if zundoko.Word == "Doko" {
+		color = colorDoko
+	}
+	fmt.Fprintln(p.w, color+zundoko.Word+colorReset)
+}
+
+func (p *colorPresenter) PresentKiyoshi(kiyoshi *model.Kiyoshi, index int) {
+	fmt.Fprintln(p.w, colorKiyoshi+kiyoshiWord+colorReset)
+}
+
+// NewSilentPresenter creates a Presenter that presents nothing.
+func NewSilentPresenter() Presenter {
+	return silentPresenter{}
+}
+
+type silentPresenter struct{}
+
+func (silentPresenter) PresentZundoko(zundoko *model.Zundoko, index int) {}
+
+func (silentPresenter) PresentKiyoshi(kiyoshi *model.Kiyoshi, index int) {}
@@ -0,0 +1,89 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Presenter", func() {
+	var (
+		output  *bytes.Buffer
+		zun     *model.Zundoko
+		doko    *model.Zundoko
+		kiyoshi *model.Kiyoshi
+	)
+
+	BeforeEach(func() {
+		output = new(bytes.Buffer)
+		zun = &model.Zundoko{
+			Id:     "91259080-1984-4a87-a671-f6adb641ef52",
+			SaidAt: time.Date(2021, 1, 1, 1, 50, 0, 0, time.UTC),
+			Word:   "Zun",
+		}
+		doko = &model.Zundoko{
+			Id:     "0e8f5ad2-7a8e-4b53-9f4e-1c0e8f6c5a10",
+			SaidAt: time.Date(2021, 1, 1, 1, 50, 1, 0, time.UTC),
+			Word:   "Doko",
+		}
+		kiyoshi = &model.Kiyoshi{
+			Id:     "5e2b8a3c-2f4d-4c1e-8a6b-9d7f0e1c2b3a",
+			SaidAt: time.Date(2021, 1, 1, 1, 50, 2, 0, time.UTC),
+		}
+	})
+
+	present := func(p Presenter) {
+		p.PresentZundoko(zun, 1)
+		p.PresentZundoko(doko, 2)
+		p.PresentKiyoshi(kiyoshi, 3)
+	}
+
+	Describe("NewTextPresenter()", func() {
+		It("writes words in plain text lines.", func() {
+			present(NewTextPresenter(output))
+
+			Expect(output.String()).To(Equal("Zun\nDoko\nKi Yo Shi !\n"))
+		})
+	})
+
+	Describe("NewJSONLinesPresenter()", func() {
+		It("writes words with their ids, timestamps, and indexes in JSON lines.", func() {
+			present(NewJSONLinesPresenter(output))
+
+			var lines []JSONLine
+			for _, l := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+				var line JSONLine
+				Expect(json.Unmarshal([]byte(l), &line)).To(Succeed())
+				lines = append(lines, line)
+			}
+			Expect(lines).To(Equal([]JSONLine{
+				{"Zun", zun.Id, zun.SaidAt, 1},
+				{"Doko", doko.Id, doko.SaidAt, 2},
+				{"Kiyoshi", kiyoshi.Id, kiyoshi.SaidAt, 3},
+			}))
+		})
+	})
+
+	Describe("NewColorPresenter()", func() {
+		It("writes words in text lines colorized by ANSI escape sequences.", func() {
+			present(NewColorPresenter(output))
+
+			Expect(output.String()).To(Equal(
+				"\x1b[32mZun\x1b[0m\n\x1b[33mDoko\x1b[0m\n\x1b[1;35mKi Yo Shi !\x1b[0m\n",
+			))
+		})
+	})
+
+	Describe("NewSilentPresenter()", func() {
+		It("presents nothing.", func() {
+			present(NewSilentPresenter())
+
+			Expect(output.Len()).To(Equal(0))
+		})
+	})
+})
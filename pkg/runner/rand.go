@@ -0,0 +1,52 @@
+package runner
+
+import (
+	"math/rand"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/util"
+)
+
+// Rand generates pseudo-random numbers. *rand.Rand of math/rand satisfies it.
+type Rand interface {
+	// Intn returns a non-negative pseudo-random number in [0,n).
+	Intn(n int) int
+
+	// Int63n returns a non-negative pseudo-random number in [0,n).
+	Int63n(n int64) int64
+}
+
+// globalRand is a Rand backed by the top-level funcs of math/rand.
+type globalRand struct{}
+
+func (globalRand) Intn(n int) int {
+	return rand.Intn(n)
+}
+
+func (globalRand) Int63n(n int64) int64 {
+	return rand.Int63n(n)
+}
+
+// orGlobalRand returns rnd, or a Rand backed by the top-level funcs of math/rand if rnd is nil.
+func orGlobalRand(rnd Rand) Rand {
+	if rnd == nil {
+		return globalRand{}
+	}
+	return rnd
+}
+
+// IDGenerator generates ids of Zundokos and Kiyoshies.
+type IDGenerator interface {
+	// NewID returns a new unique id.
+	NewID() string
+}
+
+// NewUUIDGenerator creates an IDGenerator that generates UUIDv4 strings.
+func NewUUIDGenerator() IDGenerator {
+	return uuidGenerator{}
+}
+
+type uuidGenerator struct{}
+
+func (uuidGenerator) NewID() string {
+	return util.NewUUID().String()
+}
@@ -0,0 +1,203 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/client"
+	"github.com/kaitoy/zundoko-go-client/pkg/model"
+)
+
+// Result represents the result of a Zundoko Kiyoshi session.
+type Result struct {
+	// StartedAt is the time the session started.
+	StartedAt time.Time `json:"startedAt"`
+
+	// Elapsed is the time the session took.
+	Elapsed time.Duration `json:"elapsed"`
+
+	// Attempts is the number of iterations, each of which got Zundokos to check if ready to go Kiyoshi.
+	Attempts int `json:"attempts"`
+
+	// Zundokos is the Zundokos posted in the session in order.
+	Zundokos []model.Zundoko `json:"zundokos"`
+
+	// Kiyoshi is the Kiyoshi posted in the session, or nil if the session ended without it.
+	Kiyoshi *model.Kiyoshi `json:"kiyoshi,omitempty"`
+
+	// Duplicate tells the Kiyoshi was rejected as a duplicate of another one and the session yielded to it.
+	Duplicate bool `json:"duplicate,omitempty"`
+
+	// Calls is the statistics of API calls in the session for each operation.
+	Calls map[client.Operation]CallStats `json:"calls"`
+
+	// ClockSkew is how far the local clock was ahead of Zundoko Server's, measured by the Date header of a response.
+	// It's zero if not measured.
+	ClockSkew time.Duration `json:"clockSkew,omitempty"`
+}
+
+// CallStats represents statistics of API calls.
+type CallStats struct {
+	// Count is the number of the calls.
+	Count int `json:"count"`
+
+	// Errors is the number of the calls that failed.
+	Errors int `json:"errors"`
+
+	// Latencies is the latency of each call in order.
+	Latencies []time.Duration `json:"latencies"`
+}
+
+// Mean returns the mean latency of the calls.
+func (s CallStats) Mean() time.Duration {
+	if len(s.Latencies) == 0 {
+		return 0
+	}
+	var sum time.Duration
+	for _, l := range s.Latencies {
+		sum += l
+	}
+	return sum / time.Duration(len(s.Latencies))
+}
+
+// Max returns the maximum latency of the calls.
+func (s CallStats) Max() time.Duration {
+	var max time.Duration
+	for _, l := range s.Latencies {
+		if l > max {
+			max = l
+		}
+	}
+	return max
+}
+
+// add merges the other statistics into a copy of s.
+func (s CallStats) add(other CallStats) CallStats {
+	latencies := make([]time.Duration, 0, len(s.Latencies)+len(other.Latencies))
+	latencies = append(latencies, s.Latencies...)
+	latencies = append(latencies, other.Latencies...)
+	return CallStats{
+		Count:     s.Count + other.Count,
+		Errors:    s.Errors + other.Errors,
+		Latencies: latencies,
+	}
+}
+
+func newResult(startedAt time.Time) *Result {
+	return &Result{
+		StartedAt: startedAt,
+		Zundokos:  []model.Zundoko{},
+		Calls:     make(map[client.Operation]CallStats),
+	}
+}
+
+// record adds an API call of the operation to the result.
+func (r *Result) record(operation client.Operation, latency time.Duration, err error) {
+	stats := r.Calls[operation]
+	stats.Count++
+	if err != nil {
+		stats.Errors++
+	}
+	stats.Latencies = append(stats.Latencies, latency)
+	r.Calls[operation] = stats
+}
+
+// WriteText writes the result in a human-readable text format.
+func (r *Result) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	words := make([]string, 0, len(r.Zundokos)+1)
+	for _, zd := range r.Zundokos {
+		words = append(words, zd.Word)
+	}
+	if r.Kiyoshi != nil {
+		words = append(words, kiyoshiWord)
+	}
+	fmt.Fprintf(tw, "Words:\t%s\n", strings.Join(words, " "))
+	if r.Duplicate {
+		fmt.Fprintln(tw, "Kiyoshi:\tyielded to a duplicate")
+	}
+	fmt.Fprintf(tw, "Attempts:\t%d\n", r.Attempts)
+	fmt.Fprintf(tw, "Elapsed:\t%s\n", r.Elapsed.Round(time.Millisecond))
+	writeCalls(tw, r.Calls)
+	return tw.Flush()
+}
+
+// WriteJSON writes the result in JSON. Durations are in nanoseconds.
+func (r *Result) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// Summary represents aggregated results of Zundoko Kiyoshi sessions.
+type Summary struct {
+	// Sessions is the number of the sessions.
+	Sessions int `json:"sessions"`
+
+	// Kiyoshies is the number of the sessions that made a Kiyoshi.
+	Kiyoshies int `json:"kiyoshies"`
+
+	// Zundokos is the total number of Zundokos posted in the sessions.
+	Zundokos int `json:"zundokos"`
+
+	// Attempts is the total number of iterations of the sessions.
+	Attempts int `json:"attempts"`
+
+	// Elapsed is the total time the sessions took.
+	Elapsed time.Duration `json:"elapsed"`
+
+	// Calls is the statistics of API calls in all the sessions for each operation.
+	Calls map[client.Operation]CallStats `json:"calls"`
+}
+
+// Summarize aggregates the results. nil results are ignored.
+func Summarize(results []*Result) Summary {
+	summary := Summary{Calls: make(map[client.Operation]CallStats)}
+	for _, r := range results {
+		if r == nil {
+			continue
+		}
+		summary.Sessions++
+		if r.Kiyoshi != nil {
+			summary.Kiyoshies++
+		}
+		summary.Zundokos += len(r.Zundokos)
+		summary.Attempts += r.Attempts
+		summary.Elapsed += r.Elapsed
+		for op, stats := range r.Calls {
+			summary.Calls[op] = summary.Calls[op].add(stats)
+		}
+	}
+	return summary
+}
+
+func writeCalls(w io.Writer, calls map[client.Operation]CallStats) {
+	if len(calls) == 0 {
+		return
+	}
+	operations := make([]client.Operation, 0, len(calls))
+	for op := range calls {
+		operations = append(operations, op)
+	}
+	sort.Slice(operations, func(i, j int) bool { return operations[i] < operations[j] })
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Calls\tcount\terrors\tmean\tmax")
+	for _, op := range operations {
+		stats := calls[op]
+		fmt.Fprintf(
+			w,
+			"%s\t%d\t%d\t%s\t%s\n",
+			op,
+			stats.Count,
+			stats.Errors,
+			stats.Mean().Round(time.Microsecond),
+			stats.Max().Round(time.Microsecond),
+		)
+	}
+}
@@ -0,0 +1,103 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/client"
+	"github.com/kaitoy/zundoko-go-client/pkg/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Result", func() {
+	var result *Result
+
+	BeforeEach(func() {
+		result = newResult(time.Date(2021, 1, 1, 1, 50, 0, 0, time.UTC))
+		result.Elapsed = 1500 * time.Millisecond
+		result.Attempts = 3
+		result.Zundokos = []model.Zundoko{{Id: "z1", Word: "Zun"}, {Id: "z2", Word: "Doko"}}
+		result.Kiyoshi = &model.Kiyoshi{Id: "k1"}
+		result.record(client.OperationGetZundokos, 10*time.Millisecond, nil)
+		result.record(client.OperationGetZundokos, 30*time.Millisecond, errors.New("some error"))
+		result.record(client.OperationPostKiyoshi, 5*time.Millisecond, nil)
+	})
+
+	Describe("record()", func() {
+		It("counts calls and errors, and keeps latencies for each operation.", func() {
+			stats := result.Calls[client.OperationGetZundokos]
+
+			Expect(stats.Count).To(Equal(2))
+			Expect(stats.Errors).To(Equal(1))
+			Expect(stats.Latencies).To(Equal([]time.Duration{10 * time.Millisecond, 30 * time.Millisecond}))
+			Expect(stats.Mean()).To(Equal(20 * time.Millisecond))
+			Expect(stats.Max()).To(Equal(30 * time.Millisecond))
+		})
+	})
+
+	Describe("WriteText()", func() {
+		It("writes the words, attempts, elapsed time, and calls.", func() {
+			output := new(bytes.Buffer)
+
+			Expect(result.WriteText(output)).To(Succeed())
+
+			Expect(output.String()).To(Equal(
+				"Words:     Zun Doko Ki Yo Shi !\n" +
+					"Attempts:  3\n" +
+					"Elapsed:   1.5s\n" +
+					"\n" +
+					"Calls        count  errors  mean  max\n" +
+					"GetZundokos  2      1       20ms  30ms\n" +
+					"PostKiyoshi  1      0       5ms   5ms\n",
+			))
+		})
+	})
+
+	Describe("WriteJSON()", func() {
+		It("writes the result in JSON.", func() {
+			output := new(bytes.Buffer)
+
+			Expect(result.WriteJSON(output)).To(Succeed())
+
+			var decoded Result
+			Expect(json.Unmarshal(output.Bytes(), &decoded)).To(Succeed())
+			Expect(decoded.Attempts).To(Equal(3))
+			Expect(decoded.Elapsed).To(Equal(1500 * time.Millisecond))
+			Expect(decoded.Zundokos).To(HaveLen(2))
+			Expect(decoded.Kiyoshi.Id).To(Equal("k1"))
+			Expect(decoded.Calls[client.OperationGetZundokos].Latencies).To(HaveLen(2))
+		})
+	})
+})
+
+var _ = Describe("Summarize()", func() {
+	It("aggregates results.", func() {
+		first := newResult(time.Now())
+		first.Elapsed = time.Second
+		first.Attempts = 2
+		first.Zundokos = []model.Zundoko{{Word: "Zun"}}
+		first.Kiyoshi = &model.Kiyoshi{}
+		first.record(client.OperationGetZundokos, time.Millisecond, nil)
+		second := newResult(time.Now())
+		second.Elapsed = 2 * time.Second
+		second.Attempts = 1
+		second.record(client.OperationGetZundokos, 3*time.Millisecond, errors.New("some error"))
+
+		summary := Summarize([]*Result{first, nil, second})
+
+		Expect(summary.Sessions).To(Equal(2))
+		Expect(summary.Kiyoshies).To(Equal(1))
+		Expect(summary.Zundokos).To(Equal(1))
+		Expect(summary.Attempts).To(Equal(3))
+		Expect(summary.Elapsed).To(Equal(3 * time.Second))
+		Expect(summary.Calls[client.OperationGetZundokos]).To(Equal(CallStats{
+			Count:     2,
+			Errors:    1,
+			Latencies: []time.Duration{time.Millisecond, 3 * time.Millisecond},
+		}))
+		Expect(first.Calls[client.OperationGetZundokos].Latencies).To(HaveLen(1))
+	})
+})
@@ -1,92 +1,279 @@
 package runner
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"math/rand"
-	"sort"
-	"strings"
+	"os"
 	"time"
 
 	"github.com/kaitoy/zundoko-go-client/pkg/client"
 	"github.com/kaitoy/zundoko-go-client/pkg/logging"
 	"github.com/kaitoy/zundoko-go-client/pkg/model"
-	"github.com/kaitoy/zundoko-go-client/pkg/util"
+	"github.com/kaitoy/zundoko-go-client/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Runner starts a Zundoko Kiyoshi.
 type Runner interface {
-	// Run starts a Zundoko Kiyoshi.
-	Run(intervalMillis time.Duration) error
+	// Run starts a Zundoko Kiyoshi and returns the result of it.
+	// The result is returned even with an error, holding what was done until the error.
+	// The whole session is traced as a span, which is a child of the span in the given context if any.
+	// If the Client is a CircuitBreaker and it's open, Run fails fast with an error wrapping client.ErrCircuitOpen.
+	// If the WordSource runs out of words before a Kiyoshi, Run returns an error wrapping io.EOF.
+	// Words are said at the pace of the Pacer, and waiting for it is interrupted when ctx is done.
+	Run(ctx context.Context) (*Result, error)
 }
 
 type runner struct {
-	cl client.Client
+	cl        client.Client
+	presenter Presenter
+	source    WordSource
+	pacer     Pacer
+	clock     Clock
+	rnd       Rand
+	ids       IDGenerator
+	pattern   []string
+	local     *LocalDetection
+	consensus *Consensus
+	observers []Observer
+
+	// observer notifies the logging and presenting Observers and ones given by options.
+	observer Observer
 }
 
 // NewRunner creates a Runner instance.
-func NewRunner(cl client.Client) Runner {
-	return &runner{cl}
+func NewRunner(cl client.Client, opts ...Option) Runner {
+	r := &runner{
+		cl:        cl,
+		presenter: NewTextPresenter(os.Stdout),
+		pacer:     NewFixedPacer(time.Second),
+		clock:     NewRealClock(),
+		ids:       NewUUIDGenerator(),
+		pattern:   Pattern(),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.source == nil {
+		r.source = NewRandomWordSource(r.rnd)
+	}
+	r.observer = append(observers{NewPresentingObserver(r.presenter)}, r.observers...)
+	return r
 }
 
-func (r *runner) Run(intervalMillis time.Duration) error {
-	defer logging.GetLogger().Sync()
-	logging.GetLogger().Info("Start Zundoko Kiyoshi.")
+func (r *runner) Run(ctx context.Context) (result *Result, err error) {
+	ctx, span := tracing.GetTracer().Start(ctx, "Zundoko Kiyoshi")
+	defer func() { tracing.EndSpan(span, err) }()
 
-	for {
-		zundokos, err := r.cl.GetZundokos()
+	result = newResult(r.clock.Now())
+	s := &session{result: result, own: make(map[string]bool)}
+	if r.local != nil {
+		s.ring = newWordRing(r.pattern)
+	}
+	r.observer.OnStart()
+	defer func() {
+		result.Elapsed = r.clock.Now().Sub(result.StartedAt)
 		if err != nil {
-			return fmt.Errorf("failed to get Zundokos: %w", err)
+			r.observer.OnError(err)
 		}
-		if isReadyToKiyoshi(zundokos) {
+		r.observer.OnFinish(result)
+	}()
+
+	iteration := 1
+	for ; ; iteration++ {
+		result.Attempts = iteration
+		ready, err := r.iterate(ctx, iteration, s)
+		if err != nil {
+			return result, err
+		}
+		if ready {
 			break
 		}
 
-		word := "Zun"
-		if rand.Intn(10) < 5 {
-			word = "Doko"
+		if err := r.pace(ctx, result.StartedAt); err != nil {
+			return result, err
+		}
+	}
+
+	if err := r.pace(ctx, result.StartedAt); err != nil {
+		return result, err
+	}
+
+	return result, r.kiyoshi(ctx, iteration, s)
+}
+
+// session holds the state of a Zundoko Kiyoshi session.
+type session struct {
+	result *Result
+
+	// ring keeps the last words with LocalDetection, or nil without it.
+	ring *wordRing
+
+	// own holds ids of Zundokos posted in the session.
+	own map[string]bool
+
+	// old holds ids of Zundokos that existed at the start of the session, or nil before getting them.
+	old map[string]bool
+
+	// skewChecked tells if the clock skew against Zundoko Server has been checked.
+	skewChecked bool
+}
+
+// isReady tells if ready to go Kiyoshi by the Zundokos got from Zundoko Server, following the Consensus if any.
+func (r *runner) isReady(zundokos []model.Zundoko, s *session) bool {
+	if r.consensus == nil {
+		return isReadyToKiyoshi(zundokos, r.pattern)
+	}
+
+	if s.old == nil {
+		s.old = make(map[string]bool, len(zundokos))
+		for _, zd := range zundokos {
+			s.old[zd.Id] = true
+		}
+	}
+	return r.consensus.claimable(zundokos, s.own, s.old, r.pattern)
+}
+
+// pace waits until the time to say the next word decided by the Pacer, or until ctx is done.
+func (r *runner) pace(ctx context.Context, start time.Time) error {
+	now := r.clock.Now()
+	wait := r.pacer.Next(start, now).Sub(now)
+	if wait <= 0 {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-r.clock.After(wait):
+		return nil
+	}
+}
+
+// iterate gets Zundokos and posts a new one if not ready to go Kiyoshi, recording them to the result.
+// With LocalDetection, Zundokos are got only when the ring needs to be synced, and the ring tells if ready otherwise.
+// It's traced as a child span of the session.
+func (r *runner) iterate(ctx context.Context, iteration int, s *session) (ready bool, err error) {
+	ctx, span := tracing.GetTracer().Start(
+		ctx,
+		"Zundoko iteration",
+		trace.WithAttributes(attribute.Int("zundoko.iteration", iteration)),
+	)
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if s.ring != nil && !s.ring.needsSync(r.local, r.clock.Now()) {
+		ready = s.ring.matches()
+	} else {
+		var zundokos []model.Zundoko
+		err = r.call(s.result, client.OperationGetZundokos, func() (err error) {
+			zundokos, err = r.cl.GetZundokos(ctx)
+			return err
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to get Zundokos: %w", err)
 		}
-		if err = r.cl.PostZundoko(
-			&model.Zundoko{
-				Id:     util.NewUUID().String(),
-				SaidAt: time.Now(),
-				Word:   word,
-			},
-		); err != nil {
-			return fmt.Errorf("failed to create a Zundoko: %w", err)
+		r.checkClockSkew(s)
+		r.observer.OnFetch(zundokos)
+		if s.ring != nil {
+			s.ring.sync(r.countedZundokos(zundokos, s), r.clock.Now())
 		}
-		fmt.Println(word)
+		ready = r.isReady(zundokos, s)
+	}
+	if ready {
+		return true, nil
+	}
 
-		time.Sleep(intervalMillis * time.Millisecond)
+	word, err := r.source.NextWord(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get a word: %w", err)
+	}
+	span.SetAttributes(attribute.String("zundoko.word", word))
+	zundoko := &model.Zundoko{
+		Id:     r.ids.NewID(),
+		SaidAt: r.clock.Now(),
+		Word:   word,
+	}
+	if err = r.call(s.result, client.OperationPostZundoko, func() error {
+		return r.cl.PostZundoko(ctx, zundoko)
+	}); err != nil {
+		if s.ring != nil && errors.Is(err, client.ErrConflict) {
+			logging.GetLogger().Warnw("A Zundoko conflicted. Resync words.", "id", zundoko.Id, "err", err)
+			s.ring.invalidate()
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to create a Zundoko: %w", err)
 	}
+	s.own[zundoko.Id] = true
+	if s.ring != nil {
+		s.ring.push(word)
+	}
+	s.result.Zundokos = append(s.result.Zundokos, *zundoko)
+	r.observer.OnWord(zundoko, iteration)
+
+	return false, nil
+}
 
-	time.Sleep(intervalMillis * time.Millisecond)
+// kiyoshi posts a Kiyoshi as the index-th word of the session, recording it to the result.
+// If it's rejected as a duplicate and the Consensus says to yield, it returns nil.
+// It's traced as a child span of the session.
+func (r *runner) kiyoshi(ctx context.Context, index int, s *session) (err error) {
+	ctx, span := tracing.GetTracer().Start(ctx, "Kiyoshi")
+	defer func() { tracing.EndSpan(span, err) }()
 
-	if err := r.cl.PostKiyoshi(
-		&model.Kiyoshi{
-			Id:     util.NewUUID().String(),
-			SaidAt: time.Now(),
-		},
-	); err != nil {
+	kiyoshi := &model.Kiyoshi{
+		Id:     r.ids.NewID(),
+		SaidAt: r.clock.Now(),
+	}
+	if err := r.call(s.result, client.OperationPostKiyoshi, func() error {
+		return r.cl.PostKiyoshi(ctx, kiyoshi)
+	}); err != nil {
+		if r.consensus != nil && r.consensus.OnDuplicate == DuplicateYield && errors.Is(err, client.ErrConflict) {
+			logging.GetLogger().Infow("Yield the Kiyoshi to another one.", "id", kiyoshi.Id, "err", err)
+			s.result.Duplicate = true
+			return nil
+		}
 		return fmt.Errorf("failed to create a Kiyoshi: %w", err)
 	}
-	fmt.Println("Ki Yo Shi !")
+	s.result.Kiyoshi = kiyoshi
+	r.observer.OnKiyoshi(kiyoshi, index)
 
 	return nil
 }
 
-func isReadyToKiyoshi(zundokos []model.Zundoko) bool {
+// call calls the API and records its latency and error to the result as the operation.
+func (r *runner) call(result *Result, operation client.Operation, api func() error) error {
+	start := r.clock.Now()
+	err := api()
+	result.record(operation, r.clock.Now().Sub(start), err)
+	return err
+}
+
+// countedZundokos returns the Zundokos counted for the pattern, which are only ones posted in the session
+// if the Consensus says so.
+func (r *runner) countedZundokos(zundokos []model.Zundoko, s *session) []model.Zundoko {
+	if r.consensus == nil || !r.consensus.OwnWordsOnly {
+		return zundokos
+	}
+	var own []model.Zundoko
+	for _, zd := range zundokos {
+		if s.own[zd.Id] {
+			own = append(own, zd)
+		}
+	}
+	return own
+}
+
+func isReadyToKiyoshi(zundokos []model.Zundoko, pattern []string) bool {
 	numZundokos := len(zundokos)
-	if numZundokos < 5 {
+	if numZundokos < len(pattern) {
 		return false
 	}
 
-	sort.Slice(zundokos, func(i, j int) bool {
-		return zundokos[i].SaidAt.Before(zundokos[j].SaidAt)
-	})
-
 	var words []string
-	for _, zd := range zundokos[numZundokos-5:] {
+	for _, zd := range sortedZundokos(zundokos)[numZundokos-len(pattern):] {
 		words = append(words, zd.Word)
 	}
-	return strings.Join(words, "") == "ZunZunZunZunDoko"
+	return EndsWithPattern(words, pattern)
 }
@@ -1,17 +1,27 @@
 package runner
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"math/rand"
-	"os"
+	"runtime"
+	"strings"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/kaitoy/zundoko-go-client/mock/pkg/mock_client"
+	"github.com/kaitoy/zundoko-go-client/pkg/client"
 	"github.com/kaitoy/zundoko-go-client/pkg/model"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 var _ = Describe("Runner", func() {
@@ -19,54 +29,81 @@ var _ = Describe("Runner", func() {
 		mockCtrl   *gomock.Controller
 		mockClient *mock_client.MockClient
 		testee     Runner
-		origStdout *os.File
+		output     *bytes.Buffer
 	)
 
 	BeforeEach(func() {
 		mockCtrl = gomock.NewController(GinkgoT())
 		mockClient = mock_client.NewMockClient(mockCtrl)
-		testee = NewRunner(mockClient)
-
-		origStdout = os.Stdout
-		os.Stdout, _ = os.Open(os.DevNull)
+		output = new(bytes.Buffer)
+		testee = NewRunner(mockClient, WithPacer(NewFastPacer()), WithPresenter(NewTextPresenter(output)))
 	})
 
 	AfterEach(func() {
 		mockCtrl.Finish()
-		os.Stdout.Close()
-		os.Stdout = origStdout
+	})
+
+	Describe("NewRunner()", func() {
+		It("works.", func() {
+			Expect(NewRunner(mockClient)).NotTo(BeNil())
+		})
+
+		It("applies the given options.", func() {
+			presenter := NewSilentPresenter()
+			observer := ObserverFuncs{}
+
+			newRunner := NewRunner(mockClient, WithPresenter(presenter), WithObserver(observer))
+
+			Expect(newRunner.(*runner).presenter).To(Equal(presenter))
+			Expect(newRunner.(*runner).observer).To(HaveLen(2))
+		})
 	})
 
 	Describe("Run()", func() {
 		Context("when getting Zundokos by Client", func() {
 			Specify("if the Client returned an error, return the error in a wrap.", func() {
 				err := fmt.Errorf("some error")
-				mockClient.EXPECT().GetZundokos().Return(nil, err)
+				mockClient.EXPECT().GetZundokos(gomock.Any()).Return(nil, err)
 
-				retErr := testee.Run(10)
+				_, retErr := testee.Run(context.Background())
 
 				Expect(errors.Unwrap(retErr)).To(Equal(err))
 			})
 		})
 
+		Context("when the Client is a CircuitBreaker and it's open", func() {
+			It("returns an error that is client.ErrCircuitOpen in a wrap.", func() {
+				mockClient.EXPECT().GetZundokos(gomock.Any()).Return(nil, client.ErrCircuitOpen)
+
+				_, retErr := testee.Run(context.Background())
+
+				Expect(errors.Is(retErr, client.ErrCircuitOpen)).To(BeTrue())
+			})
+		})
+
 		Context("when not ready to go Kiyoshi and posting a Zundoko", func() {
 			Specify("if the Client returned an error, return the error in a wrap.", func() {
 				err := fmt.Errorf("some error")
 				gomock.InOrder(
-					mockClient.EXPECT().GetZundokos().Return(make([]model.Zundoko, 0), nil),
-					mockClient.EXPECT().PostZundoko(gomock.AssignableToTypeOf(&model.Zundoko{})).Return(err),
+					mockClient.EXPECT().GetZundokos(gomock.Any()).Return(make([]model.Zundoko, 0), nil),
+					mockClient.EXPECT().PostZundoko(gomock.Any(), gomock.AssignableToTypeOf(&model.Zundoko{})).Return(err),
 				)
 
-				retErr := testee.Run(10)
+				result, retErr := testee.Run(context.Background())
 
 				Expect(errors.Unwrap(retErr)).To(Equal(err))
+				Expect(result.Attempts).To(Equal(1))
+				Expect(result.Zundokos).To(BeEmpty())
+				Expect(result.Kiyoshi).To(BeNil())
+				Expect(result.Calls[client.OperationPostZundoko].Count).To(Equal(1))
+				Expect(result.Calls[client.OperationPostZundoko].Errors).To(Equal(1))
 			})
 		})
 
 		It("repeats to post a Zundoko until getting ready to go Kiyoshi.", func() {
 			lastZundoko := model.Zundoko{Word: "Doko", SaidAt: time.Now()}
 			gomock.InOrder(
-				mockClient.EXPECT().GetZundokos().Return(
+				mockClient.EXPECT().GetZundokos(gomock.Any()).Return(
 					[]model.Zundoko{
 						{Word: "Zun", SaidAt: time.Date(2021, 1, 1, 1, 50, 0, 0, time.UTC)},
 						{Word: "Zun", SaidAt: time.Date(2021, 1, 1, 1, 50, 1, 0, time.UTC)},
@@ -74,8 +111,8 @@ var _ = Describe("Runner", func() {
 					},
 					nil,
 				),
-				mockClient.EXPECT().PostZundoko(gomock.AssignableToTypeOf(&model.Zundoko{})).Return(nil),
-				mockClient.EXPECT().GetZundokos().Return(
+				mockClient.EXPECT().PostZundoko(gomock.Any(), gomock.AssignableToTypeOf(&model.Zundoko{})).Return(nil),
+				mockClient.EXPECT().GetZundokos(gomock.Any()).Return(
 					[]model.Zundoko{
 						{Word: "Zun", SaidAt: time.Date(2021, 1, 1, 1, 50, 0, 0, time.UTC)},
 						{Word: "Zun", SaidAt: time.Date(2021, 1, 1, 1, 50, 1, 0, time.UTC)},
@@ -84,8 +121,8 @@ var _ = Describe("Runner", func() {
 					},
 					nil,
 				),
-				mockClient.EXPECT().PostZundoko(gomock.AssignableToTypeOf(&model.Zundoko{})).Return(nil),
-				mockClient.EXPECT().GetZundokos().Return(
+				mockClient.EXPECT().PostZundoko(gomock.Any(), gomock.AssignableToTypeOf(&model.Zundoko{})).Return(nil),
+				mockClient.EXPECT().GetZundokos(gomock.Any()).Return(
 					[]model.Zundoko{
 						{Word: "Zun", SaidAt: time.Date(2021, 1, 1, 1, 50, 0, 0, time.UTC)},
 						{Word: "Zun", SaidAt: time.Date(2021, 1, 1, 1, 50, 1, 0, time.UTC)},
@@ -95,22 +132,517 @@ var _ = Describe("Runner", func() {
 					},
 					nil,
 				),
-				mockClient.EXPECT().PostKiyoshi(gomock.AssignableToTypeOf(&model.Kiyoshi{})).Return(nil).
-					Do(func(kiyoshi *model.Kiyoshi) {
+				mockClient.EXPECT().PostKiyoshi(gomock.Any(), gomock.AssignableToTypeOf(&model.Kiyoshi{})).Return(nil).
+					Do(func(_ context.Context, kiyoshi *model.Kiyoshi) {
 						Expect(kiyoshi.SaidAt.After(lastZundoko.SaidAt)).To(BeTrue())
 					}),
 			)
 
-			retErr := testee.Run(10)
+			result, retErr := testee.Run(context.Background())
+
+			Expect(retErr).To(BeNil())
+			lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+			Expect(lines).To(HaveLen(3))
+			Expect(lines[0]).To(BeElementOf("Zun", "Doko"))
+			Expect(lines[1]).To(BeElementOf("Zun", "Doko"))
+			Expect(lines[2]).To(Equal("Ki Yo Shi !"))
+
+			Expect(result.Attempts).To(Equal(3))
+			Expect(result.Zundokos).To(HaveLen(2))
+			Expect(result.Zundokos[0].Word).To(Equal(lines[0]))
+			Expect(result.Zundokos[1].Word).To(Equal(lines[1]))
+			Expect(result.Kiyoshi).NotTo(BeNil())
+			Expect(result.Elapsed).To(BeNumerically(">", 0))
+			Expect(result.Calls[client.OperationGetZundokos].Count).To(Equal(3))
+			Expect(result.Calls[client.OperationGetZundokos].Latencies).To(HaveLen(3))
+			Expect(result.Calls[client.OperationPostZundoko].Count).To(Equal(2))
+			Expect(result.Calls[client.OperationPostKiyoshi].Count).To(Equal(1))
+		})
+
+		It("traces the session as a span and each iteration and the Kiyoshi as its child spans.", func() {
+			recorder := tracetest.NewSpanRecorder()
+			otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+			defer otel.SetTracerProvider(noop.NewTracerProvider())
+			gomock.InOrder(
+				mockClient.EXPECT().GetZundokos(gomock.Any()).Return(make([]model.Zundoko, 0), nil),
+				mockClient.EXPECT().PostZundoko(gomock.Any(), gomock.AssignableToTypeOf(&model.Zundoko{})).Return(nil),
+				mockClient.EXPECT().GetZundokos(gomock.Any()).Return(
+					[]model.Zundoko{
+						{Word: "Zun", SaidAt: time.Date(2021, 1, 1, 1, 50, 0, 0, time.UTC)},
+						{Word: "Zun", SaidAt: time.Date(2021, 1, 1, 1, 50, 1, 0, time.UTC)},
+						{Word: "Zun", SaidAt: time.Date(2021, 1, 1, 1, 50, 2, 0, time.UTC)},
+						{Word: "Zun", SaidAt: time.Date(2021, 1, 1, 1, 50, 3, 0, time.UTC)},
+						{Word: "Doko", SaidAt: time.Date(2021, 1, 1, 1, 50, 4, 0, time.UTC)},
+					},
+					nil,
+				),
+				mockClient.EXPECT().PostKiyoshi(gomock.Any(), gomock.AssignableToTypeOf(&model.Kiyoshi{})).
+					DoAndReturn(func(ctx context.Context, _ *model.Kiyoshi) error {
+						Expect(trace.SpanFromContext(ctx).SpanContext().IsValid()).To(BeTrue())
+						return nil
+					}),
+			)
+
+			_, retErr := testee.Run(context.Background())
+
+			Expect(retErr).To(BeNil())
+			spans := recorder.Ended()
+			Expect(spans).To(HaveLen(4))
+			session := spans[3]
+			Expect(session.Name()).To(Equal("Zundoko Kiyoshi"))
+			for i, name := range []string{"Zundoko iteration", "Zundoko iteration", "Kiyoshi"} {
+				Expect(spans[i].Name()).To(Equal(name))
+				Expect(spans[i].Parent().SpanID()).To(Equal(session.SpanContext().SpanID()))
+			}
+		})
+
+		It("notifies the Observers of the progress of the session.", func() {
+			var events []string
+			var finished *Result
+			observer := ObserverFuncs{
+				Start:   func() { events = append(events, "start") },
+				Fetch:   func(zundokos []model.Zundoko) { events = append(events, fmt.Sprintf("fetch %d", len(zundokos))) },
+				Word:    func(zundoko *model.Zundoko, index int) { events = append(events, fmt.Sprintf("word %d", index)) },
+				Kiyoshi: func(kiyoshi *model.Kiyoshi, index int) { events = append(events, fmt.Sprintf("kiyoshi %d", index)) },
+				Error:   func(err error) { events = append(events, "error") },
+				Finish: func(result *Result) {
+					events = append(events, "finish")
+					finished = result
+				},
+			}
+			testee = NewRunner(mockClient, WithPacer(NewFastPacer()), WithPresenter(NewSilentPresenter()), WithObserver(observer))
+			gomock.InOrder(
+				mockClient.EXPECT().GetZundokos(gomock.Any()).Return(make([]model.Zundoko, 0), nil),
+				mockClient.EXPECT().PostZundoko(gomock.Any(), gomock.AssignableToTypeOf(&model.Zundoko{})).Return(nil),
+				mockClient.EXPECT().GetZundokos(gomock.Any()).Return(
+					[]model.Zundoko{
+						{Word: "Zun", SaidAt: time.Date(2021, 1, 1, 1, 50, 0, 0, time.UTC)},
+						{Word: "Zun", SaidAt: time.Date(2021, 1, 1, 1, 50, 1, 0, time.UTC)},
+						{Word: "Zun", SaidAt: time.Date(2021, 1, 1, 1, 50, 2, 0, time.UTC)},
+						{Word: "Zun", SaidAt: time.Date(2021, 1, 1, 1, 50, 3, 0, time.UTC)},
+						{Word: "Doko", SaidAt: time.Date(2021, 1, 1, 1, 50, 4, 0, time.UTC)},
+					},
+					nil,
+				),
+				mockClient.EXPECT().PostKiyoshi(gomock.Any(), gomock.AssignableToTypeOf(&model.Kiyoshi{})).Return(nil),
+			)
+
+			result, retErr := testee.Run(context.Background())
 
 			Expect(retErr).To(BeNil())
+			Expect(events).To(Equal([]string{"start", "fetch 0", "word 1", "fetch 5", "kiyoshi 2", "finish"}))
+			Expect(finished).To(BeIdenticalTo(result))
+			Expect(finished.Elapsed).To(BeNumerically(">", 0))
+		})
+
+		It("notifies the Observers of the error the session ends with.", func() {
+			err := fmt.Errorf("some error")
+			var notified error
+			var finished bool
+			testee = NewRunner(mockClient, WithPacer(NewFastPacer()), WithObserver(ObserverFuncs{
+				Error:  func(err error) { notified = err },
+				Finish: func(*Result) { finished = true },
+			}))
+			mockClient.EXPECT().GetZundokos(gomock.Any()).Return(nil, err)
+
+			_, retErr := testee.Run(context.Background())
+
+			Expect(notified).To(Equal(retErr))
+			Expect(finished).To(BeTrue())
+		})
+
+		It("stops waiting for the Pacer when the context is done.", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			testee = NewRunner(mockClient, WithPacer(NewFixedPacer(time.Hour)), WithPresenter(NewSilentPresenter()))
+			gomock.InOrder(
+				mockClient.EXPECT().GetZundokos(gomock.Any()).Return(make([]model.Zundoko, 0), nil),
+				mockClient.EXPECT().PostZundoko(gomock.Any(), gomock.AssignableToTypeOf(&model.Zundoko{})).
+					DoAndReturn(func(context.Context, *model.Zundoko) error {
+						cancel()
+						return nil
+					}),
+			)
+
+			result, retErr := testee.Run(ctx)
+
+			Expect(errors.Is(retErr, context.Canceled)).To(BeTrue())
+			Expect(result.Zundokos).To(HaveLen(1))
+		})
+
+		Context("with a FakeClock", func() {
+			var (
+				start   time.Time
+				clock   *FakeClock
+				history []model.Zundoko
+				// conflicts is the number of next posts of Zundokos to fail with client.ErrConflict.
+				conflicts int
+				// interleave returns Zundokos posted by others right after the n-th post of the testee.
+				interleave func(n int) []model.Zundoko
+			)
+
+			BeforeEach(func() {
+				start = time.Date(2021, 1, 1, 1, 50, 0, 0, time.UTC)
+				clock = NewFakeClock(start)
+				history = nil
+				conflicts = 0
+				interleave = func(int) []model.Zundoko { return nil }
+				posts := 0
+				mockClient.EXPECT().GetZundokos(gomock.Any()).AnyTimes().DoAndReturn(
+					func(context.Context) ([]model.Zundoko, error) {
+						return append([]model.Zundoko(nil), history...), nil
+					},
+				)
+				mockClient.EXPECT().PostZundoko(gomock.Any(), gomock.AssignableToTypeOf(&model.Zundoko{})).AnyTimes().DoAndReturn(
+					func(_ context.Context, zundoko *model.Zundoko) error {
+						if conflicts > 0 {
+							conflicts--
+							return fmt.Errorf("failed: %w", client.ErrConflict)
+						}
+						history = append(history, *zundoko)
+						posts++
+						for _, zd := range interleave(posts) {
+							zd.SaidAt = zundoko.SaidAt.Add(time.Millisecond)
+							history = append(history, zd)
+						}
+						return nil
+					},
+				)
+			})
+
+			// runAdvancing runs the testee, advancing the clock by the step whenever the testee waits for it.
+			runAdvancing := func(step time.Duration) (*Result, error) {
+				type ret struct {
+					result *Result
+					err    error
+				}
+				done := make(chan ret)
+				go func() {
+					defer GinkgoRecover()
+					result, err := testee.Run(context.Background())
+					done <- ret{result, err}
+				}()
+				for {
+					select {
+					case r := <-done:
+						return r.result, r.err
+					default:
+						if clock.Waiters() > 0 {
+							clock.Advance(step)
+						} else {
+							runtime.Gosched()
+						}
+					}
+				}
+			}
+
+			It("posts exact Zundokos and a Kiyoshi at the pace.", func() {
+				var kiyoshi *model.Kiyoshi
+				mockClient.EXPECT().PostKiyoshi(gomock.Any(), gomock.AssignableToTypeOf(&model.Kiyoshi{})).DoAndReturn(
+					func(_ context.Context, k *model.Kiyoshi) error {
+						kiyoshi = k
+						return nil
+					},
+				)
+				testee = NewRunner(
+					mockClient,
+					WithPresenter(NewSilentPresenter()),
+					WithPacer(NewFixedPacer(time.Second)),
+					WithClock(clock),
+					WithRand(&fakeRand{values: []int{9, 9, 9, 9, 0}}),
+					WithIDGenerator(&sequentialIDs{}),
+				)
+
+				result, retErr := runAdvancing(time.Second)
+
+				Expect(retErr).To(BeNil())
+				Expect(result.Zundokos).To(Equal([]model.Zundoko{
+					{Id: "id-1", SaidAt: start, Word: "Zun"},
+					{Id: "id-2", SaidAt: start.Add(1 * time.Second), Word: "Zun"},
+					{Id: "id-3", SaidAt: start.Add(2 * time.Second), Word: "Zun"},
+					{Id: "id-4", SaidAt: start.Add(3 * time.Second), Word: "Zun"},
+					{Id: "id-5", SaidAt: start.Add(4 * time.Second), Word: "Doko"},
+				}))
+				Expect(kiyoshi).To(Equal(&model.Kiyoshi{Id: "id-6", SaidAt: start.Add(6 * time.Second)}))
+				Expect(result.Kiyoshi).To(Equal(kiyoshi))
+				Expect(result.StartedAt).To(Equal(start))
+				Expect(result.Elapsed).To(Equal(6 * time.Second))
+				Expect(result.Calls[client.OperationGetZundokos].Max()).To(BeZero())
+			})
+
+			Context("with LocalDetection", func() {
+				newTestee := func(config LocalDetection) Runner {
+					return NewRunner(
+						mockClient,
+						WithPresenter(NewSilentPresenter()),
+						WithPacer(NewFixedPacer(time.Second)),
+						WithClock(clock),
+						WithRand(&fakeRand{values: []int{9, 9, 9, 9, 0}}),
+						WithLocalDetection(config),
+					)
+				}
+
+				It("gets Zundokos only at the start.", func() {
+					mockClient.EXPECT().PostKiyoshi(gomock.Any(), gomock.AssignableToTypeOf(&model.Kiyoshi{})).Return(nil)
+					testee = newTestee(LocalDetection{})
+
+					result, retErr := runAdvancing(time.Second)
+
+					Expect(retErr).To(BeNil())
+					Expect(result.Zundokos).To(HaveLen(5))
+					Expect(result.Calls[client.OperationGetZundokos].Count).To(Equal(1))
+					Expect(result.Calls[client.OperationPostZundoko].Count).To(Equal(5))
+				})
+
+				It("resyncs after the posts, seeing words posted by others.", func() {
+					mockClient.EXPECT().PostKiyoshi(gomock.Any(), gomock.AssignableToTypeOf(&model.Kiyoshi{})).Return(nil)
+					testee = newTestee(LocalDetection{ResyncPosts: 2})
+					history = []model.Zundoko{
+						{Id: "other-1", SaidAt: start.Add(-3 * time.Second), Word: "Zun"},
+						{Id: "other-2", SaidAt: start.Add(-2 * time.Second), Word: "Zun"},
+					}
+
+					result, retErr := runAdvancing(time.Second)
+
+					Expect(retErr).To(BeNil())
+					Expect(result.Zundokos).To(HaveLen(5))
+					Expect(result.Calls[client.OperationGetZundokos].Count).To(Equal(3))
+				})
+
+				It("resyncs after a conflict.", func() {
+					mockClient.EXPECT().PostKiyoshi(gomock.Any(), gomock.AssignableToTypeOf(&model.Kiyoshi{})).Return(nil)
+					testee = newTestee(LocalDetection{})
+					conflicts = 1
+
+					result, retErr := runAdvancing(time.Second)
+
+					Expect(retErr).To(BeNil())
+					Expect(result.Kiyoshi).NotTo(BeNil())
+					Expect(result.Calls[client.OperationGetZundokos].Count).To(Equal(2))
+					Expect(result.Calls[client.OperationPostZundoko].Errors).To(Equal(1))
+				})
+			})
+
+			Context("with Consensus", func() {
+				newTestee := func(consensus Consensus, words ...int) Runner {
+					return NewRunner(
+						mockClient,
+						WithPresenter(NewSilentPresenter()),
+						WithPacer(NewFixedPacer(time.Second)),
+						WithClock(clock),
+						WithRand(&fakeRand{values: words}),
+						WithConsensus(consensus),
+					)
+				}
+				other := func(id, word string) []model.Zundoko {
+					return []model.Zundoko{{Id: id, Word: word}}
+				}
+
+				It("doesn't miss a pattern followed by words of others.", func() {
+					mockClient.EXPECT().PostKiyoshi(gomock.Any(), gomock.AssignableToTypeOf(&model.Kiyoshi{})).Return(nil)
+					interleave = func(n int) []model.Zundoko {
+						if n == 5 {
+							return other("other-1", "Zun")
+						}
+						return nil
+					}
+					testee = newTestee(Consensus{}, 9, 9, 9, 9, 0)
+
+					result, retErr := runAdvancing(time.Second)
+
+					Expect(retErr).To(BeNil())
+					Expect(result.Zundokos).To(HaveLen(5))
+					Expect(result.Kiyoshi).NotTo(BeNil())
+				})
+
+				It("doesn't claim a pattern completed before the session started.", func() {
+					mockClient.EXPECT().PostKiyoshi(gomock.Any(), gomock.AssignableToTypeOf(&model.Kiyoshi{})).Return(nil)
+					for i, word := range []string{"Zun", "Zun", "Zun", "Zun", "Doko"} {
+						history = append(history, model.Zundoko{
+							Id:     fmt.Sprintf("old-%d", i),
+							SaidAt: start.Add(time.Duration(i-10) * time.Second),
+							Word:   word,
+						})
+					}
+					testee = newTestee(Consensus{}, 9, 9, 9, 9, 0)
+
+					result, retErr := runAdvancing(time.Second)
+
+					Expect(retErr).To(BeNil())
+					Expect(result.Zundokos).To(HaveLen(5))
+				})
+
+				It("lets only the completer claim with ClaimCompleter.", func() {
+					mockClient.EXPECT().PostKiyoshi(gomock.Any(), gomock.AssignableToTypeOf(&model.Kiyoshi{})).Return(nil)
+					interleave = func(n int) []model.Zundoko {
+						if n == 4 {
+							return other("other-1", "Doko")
+						}
+						return nil
+					}
+					testee = newTestee(Consensus{Claim: ClaimCompleter}, 9, 9, 9, 9, 9, 9, 9, 9, 0)
+
+					result, retErr := runAdvancing(time.Second)
+
+					Expect(retErr).To(BeNil())
+					Expect(result.Zundokos).To(HaveLen(9))
+					Expect(result.Zundokos[8].Word).To(Equal("Doko"))
+				})
+
+				It("claims a pattern completed by others with ClaimAny.", func() {
+					mockClient.EXPECT().PostKiyoshi(gomock.Any(), gomock.AssignableToTypeOf(&model.Kiyoshi{})).Return(nil)
+					interleave = func(n int) []model.Zundoko {
+						if n == 4 {
+							return other("other-1", "Doko")
+						}
+						return nil
+					}
+					testee = newTestee(Consensus{Claim: ClaimAny}, 9)
+
+					result, retErr := runAdvancing(time.Second)
+
+					Expect(retErr).To(BeNil())
+					Expect(result.Zundokos).To(HaveLen(4))
+				})
+
+				It("ignores words of others with OwnWordsOnly.", func() {
+					mockClient.EXPECT().PostKiyoshi(gomock.Any(), gomock.AssignableToTypeOf(&model.Kiyoshi{})).Return(nil)
+					interleave = func(n int) []model.Zundoko {
+						return other(fmt.Sprintf("other-%d", n), "Doko")
+					}
+					testee = newTestee(Consensus{OwnWordsOnly: true}, 9, 9, 9, 9, 0)
+
+					result, retErr := runAdvancing(time.Second)
+
+					Expect(retErr).To(BeNil())
+					Expect(result.Zundokos).To(HaveLen(5))
+					Expect(result.Kiyoshi).NotTo(BeNil())
+				})
+
+				It("ends without a Kiyoshi when it's a duplicate with DuplicateYield.", func() {
+					mockClient.EXPECT().PostKiyoshi(gomock.Any(), gomock.AssignableToTypeOf(&model.Kiyoshi{})).
+						Return(fmt.Errorf("failed: %w", client.ErrConflict))
+					testee = newTestee(Consensus{OnDuplicate: DuplicateYield}, 9, 9, 9, 9, 0)
+
+					result, retErr := runAdvancing(time.Second)
+
+					Expect(retErr).To(BeNil())
+					Expect(result.Kiyoshi).To(BeNil())
+					Expect(result.Duplicate).To(BeTrue())
+				})
+
+				It("fails when a Kiyoshi is a duplicate with DuplicateFail.", func() {
+					mockClient.EXPECT().PostKiyoshi(gomock.Any(), gomock.AssignableToTypeOf(&model.Kiyoshi{})).
+						Return(fmt.Errorf("failed: %w", client.ErrConflict))
+					testee = newTestee(Consensus{OnDuplicate: DuplicateFail}, 9, 9, 9, 9, 0)
+
+					result, retErr := runAdvancing(time.Second)
+
+					Expect(errors.Is(retErr, client.ErrConflict)).To(BeTrue())
+					Expect(result.Duplicate).To(BeFalse())
+				})
+			})
+
+			It("covers a long session instantly.", func() {
+				mockClient.EXPECT().PostKiyoshi(gomock.Any(), gomock.AssignableToTypeOf(&model.Kiyoshi{})).Return(nil)
+				values := make([]int, 1000)
+				for i := range values {
+					values[i] = 9
+				}
+				values[999] = 0
+				testee = NewRunner(
+					mockClient,
+					WithPresenter(NewSilentPresenter()),
+					WithPacer(NewFixedPacer(time.Minute)),
+					WithClock(clock),
+					WithRand(&fakeRand{values: values}),
+				)
+
+				result, retErr := runAdvancing(time.Minute)
+
+				Expect(retErr).To(BeNil())
+				Expect(result.Zundokos).To(HaveLen(1000))
+				Expect(result.Attempts).To(Equal(1001))
+				Expect(result.Elapsed).To(Equal(1001 * time.Minute))
+			})
+
+			for _, local := range []bool{false, true} {
+				local := local
+				It(fmt.Sprintf("goes Kiyoshi on the given pattern (local detection: %v).", local), func() {
+					mockClient.EXPECT().PostKiyoshi(gomock.Any(), gomock.AssignableToTypeOf(&model.Kiyoshi{})).Return(nil)
+					opts := []Option{
+						WithPresenter(NewSilentPresenter()),
+						WithPacer(NewFixedPacer(time.Second)),
+						WithClock(clock),
+						WithRand(&fakeRand{values: []int{9, 9, 9, 9, 0, 0, 9, 0}}),
+						WithPattern([]string{"Doko", "Zun", "Doko"}),
+					}
+					if local {
+						opts = append(opts, WithLocalDetection(LocalDetection{}))
+					}
+					testee = NewRunner(mockClient, opts...)
+
+					result, retErr := runAdvancing(time.Second)
+
+					Expect(retErr).To(BeNil())
+					Expect(result.Zundokos).To(HaveLen(8))
+					Expect(result.Kiyoshi).NotTo(BeNil())
+				})
+			}
+		})
+
+		Context("with a WordSource", func() {
+			It("posts the words from the WordSource and goes Kiyoshi automatically.", func() {
+				words := make(chan string, 2)
+				words <- "Zun"
+				words <- "Doko"
+				testee = NewRunner(mockClient, WithPacer(NewFastPacer()), WithPresenter(NewTextPresenter(output)), WithWordSource(NewChanWordSource(words)))
+				var posted []string
+				post := func(_ context.Context, zundoko *model.Zundoko) error {
+					posted = append(posted, zundoko.Word)
+					return nil
+				}
+				gomock.InOrder(
+					mockClient.EXPECT().GetZundokos(gomock.Any()).Return(make([]model.Zundoko, 0), nil),
+					mockClient.EXPECT().PostZundoko(gomock.Any(), gomock.AssignableToTypeOf(&model.Zundoko{})).DoAndReturn(post),
+					mockClient.EXPECT().GetZundokos(gomock.Any()).Return(make([]model.Zundoko, 0), nil),
+					mockClient.EXPECT().PostZundoko(gomock.Any(), gomock.AssignableToTypeOf(&model.Zundoko{})).DoAndReturn(post),
+					mockClient.EXPECT().GetZundokos(gomock.Any()).Return(
+						[]model.Zundoko{
+							{Word: "Zun", SaidAt: time.Date(2021, 1, 1, 1, 50, 0, 0, time.UTC)},
+							{Word: "Zun", SaidAt: time.Date(2021, 1, 1, 1, 50, 1, 0, time.UTC)},
+							{Word: "Zun", SaidAt: time.Date(2021, 1, 1, 1, 50, 2, 0, time.UTC)},
+							{Word: "Zun", SaidAt: time.Date(2021, 1, 1, 1, 50, 3, 0, time.UTC)},
+							{Word: "Doko", SaidAt: time.Date(2021, 1, 1, 1, 50, 4, 0, time.UTC)},
+						},
+						nil,
+					),
+					mockClient.EXPECT().PostKiyoshi(gomock.Any(), gomock.AssignableToTypeOf(&model.Kiyoshi{})).Return(nil),
+				)
+
+				_, retErr := testee.Run(context.Background())
+
+				Expect(retErr).To(BeNil())
+				Expect(posted).To(Equal([]string{"Zun", "Doko"}))
+				Expect(output.String()).To(Equal("Zun\nDoko\nKi Yo Shi !\n"))
+			})
+
+			It("returns an error that is io.EOF in a wrap if the WordSource runs out of words.", func() {
+				words := make(chan string)
+				close(words)
+				testee = NewRunner(mockClient, WithPacer(NewFastPacer()), WithWordSource(NewChanWordSource(words)))
+				mockClient.EXPECT().GetZundokos(gomock.Any()).Return(make([]model.Zundoko, 0), nil)
+
+				_, retErr := testee.Run(context.Background())
+
+				Expect(errors.Is(retErr, io.EOF)).To(BeTrue())
+			})
 		})
 
 		Context("when posting a Kiyoshi", func() {
 			Specify("if the Client returned an error, return the error in a wrap.", func() {
 				err := fmt.Errorf("some error")
 				gomock.InOrder(
-					mockClient.EXPECT().GetZundokos().Return(
+					mockClient.EXPECT().GetZundokos(gomock.Any()).Return(
 						[]model.Zundoko{
 							{Word: "Zun", SaidAt: time.Date(2021, 1, 1, 1, 50, 0, 0, time.UTC)},
 							{Word: "Zun", SaidAt: time.Date(2021, 1, 1, 1, 50, 1, 0, time.UTC)},
@@ -120,10 +652,10 @@ var _ = Describe("Runner", func() {
 						},
 						nil,
 					),
-					mockClient.EXPECT().PostKiyoshi(gomock.AssignableToTypeOf(&model.Kiyoshi{})).Return(err),
+					mockClient.EXPECT().PostKiyoshi(gomock.Any(), gomock.AssignableToTypeOf(&model.Kiyoshi{})).Return(err),
 				)
 
-				retErr := testee.Run(10)
+				_, retErr := testee.Run(context.Background())
 
 				Expect(errors.Unwrap(retErr)).To(Equal(err))
 			})
@@ -162,7 +694,7 @@ var _ = Describe("Runner", func() {
 				It("returns true if last 5 are Zun, Zun, Zun, Zun, and Doko.", func() {
 					rand.Shuffle(len(zds), func(i, j int) { zds[i], zds[j] = zds[j], zds[i] })
 
-					ready := isReadyToKiyoshi(zds)
+					ready := isReadyToKiyoshi(zds, Pattern())
 
 					Expect(ready).To(BeTrue())
 				})
@@ -218,7 +750,7 @@ var _ = Describe("Runner", func() {
 					"last 5 are not Zun, Zun, Zun, Zun, and Doko.", func() {
 					rand.Shuffle(len(zds), func(i, j int) { zds[i], zds[j] = zds[j], zds[i] })
 
-					ready := isReadyToKiyoshi(zds)
+					ready := isReadyToKiyoshi(zds, Pattern())
 
 					Expect(ready).To(BeFalse())
 				})
@@ -0,0 +1,129 @@
+package runner
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/logging"
+)
+
+// WordSource supplies words for a Runner to post.
+type WordSource interface {
+	// NextWord returns the next word, "Zun" or "Doko".
+	// It returns io.EOF when there are no more words.
+	NextWord(ctx context.Context) (string, error)
+}
+
+// NewRandomWordSource creates a WordSource that randomly returns "Zun" or "Doko" with even odds forever.
+// If rnd is nil, the top-level funcs of math/rand are used.
+func NewRandomWordSource(rnd Rand) WordSource {
+	return randomWordSource{orGlobalRand(rnd)}
+}
+
+type randomWordSource struct {
+	rnd Rand
+}
+
+func (s randomWordSource) NextWord(ctx context.Context) (string, error) {
+	if s.rnd.Intn(10) < 5 {
+		return "Doko", nil
+	}
+	return "Zun", nil
+}
+
+// NewLineWordSource creates a WordSource that reads a word per line from r, e.g. the standard input.
+// "Zun" or "Z", and "Doko" or "D" are accepted in any case. Blank lines are skipped
+// and other lines are skipped with a warning.
+// Since reading from r can't be interrupted, NextWord returns only after a line is read even if ctx is done.
+func NewLineWordSource(r io.Reader) WordSource {
+	return &lineWordSource{bufio.NewScanner(r)}
+}
+
+type lineWordSource struct {
+	scanner *bufio.Scanner
+}
+
+func (s *lineWordSource) NextWord(ctx context.Context) (string, error) {
+	for s.scanner.Scan() {
+		line := strings.TrimSpace(s.scanner.Text())
+		if line == "" {
+			continue
+		}
+		switch strings.ToLower(line) {
+		case "zun", "z":
+			return "Zun", nil
+		case "doko", "d":
+			return "Doko", nil
+		}
+		logging.GetLogger().Warnw("Skip an unknown word.", "word", line)
+	}
+	if err := s.scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", io.EOF
+}
+
+// Keys handled by a WordSource created by NewKeyWordSource.
+const (
+	keyCtrlC = 0x03
+	keyCtrlD = 0x04
+	keyEsc   = 0x1b
+)
+
+// NewKeyWordSource creates a WordSource that reads key presses from r, which is expected to be a terminal in raw mode.
+// Z is read as "Zun" and D as "Doko" in any case. Q, Esc, Ctrl-C, and Ctrl-D end the words. Other keys are ignored.
+// Since reading from r can't be interrupted, NextWord returns only after a key is pressed even if ctx is done.
+func NewKeyWordSource(r io.Reader) WordSource {
+	return &keyWordSource{r}
+}
+
+type keyWordSource struct {
+	r io.Reader
+}
+
+func (s *keyWordSource) NextWord(ctx context.Context) (string, error) {
+	buf := make([]byte, 1)
+	for {
+		n, err := s.r.Read(buf)
+		if n > 0 {
+			switch buf[0] {
+			case 'z', 'Z':
+				return "Zun", nil
+			case 'd', 'D':
+				return "Doko", nil
+			case 'q', 'Q', keyCtrlC, keyCtrlD, keyEsc:
+				return "", io.EOF
+			}
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// NewChanWordSource creates a WordSource that receives words from ch.
+// Words other than "Zun" and "Doko" are skipped with a warning. Closing ch ends the words.
+func NewChanWordSource(ch <-chan string) WordSource {
+	return chanWordSource(ch)
+}
+
+type chanWordSource <-chan string
+
+func (s chanWordSource) NextWord(ctx context.Context) (string, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case word, ok := <-s:
+			if !ok {
+				return "", io.EOF
+			}
+			if word == "Zun" || word == "Doko" {
+				return word, nil
+			}
+			logging.GetLogger().Warnw("Skip an unknown word.", "word", word)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WordSource", func() {
+	readAll := func(source WordSource) ([]string, error) {
+		var words []string
+		for {
+			word, err := source.NextWord(context.Background())
+			if err != nil {
+				return words, err
+			}
+			words = append(words, word)
+		}
+	}
+
+	readN := func(source WordSource, n int) ([]string, error) {
+		var words []string
+		for i := 0; i < n; i++ {
+			word, err := source.NextWord(context.Background())
+			if err != nil {
+				return words, err
+			}
+			words = append(words, word)
+		}
+		return words, nil
+	}
+
+	Describe("NewRandomWordSource()", func() {
+		It("returns Zun or Doko.", func() {
+			source := NewRandomWordSource(nil)
+			for i := 0; i < 20; i++ {
+				word, err := source.NextWord(context.Background())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(word).To(BeElementOf("Zun", "Doko"))
+			}
+		})
+
+		It("chooses words with the Rand.", func() {
+			words, _ := readN(NewRandomWordSource(&fakeRand{values: []int{0, 4, 5, 9}}), 4)
+
+			Expect(words).To(Equal([]string{"Doko", "Doko", "Zun", "Zun"}))
+		})
+	})
+
+	Describe("NewLineWordSource()", func() {
+		It("reads a word per line, skipping blank and unknown lines.", func() {
+			words, err := readAll(NewLineWordSource(strings.NewReader("Zun\nz\n\n  DOKO \nfoo\nd\n")))
+
+			Expect(err).To(Equal(io.EOF))
+			Expect(words).To(Equal([]string{"Zun", "Zun", "Doko", "Doko"}))
+		})
+	})
+
+	Describe("NewKeyWordSource()", func() {
+		It("reads Z and D keys until Q is pressed, ignoring other keys.", func() {
+			words, err := readAll(NewKeyWordSource(strings.NewReader("zZx dDqz")))
+
+			Expect(err).To(Equal(io.EOF))
+			Expect(words).To(Equal([]string{"Zun", "Zun", "Doko", "Doko"}))
+		})
+
+		It("ends the words by Ctrl-C.", func() {
+			words, err := readAll(NewKeyWordSource(strings.NewReader("z\x03d")))
+
+			Expect(err).To(Equal(io.EOF))
+			Expect(words).To(Equal([]string{"Zun"}))
+		})
+
+		It("ends the words at the end of the input.", func() {
+			words, err := readAll(NewKeyWordSource(strings.NewReader("d")))
+
+			Expect(err).To(Equal(io.EOF))
+			Expect(words).To(Equal([]string{"Doko"}))
+		})
+	})
+
+	Describe("NewChanWordSource()", func() {
+		It("receives words until the channel is closed, skipping unknown ones.", func() {
+			ch := make(chan string, 3)
+			ch <- "Zun"
+			ch <- "Kiyoshi"
+			ch <- "Doko"
+			close(ch)
+
+			words, err := readAll(NewChanWordSource(ch))
+
+			Expect(err).To(Equal(io.EOF))
+			Expect(words).To(Equal([]string{"Zun", "Doko"}))
+		})
+
+		It("returns the error of the context when it's done.", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			_, err := NewChanWordSource(make(chan string)).NextWord(ctx)
+
+			Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+		})
+	})
+})
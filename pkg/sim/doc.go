@@ -0,0 +1,2 @@
+// Package sim provides a Monte-Carlo simulator of Zundoko Kiyoshi to estimate how many words a session takes.
+package sim
@@ -0,0 +1,77 @@
+package sim
+
+import "math"
+
+// histogram counts sessions by the number of words they took.
+type histogram struct {
+	// counts holds the number of sessions which took the index words.
+	counts  []int
+	givenUp int
+}
+
+// record records a session which took the words, or was given up if 0.
+func (h *histogram) record(words int) {
+	if words == 0 {
+		h.givenUp++
+		return
+	}
+	for len(h.counts) <= words {
+		h.counts = append(h.counts, 0)
+	}
+	h.counts[words]++
+}
+
+func (h *histogram) merge(other histogram) {
+	for words, count := range other.counts {
+		for len(h.counts) <= words {
+			h.counts = append(h.counts, 0)
+		}
+		h.counts[words] += count
+	}
+	h.givenUp += other.givenUp
+}
+
+// report calculates the statistics of the recorded sessions.
+func (h *histogram) report() *Report {
+	r := &Report{GivenUp: h.givenUp}
+	var sum, sumSquares float64
+	for words, count := range h.counts {
+		if count == 0 {
+			continue
+		}
+		if r.Runs == 0 {
+			r.Min = words
+		}
+		r.Max = words
+		r.Runs += count
+		sum += float64(words * count)
+		sumSquares += float64(words) * float64(words) * float64(count)
+	}
+	r.Runs += h.givenUp
+	finished := r.Runs - h.givenUp
+	if finished == 0 {
+		return r
+	}
+
+	r.Mean = sum / float64(finished)
+	r.StdDev = math.Sqrt(math.Max(sumSquares/float64(finished)-r.Mean*r.Mean, 0))
+	r.Median = h.percentile(finished, 50)
+	r.P99 = h.percentile(finished, 99)
+	return r
+}
+
+// percentile returns the p-th percentile of the number of words of the finished sessions by the nearest-rank method.
+func (h *histogram) percentile(finished, p int) int {
+	rank := (p*finished + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	seen := 0
+	for words, count := range h.counts {
+		seen += count
+		if seen >= rank {
+			return words
+		}
+	}
+	return len(h.counts) - 1
+}
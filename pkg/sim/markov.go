@@ -0,0 +1,91 @@
+package sim
+
+import (
+	"errors"
+	"math"
+)
+
+// automaton tracks how many words of the pattern the last words match, like the KMP algorithm,
+// which is a Markov chain of the number of matched words when words are random.
+type automaton struct {
+	// next is the number of matched words after a word of the alphabet is said when some are matched.
+	next [][]int
+
+	// size is the length of the pattern, the number of matched words which completes the pattern.
+	size int
+}
+
+// newAutomaton creates an automaton of the pattern of words in the alphabet given as their indexes.
+func newAutomaton(pattern []int, alphabetSize int) *automaton {
+	a := &automaton{next: make([][]int, len(pattern)), size: len(pattern)}
+	for matched := range pattern {
+		a.next[matched] = make([]int, alphabetSize)
+		for word := 0; word < alphabetSize; word++ {
+			said := append(append([]int(nil), pattern[:matched]...), word)
+			a.next[matched][word] = longestPrefixSuffix(pattern, said)
+		}
+	}
+	return a
+}
+
+// longestPrefixSuffix returns the length of the longest prefix of the pattern which is a suffix of the words.
+func longestPrefixSuffix(pattern, words []int) int {
+	for n := len(pattern); n > 0; n-- {
+		if n > len(words) {
+			continue
+		}
+		match := true
+		for i := 0; i < n; i++ {
+			if pattern[i] != words[len(words)-n+i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return n
+		}
+	}
+	return 0
+}
+
+// expectedWords returns the expected number of words to complete the pattern from scratch,
+// when each word of the alphabet is said with the probability.
+// It solves E[m] = 1 + Σ p(w) E[next(m, w)] with E[size] = 0 for the expected words E[m] from m matched words.
+func (a *automaton) expectedWords(probabilities []float64) (float64, error) {
+	n := a.size
+	// Augmented matrix of (I - P) E = 1 over the transient states.
+	m := make([][]float64, n)
+	for s := 0; s < n; s++ {
+		m[s] = make([]float64, n+1)
+		m[s][s] = 1
+		m[s][n] = 1
+		for word, p := range probabilities {
+			if next := a.next[s][word]; next < n {
+				m[s][next] -= p
+			}
+		}
+	}
+
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(m[row][col]) > math.Abs(m[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(m[pivot][col]) < 1e-12 {
+			return 0, errors.New("the pattern can never be completed")
+		}
+		m[col], m[pivot] = m[pivot], m[col]
+		for row := 0; row < n; row++ {
+			if row == col || m[row][col] == 0 {
+				continue
+			}
+			f := m[row][col] / m[col][col]
+			for k := col; k <= n; k++ {
+				m[row][k] -= f * m[col][k]
+			}
+		}
+	}
+	return m[0][n] / m[0][0], nil
+}
@@ -0,0 +1,51 @@
+package sim
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("automaton", func() {
+	// Words are indexes in the alphabet: 0 is "Doko" and 1 is "Zun".
+	const doko, zun = 0, 1
+
+	It("falls back to the longest matched prefix on a mismatch.", func() {
+		a := newAutomaton([]int{zun, doko, zun, zun}, 2)
+
+		Expect(a.next[3][doko]).To(Equal(2))
+		Expect(a.next[2][doko]).To(Equal(0))
+		Expect(a.next[1][zun]).To(Equal(1))
+	})
+
+	Describe("expectedWords()", func() {
+		for _, c := range []struct {
+			pattern  []int
+			expected float64
+		}{
+			{[]int{zun, zun, zun, zun, doko}, 32},
+			{[]int{zun, zun}, 6},
+			{[]int{zun, doko, zun}, 10},
+		} {
+			c := c
+			It("calculates the expected words to complete the pattern with even odds.", func() {
+				expected, err := newAutomaton(c.pattern, 2).expectedWords([]float64{0.5, 0.5})
+
+				Expect(err).To(BeNil())
+				Expect(expected).To(BeNumerically("~", c.expected, 1e-9))
+			})
+		}
+
+		It("calculates the expected words with uneven odds.", func() {
+			expected, err := newAutomaton([]int{zun, doko}, 2).expectedWords([]float64{0.25, 0.75})
+
+			Expect(err).To(BeNil())
+			Expect(expected).To(BeNumerically("~", 1/0.75+1/0.25, 1e-9))
+		})
+
+		It("returns an error if the pattern can never be completed.", func() {
+			_, err := newAutomaton([]int{zun, doko}, 2).expectedWords([]float64{0, 1})
+
+			Expect(err).NotTo(BeNil())
+		})
+	})
+})
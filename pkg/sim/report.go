@@ -0,0 +1,39 @@
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// WriteText writes the report in a human-readable text format.
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Pattern:\t%s\n", strings.Join(r.Pattern, " "))
+	var probabilities []string
+	for _, word := range sortedWords(r.Probabilities) {
+		probabilities = append(probabilities, fmt.Sprintf("%s=%.4g", word, r.Probabilities[word]))
+	}
+	fmt.Fprintf(tw, "Probabilities:\t%s\n", strings.Join(probabilities, " "))
+	fmt.Fprintf(tw, "Runs:\t%d (given up: %d)\n", r.Runs, r.GivenUp)
+	fmt.Fprintf(tw, "Elapsed:\t%s\n", r.Elapsed.Round(time.Millisecond))
+	fmt.Fprintln(tw)
+
+	fmt.Fprintln(tw, "Words to Kiyoshi\tmean\tstddev\tmin\tp50\tp99\tmax")
+	fmt.Fprintf(tw, "Simulated\t%.3f\t%.3f\t%d\t%d\t%d\t%d\n", r.Mean, r.StdDev, r.Min, r.Median, r.P99, r.Max)
+	fmt.Fprintf(tw, "Expected\t%.3f\n", r.Expected)
+	if r.Expected > 0 {
+		fmt.Fprintf(tw, "Error\t%+.3f%%\n", (r.Mean-r.Expected)/r.Expected*100)
+	}
+	return tw.Flush()
+}
+
+// WriteJSON writes the report in JSON. Durations are in nanoseconds.
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
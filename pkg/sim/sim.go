@@ -0,0 +1,272 @@
+package sim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/runner"
+)
+
+// Config represents configuration of a simulation.
+type Config struct {
+	// Runs is the number of sessions to simulate. Defaults to 100000.
+	Runs int
+
+	// Workers is the number of goroutines running sessions in parallel. Defaults to the number of CPUs.
+	Workers int
+
+	// Pattern is the sequence of words that makes a Kiyoshi. Defaults to runner.Pattern().
+	Pattern []string
+
+	// Probabilities is the probability of each word to be said. Defaults to the even odds of "Zun" and "Doko"
+	// of the random word source of runner, which is used to generate words in that case.
+	// They are normalized so that the sum is 1.
+	Probabilities map[string]float64
+
+	// Seed is the seed of the pseudo-random numbers. Each worker uses the seed plus its index.
+	Seed int64
+
+	// MaxWords is the number of words after which a session is given up. Defaults to 1000000.
+	MaxWords int
+}
+
+// Report represents the result of a simulation.
+type Report struct {
+	// Runs is the number of simulated sessions.
+	Runs int `json:"runs"`
+
+	// Pattern is the simulated pattern.
+	Pattern []string `json:"pattern"`
+
+	// Probabilities is the normalized probability of each word.
+	Probabilities map[string]float64 `json:"probabilities"`
+
+	// Mean, Median, P99, Min, and Max are statistics of the number of words said until the pattern was completed.
+	Mean   float64 `json:"mean"`
+	Median int     `json:"median"`
+	P99    int     `json:"p99"`
+	Min    int     `json:"min"`
+	Max    int     `json:"max"`
+
+	// StdDev is the standard deviation of the number of words.
+	StdDev float64 `json:"stdDev"`
+
+	// Expected is the expected number of words calculated analytically from the Markov chain of the pattern.
+	Expected float64 `json:"expected"`
+
+	// GivenUp is the number of sessions given up after MaxWords words, which are excluded from the statistics.
+	GivenUp int `json:"givenUp"`
+
+	// Elapsed is the time the simulation took.
+	Elapsed time.Duration `json:"elapsed"`
+}
+
+// Simulate runs sessions of Zundoko Kiyoshi offline with random words until the pattern is completed,
+// and reports how many words they took, compared with the analytic expectation.
+// It stops when ctx is done, returning the report of the sessions finished until then with ctx.Err().
+func Simulate(ctx context.Context, config Config) (*Report, error) {
+	if config.Runs <= 0 {
+		config.Runs = 100000
+	}
+	if config.Workers <= 0 {
+		config.Workers = runtime.NumCPU()
+	}
+	if config.MaxWords <= 0 {
+		config.MaxWords = 1000000
+	}
+	useRunnerSource := config.Pattern == nil && config.Probabilities == nil
+	if config.Pattern == nil {
+		config.Pattern = runner.Pattern()
+	}
+	if config.Probabilities == nil {
+		config.Probabilities = map[string]float64{"Zun": 0.5, "Doko": 0.5}
+	}
+
+	alphabet, probabilities, err := normalize(config.Probabilities)
+	if err != nil {
+		return nil, err
+	}
+	pattern, err := indexes(config.Pattern, alphabet, probabilities)
+	if err != nil {
+		return nil, err
+	}
+	auto := newAutomaton(pattern, len(alphabet))
+	expected, err := auto.expectedWords(probabilities)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	histograms := make([]histogram, config.Workers)
+	var wg sync.WaitGroup
+	for i := 0; i < config.Workers; i++ {
+		runs := config.Runs / config.Workers
+		if i < config.Runs%config.Workers {
+			runs++
+		}
+		rnd := rand.New(rand.NewSource(config.Seed + int64(i)))
+		var source runner.WordSource = newWeightedWordSource(rnd, alphabet, probabilities)
+		if useRunnerSource {
+			source = runner.NewRandomWordSource(rnd)
+		}
+		w := &worker{
+			source:   source,
+			pattern:  config.Pattern,
+			maxWords: config.MaxWords,
+			hist:     &histograms[i],
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.run(ctx, runs)
+		}()
+	}
+	wg.Wait()
+
+	var merged histogram
+	for _, h := range histograms {
+		merged.merge(h)
+	}
+	report := merged.report()
+	report.Pattern = config.Pattern
+	report.Probabilities = make(map[string]float64, len(alphabet))
+	for i, word := range alphabet {
+		report.Probabilities[word] = probabilities[i]
+	}
+	report.Expected = expected
+	report.Elapsed = time.Since(start)
+	return report, ctx.Err()
+}
+
+// normalize returns the words sorted and their probabilities normalized so that the sum is 1.
+func normalize(weights map[string]float64) ([]string, []float64, error) {
+	var sum float64
+	alphabet := sortedWords(weights)
+	for _, word := range alphabet {
+		w := weights[word]
+		if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+			return nil, nil, fmt.Errorf("invalid probability of %s: %v", word, w)
+		}
+		sum += w
+	}
+	if sum == 0 {
+		return nil, nil, errors.New("no word has a positive probability")
+	}
+
+	probabilities := make([]float64, len(alphabet))
+	for i, word := range alphabet {
+		probabilities[i] = weights[word] / sum
+	}
+	return alphabet, probabilities, nil
+}
+
+// indexes returns the indexes of the words of the pattern in the alphabet.
+func indexes(pattern, alphabet []string, probabilities []float64) ([]int, error) {
+	if len(pattern) == 0 {
+		return nil, errors.New("the pattern is empty")
+	}
+	symbols := symbols(alphabet)
+	indexes := make([]int, len(pattern))
+	for i, word := range pattern {
+		index, ok := symbols[word]
+		if !ok || probabilities[index] == 0 {
+			return nil, fmt.Errorf("%s in the pattern is never said: %s", word, strings.Join(pattern, " "))
+		}
+		indexes[i] = index
+	}
+	return indexes, nil
+}
+
+// sortedWords returns the words of the map in order.
+func sortedWords(m map[string]float64) []string {
+	words := make([]string, 0, len(m))
+	for word := range m {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	return words
+}
+
+// symbols maps the words of the alphabet to their indexes.
+func symbols(alphabet []string) map[string]int {
+	symbols := make(map[string]int, len(alphabet))
+	for i, word := range alphabet {
+		symbols[word] = i
+	}
+	return symbols
+}
+
+// worker simulates sessions and records the number of words they took.
+type worker struct {
+	source   runner.WordSource
+	pattern  []string
+	maxWords int
+	hist     *histogram
+}
+
+// checkInterval is the number of sessions after which a worker checks if the context is done.
+const checkInterval = 1024
+
+func (w *worker) run(ctx context.Context, runs int) {
+	for i := 0; i < runs; i++ {
+		if i%checkInterval == 0 && ctx.Err() != nil {
+			return
+		}
+		w.hist.record(w.session(ctx))
+	}
+}
+
+// session returns the number of words said until the pattern is completed, or 0 if it's given up.
+// The pattern is detected by runner.EndsWithPattern as a Runner does, on the last words as long as the pattern.
+func (w *worker) session(ctx context.Context) int {
+	last := make([]string, 0, len(w.pattern))
+	for words := 1; words <= w.maxWords; words++ {
+		word, _ := w.source.NextWord(ctx)
+		if len(last) == len(w.pattern) {
+			last = append(last[:0], last[1:]...)
+		}
+		last = append(last, word)
+		if runner.EndsWithPattern(last, w.pattern) {
+			return words
+		}
+	}
+	return 0
+}
+
+// weightedWordSource is a runner.WordSource that returns words with the probabilities.
+type weightedWordSource struct {
+	rnd        runner.Rand
+	alphabet   []string
+	cumulative []float64
+}
+
+func newWeightedWordSource(rnd runner.Rand, alphabet []string, probabilities []float64) *weightedWordSource {
+	s := &weightedWordSource{rnd: rnd, alphabet: alphabet, cumulative: make([]float64, len(probabilities))}
+	var sum float64
+	for i, p := range probabilities {
+		sum += p
+		s.cumulative[i] = sum
+	}
+	return s
+}
+
+// randomBits is the precision of random numbers of a weightedWordSource.
+const randomBits = 53
+
+func (s *weightedWordSource) NextWord(ctx context.Context) (string, error) {
+	r := float64(s.rnd.Int63n(1<<randomBits)) / (1 << randomBits)
+	for i, c := range s.cumulative {
+		if r < c {
+			return s.alphabet[i], nil
+		}
+	}
+	return s.alphabet[len(s.alphabet)-1], nil
+}
@@ -0,0 +1,89 @@
+package sim
+
+import (
+	"bytes"
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Simulate()", func() {
+	It("simulates the runner's words and pattern by default.", func() {
+		report, err := Simulate(context.Background(), Config{Runs: 20000, Workers: 4, Seed: 1})
+
+		Expect(err).To(BeNil())
+		Expect(report.Runs).To(Equal(20000))
+		Expect(report.Pattern).To(Equal([]string{"Zun", "Zun", "Zun", "Zun", "Doko"}))
+		Expect(report.Probabilities).To(Equal(map[string]float64{"Zun": 0.5, "Doko": 0.5}))
+		Expect(report.Expected).To(BeNumerically("~", 32, 1e-9))
+		Expect(report.Mean).To(BeNumerically("~", 32, 1.5))
+		Expect(report.Min).To(Equal(5))
+		Expect(report.Median).To(BeNumerically("<", report.P99))
+		Expect(report.P99).To(BeNumerically("<=", report.Max))
+	})
+
+	It("is deterministic for a seed.", func() {
+		config := Config{Runs: 1000, Workers: 2, Seed: 42}
+
+		first, _ := Simulate(context.Background(), config)
+		second, _ := Simulate(context.Background(), config)
+
+		Expect(second.Mean).To(Equal(first.Mean))
+		Expect(second.P99).To(Equal(first.P99))
+	})
+
+	It("supports custom patterns and probabilities.", func() {
+		report, err := Simulate(context.Background(), Config{
+			Runs:          20000,
+			Seed:          1,
+			Pattern:       []string{"Zun", "Doko"},
+			Probabilities: map[string]float64{"Zun": 1, "Doko": 3},
+		})
+
+		Expect(err).To(BeNil())
+		Expect(report.Probabilities).To(Equal(map[string]float64{"Zun": 0.25, "Doko": 0.75}))
+		Expect(report.Expected).To(BeNumerically("~", 16.0/3, 1e-9))
+		Expect(report.Mean).To(BeNumerically("~", 16.0/3, 0.2))
+	})
+
+	It("gives up sessions after the max words.", func() {
+		report, err := Simulate(context.Background(), Config{Runs: 100, Seed: 1, MaxWords: 5})
+
+		Expect(err).To(BeNil())
+		Expect(report.GivenUp).To(BeNumerically(">", 90))
+		Expect(report.Runs).To(Equal(100))
+	})
+
+	It("returns an error if a word of the pattern is never said.", func() {
+		_, err := Simulate(context.Background(), Config{
+			Pattern:       []string{"Zun", "Kiyoshi"},
+			Probabilities: map[string]float64{"Zun": 1, "Doko": 1},
+		})
+
+		Expect(err).To(MatchError(ContainSubstring("Kiyoshi")))
+	})
+
+	It("stops when the context is done.", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		report, err := Simulate(ctx, Config{Runs: 1000000})
+
+		Expect(err).To(Equal(context.Canceled))
+		Expect(report.Runs).To(BeZero())
+	})
+
+	Describe("Report", func() {
+		It("is written in text.", func() {
+			report, _ := Simulate(context.Background(), Config{Runs: 100, Seed: 1})
+			var buf bytes.Buffer
+
+			Expect(report.WriteText(&buf)).To(Succeed())
+
+			Expect(buf.String()).To(ContainSubstring("Pattern:        Zun Zun Zun Zun Doko\n"))
+			Expect(buf.String()).To(ContainSubstring("Probabilities:  Doko=0.5 Zun=0.5\n"))
+			Expect(buf.String()).To(ContainSubstring("Expected          32.000"))
+		})
+	})
+})
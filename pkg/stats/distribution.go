@@ -0,0 +1,64 @@
+package stats
+
+import (
+	"sort"
+	"time"
+)
+
+// Distribution represents a distribution of durations.
+type Distribution struct {
+	Count int           `json:"count"`
+	Min   time.Duration `json:"min"`
+	Mean  time.Duration `json:"mean"`
+	P50   time.Duration `json:"p50"`
+	P90   time.Duration `json:"p90"`
+	P95   time.Duration `json:"p95"`
+	P99   time.Duration `json:"p99"`
+	Max   time.Duration `json:"max"`
+}
+
+// NewDistribution calculates the distribution of the given durations. It doesn't modify the durations.
+func NewDistribution(durations []time.Duration) Distribution {
+	if len(durations) == 0 {
+		return Distribution{}
+	}
+
+	sorted := make([]time.Duration, len(durations))
+	copy(sorted, durations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum time.Duration
+	for _, d := range sorted {
+		sum += d
+	}
+
+	return Distribution{
+		Count: len(sorted),
+		Min:   sorted[0],
+		Mean:  sum / time.Duration(len(sorted)),
+		P50:   percentile(sorted, 50),
+		P90:   percentile(sorted, 90),
+		P95:   percentile(sorted, 95),
+		P99:   percentile(sorted, 99),
+		Max:   sorted[len(sorted)-1],
+	}
+}
+
+// Durations returns the statistics of the distribution from Min to Max, each rounded to the multiple of m,
+// in the order of the columns of the reports.
+func (d Distribution) Durations(m time.Duration) []time.Duration {
+	var durations []time.Duration
+	for _, v := range []time.Duration{d.Min, d.Mean, d.P50, d.P90, d.P95, d.P99, d.Max} {
+		durations = append(durations, v.Round(m))
+	}
+	return durations
+}
+
+// percentile returns the p-th percentile of the sorted durations by the nearest-rank method.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
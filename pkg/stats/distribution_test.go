@@ -0,0 +1,69 @@
+package stats
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Distribution", func() {
+	Describe("NewDistribution()", func() {
+		It("returns zero values for no durations.", func() {
+			Expect(NewDistribution(nil)).To(Equal(Distribution{}))
+		})
+
+		It("calculates percentiles by the nearest-rank method.", func() {
+			var durations []time.Duration
+			for i := 100; i >= 1; i-- {
+				durations = append(durations, time.Duration(i)*time.Millisecond)
+			}
+
+			d := NewDistribution(durations)
+
+			Expect(d).To(Equal(Distribution{
+				Count: 100,
+				Min:   1 * time.Millisecond,
+				Mean:  50500 * time.Microsecond,
+				P50:   50 * time.Millisecond,
+				P90:   90 * time.Millisecond,
+				P95:   95 * time.Millisecond,
+				P99:   99 * time.Millisecond,
+				Max:   100 * time.Millisecond,
+			}))
+			Expect(durations[0]).To(Equal(100 * time.Millisecond))
+		})
+
+		It("returns the only duration for every percentile of one duration.", func() {
+			d := NewDistribution([]time.Duration{time.Second})
+
+			Expect(d.Durations(time.Millisecond)).To(Equal([]time.Duration{
+				time.Second, time.Second, time.Second, time.Second, time.Second, time.Second, time.Second,
+			}))
+		})
+	})
+
+	Describe("Durations()", func() {
+		It("rounds the statistics from Min to Max.", func() {
+			d := Distribution{
+				Min:  1400 * time.Microsecond,
+				Mean: 1500 * time.Microsecond,
+				P50:  2 * time.Millisecond,
+				P90:  3 * time.Millisecond,
+				P95:  4 * time.Millisecond,
+				P99:  5 * time.Millisecond,
+				Max:  6 * time.Millisecond,
+			}
+
+			Expect(d.Durations(time.Millisecond)).To(Equal([]time.Duration{
+				time.Millisecond,
+				2 * time.Millisecond,
+				2 * time.Millisecond,
+				3 * time.Millisecond,
+				4 * time.Millisecond,
+				5 * time.Millisecond,
+				6 * time.Millisecond,
+			}))
+		})
+	})
+})
@@ -0,0 +1,2 @@
+// Package stats provides statistics shared by the reports of the load tester and the history analysis.
+package stats
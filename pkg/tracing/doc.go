@@ -0,0 +1,2 @@
+// Package tracing provides utility funcs to utilize OpenTelemetry tracing.
+package tracing
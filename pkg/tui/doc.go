@@ -0,0 +1,2 @@
+// Package tui provides a full-screen terminal UI to watch and play Zundoko Kiyoshi.
+package tui
@@ -0,0 +1,161 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/client"
+	"github.com/kaitoy/zundoko-go-client/pkg/model"
+	"github.com/kaitoy/zundoko-go-client/pkg/util"
+)
+
+// Config is a configuration of a TUI.
+type Config struct {
+	// Manual lets the user post Zundokos by pressing Z or D keys.
+	Manual bool
+	// PollInterval is the interval to get Zundokos from Zundoko Server. Defaults to 500ms.
+	PollInterval time.Duration
+	// FrameInterval is the interval to redraw the screen. Defaults to 100ms.
+	FrameInterval time.Duration
+}
+
+const (
+	defaultPollInterval  = 500 * time.Millisecond
+	defaultFrameInterval = 100 * time.Millisecond
+)
+
+// Keys handled by the TUI.
+const (
+	keyCtrlC = 0x03
+	keyEsc   = 0x1b
+)
+
+// TUI is a full-screen terminal UI which shows Zundokos live.
+type TUI interface {
+	// Run draws the screen until ctx is done or the user quits by pressing Q, Esc, or Ctrl-C.
+	// The terminal is expected to be in raw mode so that each key press is read as soon as it's pressed.
+	Run(ctx context.Context) error
+}
+
+type tui struct {
+	cl     client.Client
+	in     io.Reader
+	out    io.Writer
+	config Config
+}
+
+// NewTUI creates a TUI instance which reads key presses from in and draws the screen to out.
+func NewTUI(cl client.Client, in io.Reader, out io.Writer, config Config) TUI {
+	if config.PollInterval <= 0 {
+		config.PollInterval = defaultPollInterval
+	}
+	if config.FrameInterval <= 0 {
+		config.FrameInterval = defaultFrameInterval
+	}
+	return &tui{
+		cl:     cl,
+		in:     in,
+		out:    out,
+		config: config,
+	}
+}
+
+func (t *tui) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	v := &view{
+		manual:    t.config.Manual,
+		startedAt: time.Now(),
+	}
+	keys := readKeys(ctx, t.in)
+	poll := time.NewTicker(t.config.PollInterval)
+	defer poll.Stop()
+	frame := time.NewTicker(t.config.FrameInterval)
+	defer frame.Stop()
+
+	t.fetch(ctx, v)
+	t.draw(v)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case key, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			switch key {
+			case 'q', 'Q', keyCtrlC, keyEsc:
+				return nil
+			case 'z', 'Z':
+				t.post(ctx, v, "Zun")
+			case 'd', 'D':
+				t.post(ctx, v, "Doko")
+			}
+		case <-poll.C:
+			t.fetch(ctx, v)
+		case <-frame.C:
+			v.frame++
+		}
+		t.draw(v)
+	}
+}
+
+// readKeys reads key presses from in in a goroutine. The returned channel is closed when in reaches EOF.
+func readKeys(ctx context.Context, in io.Reader) <-chan byte {
+	keys := make(chan byte)
+	go func() {
+		defer close(keys)
+		buf := make([]byte, 1)
+		for {
+			n, err := in.Read(buf)
+			if n > 0 {
+				select {
+				case keys <- buf[0]:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return keys
+}
+
+// fetch gets Zundokos and updates the view with them.
+func (t *tui) fetch(ctx context.Context, v *view) {
+	zundokos, err := t.cl.GetZundokos(ctx)
+	if err != nil {
+		v.errors++
+		v.lastError = fmt.Errorf("failed to get Zundokos: %w", err)
+		return
+	}
+	v.update(zundokos, time.Now())
+}
+
+// post creates a Zundoko of the word if the TUI is in manual mode, and refreshes the view.
+func (t *tui) post(ctx context.Context, v *view, word string) {
+	if !t.config.Manual {
+		return
+	}
+	zundoko := &model.Zundoko{
+		Id:     util.NewUUID().String(),
+		SaidAt: time.Now(),
+		Word:   word,
+	}
+	if err := t.cl.PostZundoko(ctx, zundoko); err != nil {
+		v.errors++
+		v.lastError = fmt.Errorf("failed to create a Zundoko: %w", err)
+		return
+	}
+	v.posted++
+	t.fetch(ctx, v)
+}
+
+func (t *tui) draw(v *view) {
+	io.WriteString(t.out, v.render(time.Now()))
+}
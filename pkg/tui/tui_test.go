@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/kaitoy/zundoko-go-client/mock/pkg/mock_client"
+	"github.com/kaitoy/zundoko-go-client/pkg/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TUI", func() {
+	var (
+		mockCtrl   *gomock.Controller
+		mockClient *mock_client.MockClient
+		output     *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = mock_client.NewMockClient(mockCtrl)
+		output = new(bytes.Buffer)
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	Describe("Run()", func() {
+		It("quits when Q is pressed.", func() {
+			mockClient.EXPECT().GetZundokos(gomock.Any()).Return(nil, nil).AnyTimes()
+
+			err := NewTUI(mockClient, strings.NewReader("q"), output, Config{}).Run(context.Background())
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output.String()).To(ContainSubstring("Zundoko Kiyoshi"))
+		})
+
+		It("quits when the context is done.", func() {
+			mockClient.EXPECT().GetZundokos(gomock.Any()).Return(nil, nil).AnyTimes()
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			in, w := io.Pipe()
+			defer w.Close()
+
+			err := NewTUI(mockClient, in, output, Config{}).Run(ctx)
+
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("posts Zundokos by key presses in manual mode.", func() {
+			var posted []string
+			mockClient.EXPECT().GetZundokos(gomock.Any()).Return(nil, nil).AnyTimes()
+			mockClient.EXPECT().PostZundoko(gomock.Any(), gomock.AssignableToTypeOf(&model.Zundoko{})).
+				DoAndReturn(func(_ context.Context, zd *model.Zundoko) error {
+					posted = append(posted, zd.Word)
+					return nil
+				}).Times(3)
+
+			err := NewTUI(mockClient, strings.NewReader("zZdq"), output, Config{Manual: true}).Run(context.Background())
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(posted).To(Equal([]string{"Zun", "Zun", "Doko"}))
+			Expect(output.String()).To(ContainSubstring("Posted by you: 3"))
+		})
+
+		It("ignores Z and D keys in watch mode.", func() {
+			mockClient.EXPECT().GetZundokos(gomock.Any()).Return(nil, nil).AnyTimes()
+
+			err := NewTUI(mockClient, strings.NewReader("zdq"), output, Config{}).Run(context.Background())
+
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("shows errors from Zundoko Server.", func() {
+			mockClient.EXPECT().GetZundokos(gomock.Any()).Return(nil, errors.New("boom")).AnyTimes()
+
+			err := NewTUI(mockClient, strings.NewReader("q"), output, Config{}).Run(context.Background())
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output.String()).To(ContainSubstring("failed to get Zundokos: boom"))
+		})
+	})
+})
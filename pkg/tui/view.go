@@ -0,0 +1,208 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/model"
+	"github.com/kaitoy/zundoko-go-client/pkg/runner"
+)
+
+// pattern is the sequence of words that makes a Kiyoshi.
+var pattern = runner.Pattern()
+
+// ANSI escape sequences to draw the screen.
+const (
+	clearScreen = "\x1b[H\x1b[2J"
+	bold        = "\x1b[1m"
+	dim         = "\x1b[2m"
+	reset       = "\x1b[0m"
+	green       = "\x1b[32m"
+	yellow      = "\x1b[33m"
+	red         = "\x1b[31m"
+)
+
+// bannerColors are the colors the Kiyoshi banner cycles through.
+var bannerColors = []string{"\x1b[1;31m", "\x1b[1;33m", "\x1b[1;32m", "\x1b[1;36m", "\x1b[1;34m", "\x1b[1;35m"}
+
+// bannerWords are the big letters of "KI YO SHI!", each of which is a list of rows.
+var bannerWords = [][]string{
+	{
+		"#  #  ###",
+		"# #    # ",
+		"##     # ",
+		"# #    # ",
+		"#  #  ###",
+	},
+	{
+		"#   #   ### ",
+		" # #   #   #",
+		"  #    #   #",
+		"  #    #   #",
+		"  #     ### ",
+	},
+	{
+		" ####  #   #  ###  #",
+		"#      #   #   #   #",
+		" ###   #####   #   #",
+		"    #  #   #   #    ",
+		"####   #   #  ###  #",
+	},
+}
+
+// bannerDuration is how long the Kiyoshi banner is shown.
+const bannerDuration = 3 * time.Second
+
+// framesPerBannerWord is the number of animation frames to reveal the next word of the banner.
+const framesPerBannerWord = 3
+
+// maxStreamLines is the maximum number of words shown in the word stream.
+const maxStreamLines = 10
+
+// view holds the state shown on the screen.
+type view struct {
+	manual    bool
+	startedAt time.Time
+	zundokos  []model.Zundoko
+	posted    int
+	errors    int
+	lastError error
+
+	// kiyoshiID is the id of the last Zundoko that completed the pattern.
+	kiyoshiID string
+	kiyoshiAt time.Time
+	kiyoshies int
+	frame     int
+}
+
+// update replaces the Zundokos with the fetched ones in the order they were said, and starts the banner if they newly complete the pattern.
+func (v *view) update(zundokos []model.Zundoko, now time.Time) {
+	sorted := make([]model.Zundoko, len(zundokos))
+	copy(sorted, zundokos)
+	runner.SortZundokos(sorted)
+	v.zundokos = sorted
+
+	if progress(sorted) == len(pattern) {
+		last := sorted[len(sorted)-1]
+		if last.Id != v.kiyoshiID {
+			v.kiyoshiID = last.Id
+			v.kiyoshiAt = now
+			v.kiyoshies++
+			v.frame = 0
+		}
+	}
+}
+
+// progress returns how many words at the end of the Zundokos match the beginning of the pattern.
+func progress(zundokos []model.Zundoko) int {
+	for n := len(pattern); n > 0; n-- {
+		if n > len(zundokos) {
+			continue
+		}
+		matched := true
+		for i, zd := range zundokos[len(zundokos)-n:] {
+			if zd.Word != pattern[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return n
+		}
+	}
+	return 0
+}
+
+// render draws the whole screen.
+func (v *view) render(now time.Time) string {
+	var b strings.Builder
+	b.WriteString(clearScreen)
+	line := func(format string, args ...interface{}) {
+		fmt.Fprintf(&b, format, args...)
+		b.WriteString("\r\n")
+	}
+
+	line("%sZundoko Kiyoshi%s", bold, reset)
+	line("")
+
+	if now.Sub(v.kiyoshiAt) < bannerDuration && v.kiyoshiID != "" {
+		for _, row := range v.banner() {
+			line("  %s", row)
+		}
+		line("")
+	}
+
+	done := progress(v.zundokos)
+	var cells []string
+	for i, word := range pattern {
+		if i < done {
+			cells = append(cells, fmt.Sprintf("%s[%-4s]%s", colorOf(word), word, reset))
+		} else {
+			cells = append(cells, fmt.Sprintf("%s[%-4s]%s", dim, word, reset))
+		}
+	}
+	line("Progress: %s %d/%d", strings.Join(cells, ""), done, len(pattern))
+	line("")
+
+	zuns, dokos := 0, 0
+	for _, zd := range v.zundokos {
+		if zd.Word == "Doko" {
+			dokos++
+		} else {
+			zuns++
+		}
+	}
+	line("Words: %d (Zun: %d, Doko: %d)  Kiyoshies seen: %d  Elapsed: %s",
+		len(v.zundokos), zuns, dokos, v.kiyoshies, now.Sub(v.startedAt).Round(time.Second))
+	if v.manual {
+		line("Posted by you: %d", v.posted)
+	}
+	if v.errors > 0 {
+		line("%sErrors: %d (last: %v)%s", red, v.errors, v.lastError, reset)
+	}
+	line("")
+
+	stream := v.zundokos
+	if len(stream) > maxStreamLines {
+		stream = stream[len(stream)-maxStreamLines:]
+	}
+	for _, zd := range stream {
+		line("  %s  %s%s%s", zd.SaidAt.Local().Format("15:04:05.000"), colorOf(zd.Word), zd.Word, reset)
+	}
+	line("")
+
+	if v.manual {
+		line("%sPress Z for Zun, D for Doko, Q to quit.%s", dim, reset)
+	} else {
+		line("%sPress Q to quit.%s", dim, reset)
+	}
+	return b.String()
+}
+
+// banner returns the rows of the Kiyoshi banner of the current animation frame.
+// The words are revealed one by one and the color cycles.
+func (v *view) banner() []string {
+	words := v.frame/framesPerBannerWord + 1
+	if words > len(bannerWords) {
+		words = len(bannerWords)
+	}
+	color := bannerColors[v.frame%len(bannerColors)]
+
+	rows := make([]string, len(bannerWords[0]))
+	for i := range rows {
+		var parts []string
+		for _, word := range bannerWords[:words] {
+			parts = append(parts, word[i])
+		}
+		rows[i] = color + strings.Join(parts, "   ") + reset
+	}
+	return rows
+}
+
+func colorOf(word string) string {
+	if word == "Doko" {
+		return yellow
+	}
+	return green
+}
@@ -0,0 +1,106 @@
+package tui
+
+import (
+	"strings"
+	"time"
+
+	"github.com/kaitoy/zundoko-go-client/pkg/model"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("view", func() {
+	base := time.Date(2021, 1, 1, 1, 50, 0, 0, time.UTC)
+	zundokos := func(words ...string) []model.Zundoko {
+		var zds []model.Zundoko
+		for i, w := range words {
+			zds = append(zds, model.Zundoko{
+				Id:     string(rune('a' + i)),
+				SaidAt: base.Add(time.Duration(i) * time.Second),
+				Word:   w,
+			})
+		}
+		return zds
+	}
+
+	Describe("progress()", func() {
+		It("returns 0 for no Zundokos.", func() {
+			Expect(progress(nil)).To(Equal(0))
+		})
+		It("counts the trailing words matching the beginning of the pattern.", func() {
+			Expect(progress(zundokos("Doko", "Zun", "Zun"))).To(Equal(2))
+		})
+		It("returns 0 if the last word breaks the pattern.", func() {
+			Expect(progress(zundokos("Zun", "Zun", "Doko"))).To(Equal(0))
+		})
+		It("caps the count of Zun at the pattern.", func() {
+			Expect(progress(zundokos("Zun", "Zun", "Zun", "Zun", "Zun", "Zun"))).To(Equal(4))
+		})
+		It("returns the length of the pattern if completed.", func() {
+			Expect(progress(zundokos("Doko", "Zun", "Zun", "Zun", "Zun", "Doko"))).To(Equal(5))
+		})
+	})
+
+	Describe("update()", func() {
+		It("sorts the Zundokos by SaidAt.", func() {
+			v := &view{}
+			zds := zundokos("Zun", "Doko")
+			v.update([]model.Zundoko{zds[1], zds[0]}, base)
+
+			Expect(v.zundokos).To(Equal(zds))
+		})
+		It("starts the banner once per completed pattern.", func() {
+			v := &view{}
+			zds := zundokos("Zun", "Zun", "Zun", "Zun", "Doko")
+			v.update(zds, base)
+			v.frame = 4
+			v.update(zds, base.Add(time.Second))
+
+			Expect(v.kiyoshies).To(Equal(1))
+			Expect(v.kiyoshiAt).To(Equal(base))
+			Expect(v.frame).To(Equal(4))
+		})
+	})
+
+	Describe("render()", func() {
+		It("shows the progress, stats, and the stream.", func() {
+			v := &view{startedAt: base}
+			v.update(zundokos("Doko", "Zun", "Zun"), base)
+			screen := v.render(base.Add(3 * time.Second))
+
+			Expect(screen).To(HavePrefix(clearScreen))
+			Expect(screen).To(ContainSubstring(" 2/5"))
+			Expect(screen).To(ContainSubstring("Words: 3 (Zun: 2, Doko: 1)  Kiyoshies seen: 0  Elapsed: 3s"))
+			Expect(strings.Count(screen, "Zun"+reset)).To(Equal(2))
+			Expect(screen).NotTo(ContainSubstring("Posted by you"))
+			Expect(screen).To(ContainSubstring("Press Q to quit."))
+		})
+		It("shows the key help and the posted count in manual mode.", func() {
+			v := &view{manual: true, startedAt: base, posted: 2}
+			screen := v.render(base)
+
+			Expect(screen).To(ContainSubstring("Posted by you: 2"))
+			Expect(screen).To(ContainSubstring("Press Z for Zun, D for Doko, Q to quit."))
+		})
+		It("shows the banner only for a while after a Kiyoshi.", func() {
+			v := &view{startedAt: base}
+			v.update(zundokos("Zun", "Zun", "Zun", "Zun", "Doko"), base)
+
+			Expect(v.render(base.Add(time.Second))).To(ContainSubstring(bannerWords[0][0]))
+			Expect(v.render(base.Add(bannerDuration))).NotTo(ContainSubstring(bannerWords[0][0]))
+		})
+	})
+
+	Describe("banner()", func() {
+		It("reveals the words one by one.", func() {
+			v := &view{}
+			Expect(v.banner()[0]).To(Equal(bannerColors[0] + bannerWords[0][0] + reset))
+
+			v.frame = framesPerBannerWord
+			Expect(v.banner()[0]).To(ContainSubstring(bannerWords[0][0] + "   " + bannerWords[1][0]))
+
+			v.frame = 100 * framesPerBannerWord
+			Expect(v.banner()[0]).To(ContainSubstring(bannerWords[1][0] + "   " + bannerWords[2][0]))
+		})
+	})
+})
@@ -0,0 +1,11 @@
+// Package swagger embeds the OpenAPI spec of Zundoko Server, which is also the source of the generated models.
+package swagger
+
+import (
+	_ "embed"
+)
+
+// YAML is the content of swagger.yaml.
+//
+//go:embed swagger.yaml
+var YAML []byte